@@ -16,16 +16,100 @@ type Config struct {
 	// Smaller timeouts are recommended.
 	QueryTimeout time.Duration `env:"QUERY_TIMEOUT, report"`
 
+	// MaxQuerySourceIDs limits the number of known source IDs a single
+	// selector of a PromQL query may match through a regular expression or
+	// negative matcher, e.g. source_id=~"app-.*". Fixed sets such as
+	// source_id=~"a|b" are not limited. Default is 100. Zero removes the
+	// limit.
+	MaxQuerySourceIDs int `env:"MAX_QUERY_SOURCE_IDS, report"`
+
+	// QueryResultsCache enables caching the results of PromQL range
+	// queries per step, so that refreshing a range query only evaluates the
+	// steps that were not cached yet. Default is false.
+	QueryResultsCache bool `env:"QUERY_RESULTS_CACHE, report"`
+
+	// QueryResultsCacheSize is the maximum number of range queries whose
+	// results are cached. The least recently used ones are evicted first.
+	// Default is 1000. Zero removes the limit.
+	QueryResultsCacheSize int `env:"QUERY_RESULTS_CACHE_SIZE, report"`
+
+	// TimerHistogramBuckets enables selecting timers as Prometheus
+	// histograms in PromQL. Each value is the upper bound of a bucket in
+	// seconds, in ascending order, e.g. 0.005,0.01,0.1,1,10. A timer named
+	// http is then also exposed as http_bucket{le="..."}, http_sum and
+	// http_count. If empty, timers are only exposed as their durations.
+	TimerHistogramBuckets []string `env:"TIMER_HISTOGRAM_BUCKETS, report"`
+
 	// MemoryLimit sets the percentage of total system memory to use for the
 	// cache. If exceeded, the cache will prune. Default is 50%.
 	MemoryLimit uint `env:"MEMORY_LIMIT_PERCENT, report"`
 
+	// PruneStrategy selects how the cache decides to prune. It is either
+	// "memory" or "bytes". The memory strategy prunes once the heap exceeds
+	// MemoryLimit. The bytes strategy prunes once the estimated serialized
+	// size of the stored envelopes exceeds ByteBudget. Default is memory.
+	PruneStrategy string `env:"PRUNE_STRATEGY, report"`
+
+	// ByteBudget sets the number of bytes the bytes prune strategy keeps
+	// the cache within. It is required for the bytes strategy.
+	ByteBudget int64 `env:"BYTE_BUDGET, report"`
+
+	// MaxBytesPerSource sets the maximum number of bytes stored per source
+	// when using the bytes prune strategy. If zero, sources are only limited
+	// by MaxPerSource.
+	MaxBytesPerSource int64 `env:"MAX_BYTES_PER_SOURCE, report"`
+
 	// MaxPerSource sets the maximum number of items stored per source.
 	// Because autoscaler requires a minute of data, apps with more than 1000
 	// requests per second will fill up the router logs/metrics in less than a
 	// minute. Default is 100000.
 	MaxPerSource int `env:"MAX_PER_SOURCE, report"`
 
+	// MaxAge sets the maximum age of envelopes for every source. Envelopes
+	// older than MaxAge are expired regardless of memory pressure. If zero,
+	// envelopes are only expired by count and memory.
+	MaxAge time.Duration `env:"MAX_AGE, report"`
+
+	// RetentionOverrides override MaxAge for specific source IDs or source
+	// ID patterns. Each override is of the form <source-id>=<duration> or
+	// /<pattern>/=<duration>, e.g. doppler=10m or /^app-.*$/=2h.
+	RetentionOverrides []string `env:"RETENTION_OVERRIDES, report"`
+
+	// EvictionClasses group source IDs into weighted classes. When memory
+	// is scarce, each class keeps a share of the cache proportional to its
+	// weight and envelopes are pruned from the classes that exceed their
+	// share the most. Each class is of the form <name>:<source-id>=<weight>
+	// or <name>:/<pattern>/=<weight>, e.g. system:doppler=4 or
+	// apps:/^app-.*$/=1. Source IDs that no class applies to have a weight
+	// of 1.
+	EvictionClasses []string `env:"EVICTION_CLASSES, report"`
+
+	// DeduplicationWindow enables dropping envelopes that were already
+	// received, e.g. from redundant nozzles or retrying syslog drains.
+	// Envelopes with the same source ID, timestamp, instance ID and message
+	// are only stored once if they are within the window of the newest
+	// envelope of their source ID. If zero, every envelope is stored.
+	DeduplicationWindow time.Duration `env:"DEDUPLICATION_WINDOW, report"`
+
+	// StorageEngine selects how envelopes are kept in memory. It is either
+	// "avltree", "compact" or "segment". The compact engine needs
+	// considerably less memory per envelope at the cost of slower reads.
+	// The segment engine appends envelopes to time-bucketed segments, which
+	// makes inserts and range reads cheaper and keeps any number of
+	// envelopes with the same timestamp. Default is avltree.
+	StorageEngine string `env:"STORAGE_ENGINE, report"`
+
+	// PersistenceDir enables writing every envelope to a write-ahead log
+	// and periodic snapshots in the given directory. Persisted data is
+	// restored when the node restarts. If empty, data is only kept in
+	// memory.
+	PersistenceDir string `env:"PERSISTENCE_DIR, report"`
+
+	// SnapshotInterval sets how often the store is snapshotted to the
+	// PersistenceDir. Zero disables periodic snapshots, the write-ahead log
+	// then grows until the next restart. Default is 1m.
+	SnapshotInterval time.Duration `env:"SNAPSHOT_INTERVAL, report"`
+
 	// NodeIndex determines what data the node stores. It splits up the range
 	// of 0 - 18446744073709551615 evenly. If data falls out of range of the
 	// given node, it will be routed to theh correct one.
@@ -44,11 +128,15 @@ type Config struct {
 // LoadConfig creates Config object from environment variables
 func LoadConfig() (*Config, error) {
 	c := Config{
-		Addr:         ":8080",
-		HealthPort:   6060,
-		QueryTimeout: 10 * time.Second,
-		MemoryLimit:  50,
-		MaxPerSource: 100000,
+		Addr:                  ":8080",
+		HealthPort:            6060,
+		QueryTimeout:          10 * time.Second,
+		MaxQuerySourceIDs:     100,
+		QueryResultsCacheSize: 1000,
+		MemoryLimit:           50,
+		PruneStrategy:         "memory",
+		MaxPerSource:          100000,
+		SnapshotInterval:      time.Minute,
 	}
 
 	if err := envstruct.Load(&c); err != nil {
@@ -11,6 +11,8 @@ import (
 
 	envstruct "code.cloudfoundry.org/go-envstruct"
 	. "code.cloudfoundry.org/log-cache/internal/cache"
+	"code.cloudfoundry.org/log-cache/internal/cache/store"
+	"code.cloudfoundry.org/log-cache/internal/promql"
 	"google.golang.org/grpc"
 )
 
@@ -44,13 +46,12 @@ func main() {
 		}
 	}(time.Now())
 
-	cache := New(
-		m,
-		logger,
+	opts := []LogCacheOption{
 		WithAddr(cfg.Addr),
 		WithMemoryLimit(float64(cfg.MemoryLimit)),
 		WithMaxPerSource(cfg.MaxPerSource),
 		WithQueryTimeout(cfg.QueryTimeout),
+		WithMaxQuerySourceIDs(cfg.MaxQuerySourceIDs),
 		WithClustered(
 			cfg.NodeIndex,
 			cfg.NodeAddrs,
@@ -59,7 +60,73 @@ func main() {
 			),
 		),
 		WithServerOpts(grpc.Creds(cfg.TLS.Credentials("log-cache"))),
-	)
+	}
+
+	switch cfg.PruneStrategy {
+	case "memory":
+	case "bytes":
+		if cfg.ByteBudget <= 0 {
+			log.Fatalf("invalid prune strategy: BYTE_BUDGET is required for the bytes strategy")
+		}
+		opts = append(opts, WithByteBudget(cfg.ByteBudget, cfg.MaxBytesPerSource))
+	default:
+		log.Fatalf("invalid prune strategy: %q is not memory or bytes", cfg.PruneStrategy)
+	}
+
+	if cfg.PersistenceDir != "" {
+		opts = append(opts, WithPersistence(cfg.PersistenceDir, cfg.SnapshotInterval))
+	}
+
+	if cfg.MaxAge > 0 || len(cfg.RetentionOverrides) > 0 {
+		var rules []store.RetentionRule
+		for _, o := range cfg.RetentionOverrides {
+			rule, err := store.ParseRetentionRule(o)
+			if err != nil {
+				log.Fatalf("invalid retention override: %s", err)
+			}
+			rules = append(rules, rule)
+		}
+
+		opts = append(opts, WithRetentionPolicy(store.NewRetentionPolicy(cfg.MaxAge, rules...)))
+	}
+
+	if len(cfg.EvictionClasses) > 0 {
+		var classes []store.EvictionClass
+		for _, c := range cfg.EvictionClasses {
+			class, err := store.ParseEvictionClass(c)
+			if err != nil {
+				log.Fatalf("invalid eviction class: %s", err)
+			}
+			classes = append(classes, class)
+		}
+
+		opts = append(opts, WithEvictionPolicy(store.NewEvictionPolicy(1, classes...)))
+	}
+
+	if cfg.DeduplicationWindow > 0 {
+		opts = append(opts, WithDeduplication(cfg.DeduplicationWindow))
+	}
+
+	if cfg.QueryResultsCache {
+		opts = append(opts, WithQueryResultsCache(cfg.QueryResultsCacheSize))
+	}
+
+	if len(cfg.TimerHistogramBuckets) > 0 {
+		buckets, err := promql.ParseTimerHistogramBuckets(cfg.TimerHistogramBuckets)
+		if err != nil {
+			log.Fatalf("invalid timer histogram buckets: %s", err)
+		}
+
+		opts = append(opts, WithTimerHistogramBuckets(buckets))
+	}
+
+	engine, err := store.ParseStorageEngine(cfg.StorageEngine)
+	if err != nil {
+		log.Fatalf("invalid storage engine: %s", err)
+	}
+	opts = append(opts, WithStorageEngine(engine))
+
+	cache := New(m, logger, opts...)
 
 	cache.Start()
 
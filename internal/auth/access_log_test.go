@@ -97,6 +97,26 @@ var _ = Describe("AccessLog", func() {
 			})
 		})
 
+		Context("with a DELETE request", func() {
+			BeforeEach(func() {
+				method = "DELETE"
+			})
+
+			It("returns a log with DELETE as the method", func() {
+				expected := testing.BuildExpectedLog(
+					timestamp,
+					requestId,
+					method,
+					path,
+					forwardedFor,
+					"",
+					dstHost,
+					dstPort,
+				)
+				Expect(al.String()).To(Equal(expected))
+			})
+		})
+
 		Context("with X-Forwarded-For not set", func() {
 			BeforeEach(func() {
 				forwardedFor = ""
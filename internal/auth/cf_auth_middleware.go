@@ -3,17 +3,22 @@ package auth
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
 	"time"
 
 	"log"
 
 	"context"
 
+	"code.cloudfoundry.org/log-cache/pkg/client"
 	rpc "code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/gorilla/mux"
+	"github.com/grpc-ecosystem/grpc-gateway/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/utilities"
 
 	"code.cloudfoundry.org/log-cache/internal/promql"
+	"code.cloudfoundry.org/log-cache/internal/routing"
 )
 
 type CFAuthMiddlewareProvider struct {
@@ -26,7 +31,12 @@ type CFAuthMiddlewareProvider struct {
 }
 
 type Oauth2ClientContext struct {
-	IsAdmin   bool
+	IsAdmin bool
+
+	// IsLogsAdmin is only true for the logs.admin scope. Unlike IsAdmin it
+	// does not include doppler.firehose.
+	IsLogsAdmin bool
+
 	Token     string
 	ExpiresAt time.Time
 }
@@ -41,7 +51,7 @@ type LogAuthorizer interface {
 }
 
 type MetaFetcher interface {
-	Meta(context.Context) (map[string]*rpc.MetaInfo, error)
+	MetaPage(context.Context, ...client.MetaOption) (map[string]*rpc.MetaInfo, string, error)
 }
 
 type AppNameTranslator interface {
@@ -74,7 +84,7 @@ type promqlErrorBody struct {
 func (m CFAuthMiddlewareProvider) Middleware(h http.Handler) http.Handler {
 	router := mux.NewRouter()
 
-	router.HandleFunc("/api/v1/read/{sourceID:.*}", func(w http.ResponseWriter, r *http.Request) {
+	router.HandleFunc("/api/v1/{subpath:read|tail|aggregate|histogram}/{sourceID:.*}", func(w http.ResponseWriter, r *http.Request) {
 		sourceID, ok := mux.Vars(r)["sourceID"]
 		if !ok {
 			w.WriteHeader(http.StatusNotFound)
@@ -112,30 +122,60 @@ func (m CFAuthMiddlewareProvider) Middleware(h http.Handler) http.Handler {
 		}
 
 		query := r.URL.Query().Get("query")
-		sourceIds, err := m.promQLSourceIdExtractor(query)
-		if err != nil {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
+		authorized, ok := m.authorizeQueries(w, r, authToken, []string{query})
+		if !ok {
+			return
+		}
+
+		q := r.URL.Query()
+		q.Set("query", authorized[0])
+		r.URL.RawQuery = q.Encode()
 
-			json.NewEncoder(w).Encode(&promqlErrorBody{
-				Status:    "error",
-				ErrorType: "bad_data",
-				Error:     err.Error(),
-			})
+		h.ServeHTTP(w, r)
+	})
 
+	discoveryHandler := func(w http.ResponseWriter, r *http.Request) {
+		authToken := r.Header.Get("Authorization")
+		if authToken == "" {
+			w.WriteHeader(http.StatusNotFound)
 			return
 		}
 
-		if len(sourceIds) == 0 {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusBadRequest)
+		// The gateway accepts the selectors both as match[] and match, so
+		// both have to be authorized.
+		q := r.URL.Query()
+		var selectors []string
+		for _, k := range []string{"match[]", "match"} {
+			for _, v := range q[k] {
+				if v != "" {
+					selectors = append(selectors, v)
+				}
+			}
+		}
 
-			json.NewEncoder(w).Encode(&promqlErrorBody{
-				Status:    "error",
-				ErrorType: "bad_data",
-				Error:     "query does not request any source_ids",
-			})
+		if len(selectors) == 0 {
+			writePromQLBadData(w, "at least one match[] selector is required")
+			return
+		}
 
+		authorized, ok := m.authorizeQueries(w, r, authToken, selectors)
+		if !ok {
+			return
+		}
+
+		q.Del("match")
+		q["match[]"] = authorized
+		r.URL.RawQuery = q.Encode()
+
+		h.ServeHTTP(w, r)
+	}
+	router.HandleFunc("/api/v1/{subpath:labels|series}", discoveryHandler)
+	router.HandleFunc("/api/v1/label/{name}/values", discoveryHandler)
+
+	router.HandleFunc("/api/v1/meta", func(w http.ResponseWriter, r *http.Request) {
+		authToken := r.Header.Get("Authorization")
+		if authToken == "" {
+			w.WriteHeader(http.StatusNotFound)
 			return
 		}
 
@@ -146,67 +186,70 @@ func (m CFAuthMiddlewareProvider) Middleware(h http.Handler) http.Handler {
 			return
 		}
 
-		relatedSourceIds := m.appNameTranslator.GetRelatedSourceIds(sourceIds, authToken)
-		if relatedSourceIds == nil {
-			w.WriteHeader(http.StatusNotFound)
+		var req rpc.MetaRequest
+		params := metaParams(r.URL.Query())
+		err = runtime.PopulateQueryParameters(&req, params, utilities.NewDoubleArray(nil))
+		if err != nil {
+			writeMetaError(w, err)
 			return
 		}
 
-		for _, sourceId := range sourceIds {
-			sourceIdSet := append(relatedSourceIds[sourceId], sourceId)
-
-			if !c.IsAdmin {
-				sourceIdSet = m.authorizeSourceIds(sourceIdSet, c)
-
-				if len(sourceIdSet) == 0 {
-					w.WriteHeader(http.StatusNotFound)
-					return
-				}
-			}
+		query, err := routing.NewMetaQuery(&req)
+		if err != nil {
+			writeMetaError(w, err)
+			return
+		}
 
-			relatedSourceIds[sourceId] = sourceIdSet
+		// The page of a non-admin can only be selected once the source
+		// IDs they are not authorized for have been removed.
+		if !c.IsAdmin {
+			params.Del("limit")
 		}
 
-		modifiedQuery, err := promql.ReplaceSourceIdSets(query, relatedSourceIds)
+		meta, nextPageToken, err := m.metaFetcher.MetaPage(r.Context(), func(_ *url.URL, q url.Values) {
+			for k, v := range params {
+				q[k] = v
+			}
+		})
 		if err != nil {
-			log.Printf("failed to expand source IDs: %s", err)
-			w.WriteHeader(http.StatusNotFound)
+			log.Printf("failed to fetch meta information: %s", err)
+			w.WriteHeader(http.StatusBadGateway)
 			return
 		}
 
-		q := r.URL.Query()
-		q.Set("query", modifiedQuery)
-		r.URL.RawQuery = q.Encode()
+		resp := &rpc.MetaResponse{
+			Meta:          meta,
+			NextPageToken: nextPageToken,
+		}
+		if !c.IsAdmin {
+			resp = query.Apply(m.onlyAuthorized(authToken, meta, c))
+		}
 
-		h.ServeHTTP(w, r)
+		// We don't care if writing to the client fails. They can come back and ask again.
+		_ = m.marshaller.Marshal(w, resp)
+		w.Write([]byte("\n"))
 	})
 
-	router.HandleFunc("/api/v1/meta", func(w http.ResponseWriter, r *http.Request) {
+	router.HandleFunc("/api/v1/purge/{sourceID:.*}", func(w http.ResponseWriter, r *http.Request) {
 		authToken := r.Header.Get("Authorization")
 		if authToken == "" {
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
 
-		c, err := m.oauth2Reader.Read(authToken)
+		userContext, err := m.oauth2Reader.Read(authToken)
 		if err != nil {
 			log.Printf("failed to read from Oauth2 server: %s", err)
 			w.WriteHeader(http.StatusNotFound)
 			return
 		}
 
-		meta, err := m.metaFetcher.Meta(r.Context())
-		if err != nil {
-			log.Printf("failed to fetch meta information: %s", err)
-			w.WriteHeader(http.StatusBadGateway)
+		if !userContext.IsLogsAdmin {
+			w.WriteHeader(http.StatusNotFound)
 			return
 		}
 
-		// We don't care if writing to the client fails. They can come back and ask again.
-		_ = m.marshaller.Marshal(w, &rpc.MetaResponse{
-			Meta: m.onlyAuthorized(authToken, meta, c),
-		})
-		w.Write([]byte("\n"))
+		h.ServeHTTP(w, r)
 	})
 
 	router.HandleFunc("/api/v1/info", h.ServeHTTP)
@@ -214,6 +257,133 @@ func (m CFAuthMiddlewareProvider) Middleware(h http.Handler) http.Handler {
 	return router
 }
 
+// authorizeQueries returns the queries with every requested source ID
+// expanded to the related source IDs the user is authorized for. Source ID
+// patterns, e.g. source_id=~"app-.*", are resolved to the known source IDs
+// the user is authorized for. If any query can not be authorized, it writes
+// the error response and returns false.
+func (m CFAuthMiddlewareProvider) authorizeQueries(w http.ResponseWriter, r *http.Request, authToken string, queries []string) ([]string, bool) {
+	c, err := m.oauth2Reader.Read(authToken)
+	if err != nil {
+		log.Printf("failed to read from Oauth2 server: %s", err)
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	var (
+		known    []string
+		metaErr  error
+		metaRead bool
+	)
+	knownSourceIds := func() ([]string, error) {
+		if !metaRead {
+			known, metaErr = m.knownSourceIds(r.Context(), authToken, c)
+			metaRead = true
+		}
+
+		return known, metaErr
+	}
+
+	var sourceIds []string
+	seen := make(map[string]bool)
+	for i, query := range queries {
+		resolved, err := promql.ResolveSourceIdMatchers(query, knownSourceIds)
+		switch {
+		case metaErr != nil:
+			log.Printf("failed to fetch meta information: %s", metaErr)
+			w.WriteHeader(http.StatusBadGateway)
+			return nil, false
+		case err == promql.ErrNoMatchingSourceIds:
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		case err != nil:
+			writePromQLBadData(w, err.Error())
+			return nil, false
+		}
+		queries[i] = resolved
+
+		querySourceIds, err := m.promQLSourceIdExtractor(resolved)
+		if err != nil {
+			writePromQLBadData(w, err.Error())
+			return nil, false
+		}
+
+		if len(querySourceIds) == 0 {
+			writePromQLBadData(w, "query does not request any source_ids")
+			return nil, false
+		}
+
+		for _, sourceId := range querySourceIds {
+			if !seen[sourceId] {
+				seen[sourceId] = true
+				sourceIds = append(sourceIds, sourceId)
+			}
+		}
+	}
+
+	relatedSourceIds := m.appNameTranslator.GetRelatedSourceIds(sourceIds, authToken)
+	if relatedSourceIds == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return nil, false
+	}
+
+	for _, sourceId := range sourceIds {
+		sourceIdSet := append(relatedSourceIds[sourceId], sourceId)
+
+		if !c.IsAdmin {
+			sourceIdSet = m.authorizeSourceIds(sourceIdSet, c)
+
+			if len(sourceIdSet) == 0 {
+				w.WriteHeader(http.StatusNotFound)
+				return nil, false
+			}
+		}
+
+		relatedSourceIds[sourceId] = sourceIdSet
+	}
+
+	authorized := make([]string, 0, len(queries))
+	for _, query := range queries {
+		modifiedQuery, err := promql.ReplaceSourceIdSets(query, relatedSourceIds)
+		if err != nil {
+			log.Printf("failed to expand source IDs: %s", err)
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+
+		authorized = append(authorized, modifiedQuery)
+	}
+
+	return authorized, true
+}
+
+// knownSourceIds returns the source IDs of the cache the user is authorized
+// for.
+func (m CFAuthMiddlewareProvider) knownSourceIds(ctx context.Context, authToken string, c Oauth2ClientContext) ([]string, error) {
+	meta, _, err := m.metaFetcher.MetaPage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceIds []string
+	for sourceId := range m.onlyAuthorized(authToken, meta, c) {
+		sourceIds = append(sourceIds, sourceId)
+	}
+
+	return sourceIds, nil
+}
+
+func writePromQLBadData(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	json.NewEncoder(w).Encode(&promqlErrorBody{
+		Status:    "error",
+		ErrorType: "bad_data",
+		Error:     message,
+	})
+}
+
 func (m CFAuthMiddlewareProvider) authorizeSourceIds(sourceIds []string, c Oauth2ClientContext) []string {
 	var authorizedSourceIds []string
 
@@ -226,6 +396,35 @@ func (m CFAuthMiddlewareProvider) authorizeSourceIds(sourceIds []string, c Oauth
 	return authorizedSourceIds
 }
 
+// metaParams returns the query parameters that filter, sort and page meta
+// information.
+func metaParams(q url.Values) url.Values {
+	params := url.Values{}
+	for _, k := range []string{
+		"source_id_prefix",
+		"source_id_regex",
+		"sort_by",
+		"descending",
+		"limit",
+		"page_token",
+	} {
+		if v, ok := q[k]; ok {
+			params[k] = v
+		}
+	}
+
+	return params
+}
+
+func writeMetaError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	json.NewEncoder(w).Encode(map[string]string{
+		"error": err.Error(),
+	})
+}
+
 func (m CFAuthMiddlewareProvider) onlyAuthorized(authToken string, meta map[string]*rpc.MetaInfo, c Oauth2ClientContext) map[string]*rpc.MetaInfo {
 	if c.IsAdmin {
 		return meta
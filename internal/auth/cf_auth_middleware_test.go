@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 
 	"code.cloudfoundry.org/log-cache/internal/auth"
+	"code.cloudfoundry.org/log-cache/pkg/client"
 
 	"context"
 
@@ -142,6 +144,129 @@ var _ = Describe("CfAuthMiddleware", func() {
 		})
 	})
 
+	Describe("/api/v1/tail", func() {
+		It("forwards the request to the handler if non-admin user has log access", func() {
+			tc := setup("/api/v1/tail/12345")
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+			Expect(tc.baseHandlerCalled).To(BeTrue())
+			Expect(tc.spyLogAuthorizer.sourceIDsCalledWith).To(HaveKey("12345"))
+		})
+
+		It("returns 404 Not Found if user is not authorized", func() {
+			tc := setup("/api/v1/tail/12345")
+			tc.spyLogAuthorizer.unauthorizedSourceIds["12345"] = struct{}{}
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+	})
+
+	Describe("/api/v1/aggregate", func() {
+		It("forwards the request to the handler if non-admin user has log access", func() {
+			tc := setup("/api/v1/aggregate/12345")
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+			Expect(tc.baseHandlerCalled).To(BeTrue())
+			Expect(tc.spyLogAuthorizer.sourceIDsCalledWith).To(HaveKey("12345"))
+		})
+
+		It("returns 404 Not Found if user is not authorized", func() {
+			tc := setup("/api/v1/aggregate/12345")
+			tc.spyLogAuthorizer.unauthorizedSourceIds["12345"] = struct{}{}
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+	})
+
+	Describe("/api/v1/histogram", func() {
+		It("forwards the request to the handler if non-admin user has log access", func() {
+			tc := setup("/api/v1/histogram/12345")
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+			Expect(tc.baseHandlerCalled).To(BeTrue())
+			Expect(tc.spyLogAuthorizer.sourceIDsCalledWith).To(HaveKey("12345"))
+		})
+
+		It("returns 404 Not Found if user is not authorized", func() {
+			tc := setup("/api/v1/histogram/12345")
+			tc.spyLogAuthorizer.unauthorizedSourceIds["12345"] = struct{}{}
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+	})
+
+	Describe("/api/v1/purge", func() {
+		It("forwards the request to the handler if user has the logs.admin scope", func() {
+			tc := setup("/api/v1/purge/some-source/id")
+			tc.request.Method = http.MethodDelete
+			tc.spyOauth2ClientReader.isAdminResult = true
+			tc.spyOauth2ClientReader.isLogsAdminResult = true
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+			Expect(tc.baseHandlerCalled).To(BeTrue())
+			Expect(tc.baseHandlerRequest.URL.Path).To(Equal("/api/v1/purge/some-source/id"))
+		})
+
+		It("returns 404 Not Found for a doppler.firehose admin", func() {
+			tc := setup("/api/v1/purge/some-source")
+			tc.request.Method = http.MethodDelete
+			tc.spyOauth2ClientReader.isAdminResult = true
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+
+		It("returns 404 Not Found for a user with log access", func() {
+			tc := setup("/api/v1/purge/some-source")
+			tc.request.Method = http.MethodDelete
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+
+		It("returns 404 Not Found if there's no authorization header present", func() {
+			tc := setup("/api/v1/purge/some-source")
+			tc.request.Header.Del("Authorization")
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+
+		It("returns 404 Not Found if Oauth2ClientReader returns an error", func() {
+			tc := setup("/api/v1/purge/some-source")
+			tc.spyOauth2ClientReader.err = errors.New("some-error")
+			tc.spyOauth2ClientReader.isLogsAdminResult = true
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+	})
+
 	Describe("/api/v1/meta", func() {
 		It("returns all source IDs from MetaFetcher for an admin", func() {
 			tc := setup("/api/v1/meta")
@@ -164,6 +289,32 @@ var _ = Describe("CfAuthMiddleware", func() {
 			Expect(tc.spyLogAuthorizer.availableCalled).To(BeZero())
 		})
 
+		It("passes the per-type counts, bytes and rate through", func() {
+			tc := setup("/api/v1/meta")
+			tc.spyMetaFetcher.result = map[string]*rpc.MetaInfo{
+				"source-0": {
+					Count:      3,
+					LogCount:   2,
+					EventCount: 1,
+					Bytes:      240,
+					Rate:       0.05,
+				},
+			}
+			tc.spyOauth2ClientReader.isAdminResult = true
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+
+			var m rpc.MetaResponse
+			Expect(jsonpb.Unmarshal(tc.recorder.Body, &m)).To(Succeed())
+			Expect(m.Meta).To(HaveKey("source-0"))
+			Expect(m.Meta["source-0"].LogCount).To(Equal(int64(2)))
+			Expect(m.Meta["source-0"].EventCount).To(Equal(int64(1)))
+			Expect(m.Meta["source-0"].Bytes).To(Equal(int64(240)))
+			Expect(m.Meta["source-0"].Rate).To(Equal(0.05))
+		})
+
 		It("returns only source IDs that are available for a non-admin token", func() {
 			tc := setup("/api/v1/meta")
 			tc.spyMetaFetcher.result = map[string]*rpc.MetaInfo{
@@ -187,6 +338,93 @@ var _ = Describe("CfAuthMiddleware", func() {
 			Expect(tc.spyLogAuthorizer.token).To(Equal("bearer valid-token"))
 		})
 
+		It("forwards the filters, order and page of an admin", func() {
+			tc := setup("/api/v1/meta?source_id_prefix=app-&sort_by=COUNT&descending=true&limit=50&local_only=true")
+			tc.spyMetaFetcher.result = map[string]*rpc.MetaInfo{
+				"app-0": {},
+			}
+			tc.spyMetaFetcher.nextPageToken = "some-token"
+			tc.spyOauth2ClientReader.isAdminResult = true
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+			Expect(tc.spyMetaFetcher.params).To(Equal(url.Values{
+				"source_id_prefix": {"app-"},
+				"sort_by":          {"COUNT"},
+				"descending":       {"true"},
+				"limit":            {"50"},
+			}))
+
+			var m rpc.MetaResponse
+			Expect(jsonpb.Unmarshal(tc.recorder.Body, &m)).To(Succeed())
+			Expect(m.Meta).To(HaveKey("app-0"))
+			Expect(m.NextPageToken).To(Equal("some-token"))
+		})
+
+		It("selects the page of a non-admin from the available source IDs", func() {
+			tc := setup("/api/v1/meta?sort_by=COUNT&descending=true&limit=1")
+			tc.spyMetaFetcher.result = map[string]*rpc.MetaInfo{
+				"source-0": {Count: 5},
+				"source-1": {Count: 9},
+				"source-2": {Count: 3},
+			}
+			tc.spyLogAuthorizer.available = []string{
+				"source-0",
+				"source-2",
+			}
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+			Expect(tc.spyMetaFetcher.params).To(Equal(url.Values{
+				"sort_by":    {"COUNT"},
+				"descending": {"true"},
+			}))
+
+			var m rpc.MetaResponse
+			Expect(jsonpb.Unmarshal(tc.recorder.Body, &m)).To(Succeed())
+			Expect(m.Meta).To(HaveLen(1))
+			Expect(m.Meta).To(HaveKey("source-0"))
+			Expect(m.NextPageToken).ToNot(BeEmpty())
+
+			tc = setup("/api/v1/meta?sort_by=COUNT&descending=true&limit=1&page_token=" + m.NextPageToken)
+			tc.spyMetaFetcher.result = map[string]*rpc.MetaInfo{
+				"source-0": {Count: 5},
+				"source-1": {Count: 9},
+				"source-2": {Count: 3},
+			}
+			tc.spyLogAuthorizer.available = []string{
+				"source-0",
+				"source-2",
+			}
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+
+			var next rpc.MetaResponse
+			Expect(jsonpb.Unmarshal(tc.recorder.Body, &next)).To(Succeed())
+			Expect(next.Meta).To(HaveLen(1))
+			Expect(next.Meta).To(HaveKey("source-2"))
+			Expect(next.NextPageToken).To(BeEmpty())
+		})
+
+		DescribeTable("returns 400 Bad Request for invalid parameters", func(query string) {
+			tc := setup("/api/v1/meta?" + query)
+			tc.spyOauth2ClientReader.isAdminResult = true
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(tc.spyMetaFetcher.called).To(BeZero())
+		},
+			Entry("invalid regex", "source_id_regex=["),
+			Entry("invalid sort", "sort_by=SIZE"),
+			Entry("invalid limit", "limit=many"),
+			Entry("invalid page token", "page_token=invalid"),
+		)
+
 		It("respects the request's context", func() {
 			tc := setup("/api/v1/meta")
 			ctx, cancel := context.WithCancel(context.Background())
@@ -358,6 +596,59 @@ var _ = Describe("CfAuthMiddleware", func() {
 			Expect(tc.recorder.Code).To(Equal(http.StatusNotFound))
 			Expect(tc.baseHandlerCalled).To(BeFalse())
 		})
+
+		It("resolves source_id patterns to the available source IDs of a non-admin", func() {
+			tc := setup(`/api/v1/query?query=metric{source_id=~"app-.*"}`)
+			tc.spyMetaFetcher.result = map[string]*rpc.MetaInfo{
+				"app-1":        {},
+				"app-2":        {},
+				"app-3":        {},
+				"other-source": {},
+			}
+			tc.spyLogAuthorizer.available = []string{"app-1", "app-2", "other-source"}
+			tc.spyPromQLParser.sourceIDs = []string{"app-1", "app-2"}
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+			Expect(tc.baseHandlerCalled).To(BeTrue())
+
+			Expect(tc.spyPromQLParser.query).To(Equal(`metric{source_id=~"app-1|app-2"}`))
+			Expect(tc.baseHandlerRequest.URL.Query().Get("query")).To(
+				Equal(`metric{source_id=~"app-1|app-2"}`),
+			)
+		})
+
+		It("does not fetch meta information for a fixed set of source IDs", func() {
+			tc := setup(`/api/v1/query?query=metric{source_id=~"some-id|other-id"}`)
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+			Expect(tc.spyMetaFetcher.called).To(BeZero())
+		})
+
+		It("returns 404 Not Found if a source_id pattern does not match any available source IDs", func() {
+			tc := setup(`/api/v1/query?query=metric{source_id=~"app-.*"}`)
+			tc.spyMetaFetcher.result = map[string]*rpc.MetaInfo{
+				"app-1": {},
+			}
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+
+		It("returns 502 Bad Gateway if MetaFetcher fails for a source_id pattern", func() {
+			tc := setup(`/api/v1/query?query=metric{source_id=~"app-.*"}`)
+			tc.spyMetaFetcher.err = errors.New("expected")
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusBadGateway))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
 	})
 
 	Describe("/api/v1/query_range", func() {
@@ -375,6 +666,136 @@ var _ = Describe("CfAuthMiddleware", func() {
 		})
 	})
 
+	Describe("/api/v1/series", func() {
+		It("forwards the request to the handler if non-admin user has log access", func() {
+			tc := setup(`/api/v1/series?match[]=metric{source_id="some-id"}`)
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+			Expect(tc.baseHandlerCalled).To(BeTrue())
+
+			Expect(tc.spyPromQLParser.query).To(Equal(`metric{source_id="some-id"}`))
+			Expect(tc.spyLogAuthorizer.sourceIDsCalledWith).To(HaveKey("some-id"))
+		})
+
+		It("expands every selector to include all authorized related source IDs", func() {
+			tc := setup(`/api/v1/series?match[]=metric{source_id="some-id"}&match[]=other{source_id="some-id"}`)
+			tc.spyLogAuthorizer.unauthorizedSourceIds["some-id"] = struct{}{}
+			tc.spyAppNameTranslator.relatedIds = map[string][]string{"some-id": {"app-guid-1"}}
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+			Expect(tc.baseHandlerCalled).To(BeTrue())
+
+			Expect(tc.baseHandlerRequest.URL.Query()["match[]"]).To(Equal([]string{
+				`metric{source_id="app-guid-1"}`,
+				`other{source_id="app-guid-1"}`,
+			}))
+		})
+
+		It("authorizes selectors passed as match", func() {
+			tc := setup(`/api/v1/series?match=metric{source_id="some-id"}`)
+			tc.spyAppNameTranslator.relatedIds = map[string][]string{"some-id": {"app-guid-1"}}
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+			Expect(tc.baseHandlerCalled).To(BeTrue())
+
+			Expect(tc.baseHandlerRequest.URL.Query()).ToNot(HaveKey("match"))
+			Expect(tc.baseHandlerRequest.URL.Query()["match[]"]).To(Equal([]string{
+				`metric{source_id=~"app-guid-1|some-id"}`,
+			}))
+		})
+
+		It("returns 400 Bad Request if there is no selector", func() {
+			tc := setup(`/api/v1/series`)
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(tc.recorder.Body.String()).To(unmarshalledmatchers.ContainUnorderedJSON(`{
+				"status": "error",
+				"errorType": "bad_data"
+			}`))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+
+		It("returns 400 Bad Request if a selector doesn't have a source_id", func() {
+			tc := setup(`/api/v1/series?match[]=metric`)
+			tc.spyPromQLParser.sourceIDs = nil
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+
+		It("returns 404 Not Found if user is not authorized", func() {
+			tc := setup(`/api/v1/series?match[]=metric{source_id="some-id"}`)
+			tc.spyLogAuthorizer.unauthorizedSourceIds["some-id"] = struct{}{}
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+
+		It("returns 404 Not Found if there's no authorization header present", func() {
+			tc := setup(`/api/v1/series?match[]=metric{source_id="some-id"}`)
+			tc.request.Header.Del("Authorization")
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+	})
+
+	Describe("/api/v1/labels", func() {
+		It("returns 404 Not Found if user is not authorized", func() {
+			tc := setup(`/api/v1/labels?match[]=metric{source_id="some-id"}`)
+			tc.spyLogAuthorizer.unauthorizedSourceIds["some-id"] = struct{}{}
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+
+		It("forwards the request to the handler if non-admin user has log access", func() {
+			tc := setup(`/api/v1/labels?match[]=metric{source_id="some-id"}`)
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+			Expect(tc.baseHandlerCalled).To(BeTrue())
+		})
+	})
+
+	Describe("/api/v1/label/<name>/values", func() {
+		It("returns 404 Not Found if user is not authorized", func() {
+			tc := setup(`/api/v1/label/__name__/values?match[]=metric{source_id="some-id"}`)
+			tc.spyLogAuthorizer.unauthorizedSourceIds["some-id"] = struct{}{}
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(tc.baseHandlerCalled).To(BeFalse())
+		})
+
+		It("forwards the request to the handler if non-admin user has log access", func() {
+			tc := setup(`/api/v1/label/__name__/values?match[]=metric{source_id="some-id"}`)
+
+			tc.invokeAuthHandler()
+
+			Expect(tc.recorder.Code).To(Equal(http.StatusOK))
+			Expect(tc.baseHandlerCalled).To(BeTrue())
+		})
+	})
+
 	Describe("/api/v1/info", func() {
 		It("forwards the request to the handler without requiring authentication", func() {
 			tc := setup(`/api/v1/info`)
@@ -390,11 +811,12 @@ var _ = Describe("CfAuthMiddleware", func() {
 })
 
 type spyOauth2ClientReader struct {
-	token         string
-	isAdminResult bool
-	client        string
-	user          string
-	err           error
+	token             string
+	isAdminResult     bool
+	isLogsAdminResult bool
+	client            string
+	user              string
+	err               error
 }
 
 func newAdminChecker() *spyOauth2ClientReader {
@@ -404,8 +826,9 @@ func newAdminChecker() *spyOauth2ClientReader {
 func (s *spyOauth2ClientReader) Read(token string) (auth.Oauth2ClientContext, error) {
 	s.token = token
 	return auth.Oauth2ClientContext{
-		IsAdmin: s.isAdminResult,
-		Token:   token,
+		IsAdmin:     s.isAdminResult,
+		IsLogsAdmin: s.isLogsAdminResult,
+		Token:       token,
 	}, s.err
 }
 
@@ -440,20 +863,27 @@ func (s *spyLogAuthorizer) AvailableSourceIDs(token string) []string {
 }
 
 type spyMetaFetcher struct {
-	result map[string]*rpc.MetaInfo
-	err    error
-	ctx    context.Context
-	called int
+	result        map[string]*rpc.MetaInfo
+	nextPageToken string
+	err           error
+	ctx           context.Context
+	params        url.Values
+	called        int
 }
 
 func newSpyMetaFetcher() *spyMetaFetcher {
 	return &spyMetaFetcher{}
 }
 
-func (s *spyMetaFetcher) Meta(ctx context.Context) (map[string]*rpc.MetaInfo, error) {
+func (s *spyMetaFetcher) MetaPage(ctx context.Context, opts ...client.MetaOption) (map[string]*rpc.MetaInfo, string, error) {
 	s.called++
 	s.ctx = ctx
-	return s.result, s.err
+	s.params = url.Values{}
+	for _, o := range opts {
+		o(&url.URL{}, s.params)
+	}
+
+	return s.result, s.nextPageToken, s.err
 }
 
 type spyPromQLParser struct {
@@ -216,17 +216,22 @@ func (c *UAAClient) Read(token string) (Oauth2ClientContext, error) {
 		return Oauth2ClientContext{}, fmt.Errorf("token is expired, exp = %s", decodedToken.ExpTime)
 	}
 
-	var isAdmin bool
+	var isAdmin, isLogsAdmin bool
 	for _, scope := range decodedToken.Scope {
 		if scope == "doppler.firehose" || scope == "logs.admin" {
 			isAdmin = true
 		}
+
+		if scope == "logs.admin" {
+			isLogsAdmin = true
+		}
 	}
 
 	return Oauth2ClientContext{
-		IsAdmin:   isAdmin,
-		Token:     token,
-		ExpiresAt: decodedToken.ExpTime,
+		IsAdmin:     isAdmin,
+		IsLogsAdmin: isLogsAdmin,
+		Token:       token,
+		ExpiresAt:   decodedToken.ExpTime,
 	}, err
 }
 
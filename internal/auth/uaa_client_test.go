@@ -54,6 +54,7 @@ var _ = Describe("UAAClient", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(c.Token).To(Equal(withBearer(token)))
 			Expect(c.IsAdmin).To(BeTrue())
+			Expect(c.IsLogsAdmin).To(BeFalse())
 		})
 
 		It("returns IsAdmin == true when scopes include logs.admin", func() {
@@ -64,6 +65,7 @@ var _ = Describe("UAAClient", func() {
 			Expect(err).ToNot(HaveOccurred())
 			Expect(c.Token).To(Equal(withBearer(token)))
 			Expect(c.IsAdmin).To(BeTrue())
+			Expect(c.IsLogsAdmin).To(BeTrue())
 		})
 
 		It("returns IsAdmin == false when scopes include neither logs.admin nor doppler.firehose", func() {
@@ -39,6 +39,23 @@ type LogCache struct {
 	maxPerSource       int
 	memoryLimitPercent float64
 	queryTimeout       time.Duration
+	timerBuckets       []float64
+	maxQuerySourceIDs  int
+	resultsCache       bool
+	resultsCacheSize   int
+
+	// byteBudget is zero unless the LogCache was configured
+	// WithByteBudget.
+	byteBudget        int64
+	maxBytesPerSource int64
+
+	persistenceDir   string
+	snapshotInterval time.Duration
+	retentionPolicy  *store.RetentionPolicy
+	evictionPolicy   *store.EvictionPolicy
+	dedupWindow      time.Duration
+	storageEngine    store.StorageEngine
+	store            *store.Store
 
 	// Cluster Properties
 	addr     string
@@ -61,6 +78,7 @@ func New(m Metrics, logger *log.Logger, opts ...LogCacheOption) *LogCache {
 		maxPerSource:       100000,
 		memoryLimitPercent: 50,
 		queryTimeout:       10 * time.Second,
+		maxQuerySourceIDs:  100,
 
 		addr:     ":8080",
 		dialOpts: []grpc.DialOption{grpc.WithInsecure()},
@@ -90,6 +108,53 @@ func WithMaxPerSource(size int) LogCacheOption {
 	}
 }
 
+// WithPersistence returns a LogCacheOption that persists the store to a
+// write-ahead log and periodic snapshots in the given directory. On Start,
+// any persisted data is restored before requests are served. A snapshot
+// interval of zero or less disables periodic snapshots. Defaults to
+// disabled.
+func WithPersistence(dir string, snapshotInterval time.Duration) LogCacheOption {
+	return func(c *LogCache) {
+		c.persistenceDir = dir
+		c.snapshotInterval = snapshotInterval
+	}
+}
+
+// WithRetentionPolicy returns a LogCacheOption that expires envelopes once
+// they are older than the policy allows for their source ID. Defaults to
+// no age based expiry.
+func WithRetentionPolicy(p *store.RetentionPolicy) LogCacheOption {
+	return func(c *LogCache) {
+		c.retentionPolicy = p
+	}
+}
+
+// WithEvictionPolicy returns a LogCacheOption that prunes envelopes by
+// weighted eviction class when memory is scarce. Defaults to pruning the
+// oldest envelopes across every source ID.
+func WithEvictionPolicy(p *store.EvictionPolicy) LogCacheOption {
+	return func(c *LogCache) {
+		c.evictionPolicy = p
+	}
+}
+
+// WithDeduplication returns a LogCacheOption that drops envelopes that
+// were already received for the same source ID within the given window.
+// Defaults to keeping every envelope.
+func WithDeduplication(window time.Duration) LogCacheOption {
+	return func(c *LogCache) {
+		c.dedupWindow = window
+	}
+}
+
+// WithStorageEngine returns a LogCacheOption that selects how envelopes
+// are kept in memory. Defaults to store.AVLTreeEngine.
+func WithStorageEngine(e store.StorageEngine) LogCacheOption {
+	return func(c *LogCache) {
+		c.storageEngine = e
+	}
+}
+
 // WithAddr configures the address to listen for gRPC requests. It defaults to
 // :8080.
 func WithAddr(addr string) LogCacheOption {
@@ -114,6 +179,18 @@ func WithMemoryLimit(memoryPercent float64) LogCacheOption {
 	}
 }
 
+// WithByteBudget returns a LogCacheOption that prunes the store to the
+// given number of bytes instead of a percentage of the system memory. The
+// size of the store is estimated from the serialized size of its
+// envelopes. If maxBytesPerSource is greater than zero, each source ID is
+// limited to that many bytes as well. Defaults to pruning by memory limit.
+func WithByteBudget(budget, maxBytesPerSource int64) LogCacheOption {
+	return func(c *LogCache) {
+		c.byteBudget = budget
+		c.maxBytesPerSource = maxBytesPerSource
+	}
+}
+
 // WithQueryTimeout sets the maximum allowed runtime of a single PromQL query.
 // The default is 10s. If you increase this limit, make sure to keep in mind
 // that memory usage will increase with longer durations.
@@ -123,6 +200,42 @@ func WithQueryTimeout(queryTimeout time.Duration) LogCacheOption {
 	}
 }
 
+// WithMaxQuerySourceIDs limits the number of known source IDs a single
+// selector of a PromQL query may match through a regular expression or
+// negative matcher, e.g. source_id=~"app-.*". Fixed sets such as
+// source_id=~"a|b" are not limited. The default is 100. Zero removes the
+// limit.
+func WithMaxQuerySourceIDs(max int) LogCacheOption {
+	return func(c *LogCache) {
+		c.maxQuerySourceIDs = max
+	}
+}
+
+// WithQueryResultsCache caches the results of PromQL range queries per
+// step, so that refreshing a range query only evaluates the steps that were
+// not cached yet. At most maxEntries queries are cached, the least recently
+// used ones are evicted first. Zero removes the limit. Cached steps are
+// dropped once they are older than the oldest envelope in the local cache or
+// once a source ID they read from is purged. Envelopes that arrive more than
+// a minute late do not change cached steps. Defaults to evaluating every
+// step.
+func WithQueryResultsCache(maxEntries int) LogCacheOption {
+	return func(c *LogCache) {
+		c.resultsCache = true
+		c.resultsCacheSize = maxEntries
+	}
+}
+
+// WithTimerHistogramBuckets exposes timers to PromQL as Prometheus
+// histograms with the given bucket upper bounds in seconds, so that e.g.
+// histogram_quantile can be applied to them. Defaults to exposing timers
+// only as their durations.
+func WithTimerHistogramBuckets(buckets []float64) LogCacheOption {
+	return func(c *LogCache) {
+		c.timerBuckets = buckets
+	}
+}
+
 // WithClustered enables the LogCache to route data to peer nodes. It hashes
 // each envelope by SourceId and routes data that does not belong on the node
 // to the correct node. NodeAddrs is a slice of node addresses where the slice
@@ -150,16 +263,42 @@ func WithExternalAddr(addr string) LogCacheOption {
 // Start starts the LogCache. It has an internal go-routine that it creates
 // and therefore does not block.
 func (c *LogCache) Start() {
-	p := store.NewPruneConsultant(2, c.memoryLimitPercent, NewMemoryAnalyzer(c.metrics))
-	store := store.NewStore(c.maxPerSource, p, c.metrics)
-	c.setupRouting(store)
+	var p store.MemoryConsultant = store.NewPruneConsultant(2, c.memoryLimitPercent, NewMemoryAnalyzer(c.metrics))
+	if c.byteBudget > 0 {
+		p = store.NewByteBudgetConsultant(c.byteBudget, c.maxBytesPerSource)
+	}
+
+	var storeOpts []store.StoreOption
+	if c.persistenceDir != "" {
+		storeOpts = append(storeOpts, store.WithPersistence(c.persistenceDir, c.snapshotInterval, c.log))
+	}
+
+	if c.retentionPolicy != nil {
+		storeOpts = append(storeOpts, store.WithRetentionPolicy(c.retentionPolicy))
+	}
+
+	if c.evictionPolicy != nil {
+		storeOpts = append(storeOpts, store.WithEvictionPolicy(c.evictionPolicy))
+	}
+
+	if c.dedupWindow > 0 {
+		storeOpts = append(storeOpts, store.WithDeduplication(c.dedupWindow))
+	}
+	storeOpts = append(storeOpts, store.WithStorageEngine(c.storageEngine))
+
+	c.store = store.NewStore(c.maxPerSource, p, c.metrics, storeOpts...)
+	if err := c.store.Restore(); err != nil {
+		c.log.Fatalf("failed to restore persisted envelopes: %s", err)
+	}
+
+	c.setupRouting(c.store)
 }
 
-// Close will shutdown the gRPC server
+// Close will shutdown the gRPC server and flush any persisted data.
 func (c *LogCache) Close() error {
 	atomic.AddInt64(&c.closing, 1)
 	c.server.GracefulStop()
-	return nil
+	return c.store.Close()
 }
 
 func (c *LogCache) setupRouting(s *store.Store) {
@@ -186,10 +325,12 @@ func (c *LogCache) setupRouting(s *store.Store) {
 	var (
 		ingressClients []logcache_v1.IngressClient
 		egressClients  []logcache_v1.EgressClient
+		adminClients   []logcache_v1.AdminClient
 		localIdx       int
 	)
 
 	lcr := routing.NewLocalStoreReader(s)
+	purger := &resultsCachePurger{Store: s}
 
 	// Register peers and current node
 	for i, addr := range c.nodeAddrs {
@@ -212,6 +353,7 @@ func (c *LogCache) setupRouting(s *store.Store) {
 
 			ingressClients = append(ingressClients, bw)
 			egressClients = append(egressClients, logcache_v1.NewEgressClient(conn))
+			adminClients = append(adminClients, logcache_v1.NewAdminClient(conn))
 
 			continue
 		}
@@ -225,10 +367,21 @@ func (c *LogCache) setupRouting(s *store.Store) {
 			return &logcache_v1.SendResponse{}, nil
 		}))
 		egressClients = append(egressClients, lcr)
+		adminClients = append(adminClients, routing.NewLocalStoreAdmin(purger))
 	}
 
 	ingressReverseProxy := routing.NewIngressReverseProxy(lookup.Lookup, ingressClients, localIdx, c.log)
 	egressReverseProxy := routing.NewEgressReverseProxy(lookup.Lookup, egressClients, localIdx, c.log)
+	adminReverseProxy := routing.NewAdminReverseProxy(lookup.Lookup, adminClients, localIdx, c.log)
+
+	promQLOpts := []promql.PromQLOption{
+		promql.WithTimerHistogramBuckets(c.timerBuckets),
+		promql.WithMetaReader(egressReverseProxy),
+		promql.WithMaxSourceIDs(c.maxQuerySourceIDs),
+	}
+	if c.resultsCache {
+		promQLOpts = append(promQLOpts, promql.WithResultsCache(s, c.resultsCacheSize))
+	}
 
 	promQL := promql.New(
 		data_reader.NewWalkingDataReader(
@@ -237,12 +390,15 @@ func (c *LogCache) setupRouting(s *store.Store) {
 		c.metrics,
 		c.log,
 		c.queryTimeout,
+		promQLOpts...,
 	)
+	purger.promQL = promQL
 	c.server = grpc.NewServer(c.serverOpts...)
 
 	go func() {
 		logcache_v1.RegisterIngressServer(c.server, ingressReverseProxy)
 		logcache_v1.RegisterEgressServer(c.server, egressReverseProxy)
+		logcache_v1.RegisterAdminServer(c.server, adminReverseProxy)
 		logcache_v1.RegisterOrchestrationServer(c.server, orchestratorAgent)
 		logcache_v1.RegisterPromQLQuerierServer(c.server, promQL)
 		if err := c.server.Serve(lis); err != nil && atomic.LoadInt64(&c.closing) == 0 {
@@ -251,6 +407,20 @@ func (c *LogCache) setupRouting(s *store.Store) {
 	}()
 }
 
+// resultsCachePurger purges envelopes from the store and drops the cached
+// PromQL results that read from the purged source ID.
+type resultsCachePurger struct {
+	*store.Store
+	promQL *promql.PromQL
+}
+
+// Purge implements routing.StorePurger.
+func (p *resultsCachePurger) Purge(sourceID string, start, end time.Time) (int, error) {
+	defer p.promQL.PurgeResultsCache(sourceID)
+
+	return p.Store.Purge(sourceID, start, end)
+}
+
 // Addr returns the address that the LogCache is listening on. This is only
 // valid after Start has been invoked.
 func (c *LogCache) Addr() string {
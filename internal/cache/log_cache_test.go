@@ -17,6 +17,7 @@ import (
 	. "code.cloudfoundry.org/log-cache/internal/cache"
 	sharedtls "code.cloudfoundry.org/log-cache/internal/tls"
 	rpc "code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	"github.com/golang/protobuf/proto"
 
 	"code.cloudfoundry.org/log-cache/internal/testing"
 	. "github.com/onsi/ginkgo"
@@ -226,6 +227,64 @@ var _ = Describe("LogCache", func() {
 		}).Should(Equal(2.0))
 	})
 
+	It("streams new envelopes for a source ID", func() {
+		conn, err := grpc.Dial(cache.Addr(),
+			grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+		client := rpc.NewEgressClient(conn)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// source-0 hashes to 7700738999732113484 (route to node 0)
+		stream, err := client.Tail(ctx, &rpc.TailRequest{
+			SourceId:      "source-0",
+			EnvelopeTypes: []rpc.EnvelopeType{rpc.EnvelopeType_LOG},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		envelopes := make(chan *loggregator_v2.Envelope, 100)
+		go func() {
+			for {
+				resp, err := stream.Recv()
+				if err != nil {
+					return
+				}
+
+				for _, e := range resp.GetEnvelopes().GetBatch() {
+					envelopes <- e
+				}
+			}
+		}()
+
+		Eventually(func() int {
+			writeEnvelopes(cache.Addr(), []*loggregator_v2.Envelope{
+				{
+					Timestamp: 1,
+					SourceId:  "source-0",
+					Message:   &loggregator_v2.Envelope_Log{Log: &loggregator_v2.Log{}},
+				},
+				{
+					Timestamp: 2,
+					SourceId:  "source-0",
+					Message:   &loggregator_v2.Envelope_Counter{Counter: &loggregator_v2.Counter{}},
+				},
+				{
+					Timestamp: 3,
+					SourceId:  "source-1",
+					Message:   &loggregator_v2.Envelope_Log{Log: &loggregator_v2.Log{}},
+				},
+			})
+			return len(envelopes)
+		}).ShouldNot(BeZero())
+
+		e := <-envelopes
+		Expect(e.Timestamp).To(Equal(int64(1)))
+		Expect(e.SourceId).To(Equal("source-0"))
+	})
+
 	It("queries data via PromQL Instant Queries", func() {
 		now := time.Now()
 		writeEnvelopes(cache.Addr(), []*loggregator_v2.Envelope{
@@ -419,6 +478,64 @@ var _ = Describe("LogCache", func() {
 		Expect(req.EnvelopeTypes).To(ConsistOf(rpc.EnvelopeType_LOG))
 	})
 
+	It("purges source IDs on the node that stores them", func() {
+		// source-0 hashes to 7700738999732113484 (route to node 0)
+		writeEnvelopes(cache.Addr(), []*loggregator_v2.Envelope{
+			{SourceId: "source-0", Timestamp: 1},
+			{SourceId: "source-0", Timestamp: 2},
+			{SourceId: "source-0", Timestamp: 3},
+		})
+
+		conn, err := grpc.Dial(cache.Addr(),
+			grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+		)
+		Expect(err).ToNot(HaveOccurred())
+		defer conn.Close()
+		egressClient := rpc.NewEgressClient(conn)
+		adminClient := rpc.NewAdminClient(conn)
+
+		Eventually(func() int64 {
+			resp, err := egressClient.Meta(context.Background(), &rpc.MetaRequest{LocalOnly: true})
+			if err != nil {
+				return 0
+			}
+			return resp.Meta["source-0"].GetCount()
+		}).Should(Equal(int64(3)))
+
+		resp, err := adminClient.Purge(context.Background(), &rpc.PurgeRequest{
+			SourceId:  "source-0",
+			StartTime: 2,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Purged).To(Equal(int64(2)))
+
+		readResp, err := egressClient.Read(context.Background(), &rpc.ReadRequest{
+			SourceId: "source-0",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(readResp.Envelopes.Batch).To(HaveLen(1))
+		Expect(readResp.Envelopes.Batch[0].Timestamp).To(Equal(int64(1)))
+
+		// source-1 hashes to 15704273932878139171 (route to node 1)
+		_, err = adminClient.Purge(context.Background(), &rpc.PurgeRequest{
+			SourceId: "source-1",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		// Every node is sent the purge to drop its cached query results.
+		Expect(peer.GetPurgeRequests()).To(ConsistOf(
+			&rpc.PurgeRequest{
+				SourceId:  "source-0",
+				StartTime: 2,
+				LocalOnly: true,
+			},
+			&rpc.PurgeRequest{
+				SourceId:  "source-1",
+				LocalOnly: true,
+			},
+		))
+	})
+
 	It("returns all meta information", func() {
 		peer.MetaResponses = map[string]*rpc.MetaInfo{
 			"source-1": {
@@ -456,6 +573,8 @@ var _ = Describe("LogCache", func() {
 		}).Should(And(
 			HaveKeyWithValue("source-0", &rpc.MetaInfo{
 				Count: 1,
+				Bytes: int64(proto.Size(&loggregator_v2.Envelope{SourceId: "source-0"})),
+				Rate:  1.0 / 60,
 			}),
 			HaveKeyWithValue("source-1", &rpc.MetaInfo{
 				Count:           1,
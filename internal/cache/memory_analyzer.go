@@ -1,7 +1,13 @@
 package cache
 
 import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 
 	"sync"
 
@@ -9,7 +15,17 @@ import (
 	"github.com/cloudfoundry/gosigar"
 )
 
-// MemoryAnalyzer reports the available and total memory.
+const (
+	// defaultCgroupRoot is where the cgroup filesystem is usually mounted.
+	defaultCgroupRoot = "/sys/fs/cgroup"
+
+	// defaultProcCgroupFile lists the cgroups of the process.
+	defaultProcCgroupFile = "/proc/self/cgroup"
+)
+
+// MemoryAnalyzer reports the available and total memory. When the process
+// runs in a cgroup with a memory limit, the limit and the usage of the
+// cgroup are reported in place of the memory of the host.
 type MemoryAnalyzer struct {
 	// metrics
 	setAvail metrics.Gauge
@@ -21,16 +37,60 @@ type MemoryAnalyzer struct {
 	total uint64
 	heap  uint64
 
+	cgroupRoot     string
+	procCgroupFile string
+	cgroup         *cgroupMemory
+
 	sync.Mutex
 }
 
+// MemoryAnalyzerOption configures a MemoryAnalyzer.
+type MemoryAnalyzerOption func(*MemoryAnalyzer)
+
+// WithCgroupRoot returns a MemoryAnalyzerOption that sets the directory the
+// cgroup filesystem is mounted at. Defaults to /sys/fs/cgroup.
+func WithCgroupRoot(dir string) MemoryAnalyzerOption {
+	return func(a *MemoryAnalyzer) {
+		a.cgroupRoot = dir
+	}
+}
+
+// WithProcCgroupFile returns a MemoryAnalyzerOption that sets the file the
+// cgroups of the process are read from. Defaults to /proc/self/cgroup.
+func WithProcCgroupFile(path string) MemoryAnalyzerOption {
+	return func(a *MemoryAnalyzer) {
+		a.procCgroupFile = path
+	}
+}
+
 // NewMemoryAnalyzer creates and returns a new MemoryAnalyzer.
-func NewMemoryAnalyzer(m Metrics) *MemoryAnalyzer {
-	return &MemoryAnalyzer{
-		setAvail: m.NewGauge("log_cache_available_system_memory", metrics.WithMetricTags(map[string]string{"unit":"bytes"})),
-		setHeap:  m.NewGauge("log_cache_heap_in_use_memory", metrics.WithMetricTags(map[string]string{"unit":"bytes"})),
-		setTotal: m.NewGauge("log_cache_total_system_memory", metrics.WithMetricTags(map[string]string{"unit":"bytes"})),
+func NewMemoryAnalyzer(m Metrics, opts ...MemoryAnalyzerOption) *MemoryAnalyzer {
+	a := &MemoryAnalyzer{
+		cgroupRoot:     defaultCgroupRoot,
+		procCgroupFile: defaultProcCgroupFile,
+	}
+
+	for _, o := range opts {
+		o(a)
 	}
+
+	var host sigar.Mem
+	host.Get()
+
+	source := "host"
+	if c := detectCgroupMemory(a.cgroupRoot, a.procCgroupFile); c != nil {
+		if limit, _, ok := c.read(); ok && limit < host.Total {
+			a.cgroup = c
+			source = c.version
+		}
+	}
+
+	tags := map[string]string{"unit": "bytes", "source": source}
+	a.setAvail = m.NewGauge("log_cache_available_system_memory", metrics.WithMetricTags(tags))
+	a.setHeap = m.NewGauge("log_cache_heap_in_use_memory", metrics.WithMetricTags(map[string]string{"unit": "bytes"}))
+	a.setTotal = m.NewGauge("log_cache_total_system_memory", metrics.WithMetricTags(tags))
+
+	return a
 }
 
 // Memory returns the heap memory and total system memory.
@@ -38,14 +98,10 @@ func (a *MemoryAnalyzer) Memory() (heapInUse, available, total uint64) {
 	a.Lock()
 	defer a.Unlock()
 
-	var m sigar.Mem
-	m.Get()
-
-	a.avail = m.ActualFree
-	a.total = m.Total
+	a.avail, a.total = a.systemMemory()
 
-	a.setAvail.Set(float64(m.ActualFree))
-	a.setTotal.Set(float64(m.Total))
+	a.setAvail.Set(float64(a.avail))
+	a.setTotal.Set(float64(a.total))
 
 	var rm runtime.MemStats
 	runtime.ReadMemStats(&rm)
@@ -55,3 +111,185 @@ func (a *MemoryAnalyzer) Memory() (heapInUse, available, total uint64) {
 
 	return a.heap, a.avail, a.total
 }
+
+// systemMemory returns the available and total memory of the cgroup. It
+// falls back to the memory of the host if there is no cgroup limit or it
+// can not be read.
+func (a *MemoryAnalyzer) systemMemory() (available, total uint64) {
+	if a.cgroup != nil {
+		if limit, usage, ok := a.cgroup.read(); ok {
+			if usage > limit {
+				return 0, limit
+			}
+			return limit - usage, limit
+		}
+	}
+
+	var m sigar.Mem
+	m.Get()
+
+	return m.ActualFree, m.Total
+}
+
+// cgroupMemory reads the memory limit and usage of a cgroup.
+type cgroupMemory struct {
+	// version is either cgroup_v1 or cgroup_v2.
+	version string
+
+	limitFile string
+	usageFile string
+	statFile  string
+
+	// inactiveStat is the key in the stat file of the page cache that can
+	// be reclaimed. It is not counted as usage.
+	inactiveStat string
+}
+
+// detectCgroupMemory returns the memory controller of the cgroup of the
+// process in the cgroup filesystem mounted at root. The cgroup is resolved
+// from procFile. It returns nil if there is none.
+func detectCgroupMemory(root, procFile string) *cgroupMemory {
+	v1Path, v2Path := readProcCgroup(procFile)
+
+	if fileExists(filepath.Join(root, "cgroup.controllers")) {
+		root = cgroupDir(root, v2Path, "memory.max")
+		return &cgroupMemory{
+			version:      "cgroup_v2",
+			limitFile:    filepath.Join(root, "memory.max"),
+			usageFile:    filepath.Join(root, "memory.current"),
+			statFile:     filepath.Join(root, "memory.stat"),
+			inactiveStat: "inactive_file",
+		}
+	}
+
+	dir := cgroupDir(filepath.Join(root, "memory"), v1Path, "memory.limit_in_bytes")
+	if fileExists(filepath.Join(dir, "memory.limit_in_bytes")) {
+		return &cgroupMemory{
+			version:      "cgroup_v1",
+			limitFile:    filepath.Join(dir, "memory.limit_in_bytes"),
+			usageFile:    filepath.Join(dir, "memory.usage_in_bytes"),
+			statFile:     filepath.Join(dir, "memory.stat"),
+			inactiveStat: "total_inactive_file",
+		}
+	}
+
+	return nil
+}
+
+// readProcCgroup returns the path of the cgroup v1 memory controller and
+// of the cgroup v2 hierarchy that are listed in a /proc/<pid>/cgroup file.
+// Each line has the form hierarchy-ID:controller-list:cgroup-path, where
+// cgroup v2 has the ID 0 and no controllers.
+func readProcCgroup(path string) (v1Path, v2Path string) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", ""
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.SplitN(s.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		if fields[0] == "0" && fields[1] == "" {
+			v2Path = fields[2]
+			continue
+		}
+
+		for _, c := range strings.Split(fields[1], ",") {
+			if c == "memory" {
+				v1Path = fields[2]
+			}
+		}
+	}
+
+	return v1Path, v2Path
+}
+
+// cgroupDir returns the directory of the cgroup path in the hierarchy
+// mounted at root. Inside of a container the hierarchy of the container is
+// usually mounted at root while the path is still relative to the host, so
+// root is returned if the cgroup directory does not have the given file.
+func cgroupDir(root, path, file string) string {
+	if path == "" {
+		return root
+	}
+
+	dir := filepath.Join(root, path)
+	if !fileExists(filepath.Join(dir, file)) {
+		return root
+	}
+
+	return dir
+}
+
+// read returns the memory limit and usage of the cgroup. It returns false
+// if the cgroup does not have a limit or it can not be read.
+func (c *cgroupMemory) read() (limit, usage uint64, ok bool) {
+	limit, ok = readCgroupValue(c.limitFile)
+	if !ok {
+		return 0, 0, false
+	}
+
+	usage, ok = readCgroupValue(c.usageFile)
+	if !ok {
+		return 0, 0, false
+	}
+
+	inactive := readCgroupStat(c.statFile, c.inactiveStat)
+	if inactive < usage {
+		usage -= inactive
+	}
+
+	return limit, usage, true
+}
+
+// readCgroupValue reads a file that holds a single number of bytes. The
+// value max means there is no limit.
+func readCgroupValue(path string) (uint64, bool) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	v, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return v, true
+}
+
+// readCgroupStat returns the value of the given key in a memory.stat file.
+// It returns 0 if the key can not be read.
+func readCgroupStat(path, key string) uint64 {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) != 2 || fields[0] != key {
+			continue
+		}
+
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0
+		}
+		return v
+	}
+
+	return 0
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
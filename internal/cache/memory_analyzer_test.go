@@ -0,0 +1,144 @@
+package cache_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	. "code.cloudfoundry.org/log-cache/internal/cache"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MemoryAnalyzer", func() {
+	var (
+		root       string
+		spyMetrics *testhelpers.SpyMetricsRegistry
+	)
+
+	BeforeEach(func() {
+		var err error
+		root, err = ioutil.TempDir("", "cgroup")
+		Expect(err).ToNot(HaveOccurred())
+
+		spyMetrics = testhelpers.NewMetricsRegistry()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(root)
+	})
+
+	writeFile := func(name, contents string) {
+		path := filepath.Join(root, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+	}
+
+	It("uses the memory limit and usage of a cgroup v2", func() {
+		writeFile("cgroup.controllers", "cpu memory\n")
+		writeFile("memory.max", "1000\n")
+		writeFile("memory.current", "600\n")
+		writeFile("memory.stat", "anon 300\ninactive_file 200\nactive_file 100\n")
+
+		a := NewMemoryAnalyzer(spyMetrics, WithCgroupRoot(root))
+
+		heap, avail, total := a.Memory()
+		Expect(heap).ToNot(BeZero())
+		Expect(avail).To(Equal(uint64(600)))
+		Expect(total).To(Equal(uint64(1000)))
+
+		tags := map[string]string{"unit": "bytes", "source": "cgroup_v2"}
+		Expect(spyMetrics.GetMetricValue("log_cache_total_system_memory", tags)).To(Equal(1000.0))
+		Expect(spyMetrics.GetMetricValue("log_cache_available_system_memory", tags)).To(Equal(600.0))
+
+		writeFile("memory.current", "1500\n")
+		_, avail, _ = a.Memory()
+		Expect(avail).To(BeZero())
+	})
+
+	It("uses the memory limit and usage of a cgroup v1", func() {
+		writeFile("memory/memory.limit_in_bytes", "2000\n")
+		writeFile("memory/memory.usage_in_bytes", "1500\n")
+		writeFile("memory/memory.stat", "cache 700\ntotal_inactive_file 500\n")
+
+		a := NewMemoryAnalyzer(spyMetrics, WithCgroupRoot(root))
+
+		_, avail, total := a.Memory()
+		Expect(avail).To(Equal(uint64(1000)))
+		Expect(total).To(Equal(uint64(2000)))
+
+		tags := map[string]string{"unit": "bytes", "source": "cgroup_v1"}
+		Expect(spyMetrics.GetMetricValue("log_cache_total_system_memory", tags)).To(Equal(2000.0))
+	})
+
+	It("uses the cgroup v2 of the process", func() {
+		writeFile("cgroup.controllers", "cpu memory\n")
+		writeFile("system.slice/log-cache.service/memory.max", "1000\n")
+		writeFile("system.slice/log-cache.service/memory.current", "400\n")
+		writeFile("proc/cgroup", "0::/system.slice/log-cache.service\n")
+
+		a := NewMemoryAnalyzer(spyMetrics,
+			WithCgroupRoot(root),
+			WithProcCgroupFile(filepath.Join(root, "proc/cgroup")),
+		)
+
+		_, avail, total := a.Memory()
+		Expect(avail).To(Equal(uint64(600)))
+		Expect(total).To(Equal(uint64(1000)))
+	})
+
+	It("uses the cgroup v1 memory controller of the process", func() {
+		writeFile("memory/memory.limit_in_bytes", "9223372036854771712\n")
+		writeFile("memory/docker/some-id/memory.limit_in_bytes", "2000\n")
+		writeFile("memory/docker/some-id/memory.usage_in_bytes", "500\n")
+		writeFile("proc/cgroup", "12:cpu,cpuacct:/docker/some-id\n4:memory:/docker/some-id\n0::/system.slice\n")
+
+		a := NewMemoryAnalyzer(spyMetrics,
+			WithCgroupRoot(root),
+			WithProcCgroupFile(filepath.Join(root, "proc/cgroup")),
+		)
+
+		_, avail, total := a.Memory()
+		Expect(avail).To(Equal(uint64(1500)))
+		Expect(total).To(Equal(uint64(2000)))
+	})
+
+	It("uses the cgroup at the root if the cgroup of the process is not mounted", func() {
+		writeFile("memory/memory.limit_in_bytes", "2000\n")
+		writeFile("memory/memory.usage_in_bytes", "1500\n")
+		writeFile("proc/cgroup", "4:memory:/docker/some-id\n")
+
+		a := NewMemoryAnalyzer(spyMetrics,
+			WithCgroupRoot(root),
+			WithProcCgroupFile(filepath.Join(root, "proc/cgroup")),
+		)
+
+		_, avail, total := a.Memory()
+		Expect(avail).To(Equal(uint64(500)))
+		Expect(total).To(Equal(uint64(2000)))
+	})
+
+	It("uses the memory of the host without a cgroup limit", func() {
+		writeFile("cgroup.controllers", "cpu memory\n")
+		writeFile("memory.max", "max\n")
+		writeFile("memory.current", "600\n")
+
+		a := NewMemoryAnalyzer(spyMetrics, WithCgroupRoot(root))
+
+		_, _, total := a.Memory()
+		Expect(total).To(BeNumerically(">", 1000))
+
+		tags := map[string]string{"unit": "bytes", "source": "host"}
+		Expect(spyMetrics.GetMetricValue("log_cache_total_system_memory", tags)).To(Equal(float64(total)))
+	})
+
+	It("uses the memory of the host without a cgroup filesystem", func() {
+		a := NewMemoryAnalyzer(spyMetrics, WithCgroupRoot(filepath.Join(root, "missing")))
+
+		_, _, total := a.Memory()
+		Expect(total).To(BeNumerically(">", 1000))
+		Expect(spyMetrics.HasMetric("log_cache_total_system_memory", map[string]string{"unit": "bytes", "source": "host"})).To(BeTrue())
+	})
+})
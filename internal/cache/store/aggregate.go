@@ -0,0 +1,138 @@
+package store
+
+import (
+	"math"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+)
+
+// Aggregate downsamples the values of the counters, gauge metrics and timers
+// with the given name within [start..end) into buckets of the given step.
+// Buckets start at multiples of the step and buckets without values are
+// left out. The value of a timer is its duration.
+func (store *Store) Aggregate(
+	index string,
+	start time.Time,
+	end time.Time,
+	name string,
+	step time.Duration,
+	aggregation logcache_v1.ReadAggregateRequest_Aggregation,
+) []*logcache_v1.ReadAggregateResponse_Point {
+	tree, ok := store.storageIndex.Load(index)
+	if !ok {
+		return nil
+	}
+
+	tree.(*storage).RLock()
+	defer tree.(*storage).RUnlock()
+
+	var (
+		points []*logcache_v1.ReadAggregateResponse_Point
+		b      *bucket
+	)
+	visit := func(e *loggregator_v2.Envelope, _ uint64) bool {
+		value, ok := metricValue(e, name)
+		if !ok {
+			return false
+		}
+
+		bucketStart := alignToStep(e.GetTimestamp(), int64(step))
+		if b != nil && b.start != bucketStart {
+			points = append(points, b.point(aggregation))
+			b = nil
+		}
+
+		if b == nil {
+			b = &bucket{start: bucketStart}
+		}
+		b.add(value)
+
+		return false
+	}
+
+	timestamps := tree.(*storage).names.lookupName(name, start.UnixNano(), end.UnixNano())
+	tree.(*storage).TraverseTimestamps(timestamps, false, visit)
+
+	if b != nil {
+		points = append(points, b.point(aggregation))
+	}
+
+	return points
+}
+
+// metricValue returns the value of the counter, gauge metric or timer with
+// the given name. It returns false if the envelope does not have one.
+func metricValue(e *loggregator_v2.Envelope, name string) (float64, bool) {
+	switch m := e.Message.(type) {
+	case *loggregator_v2.Envelope_Counter:
+		if m.Counter.GetName() == name {
+			return float64(m.Counter.GetTotal()), true
+		}
+	case *loggregator_v2.Envelope_Gauge:
+		if v, ok := m.Gauge.GetMetrics()[name]; ok {
+			return v.GetValue(), true
+		}
+	case *loggregator_v2.Envelope_Timer:
+		if m.Timer.GetName() == name {
+			return float64(m.Timer.GetStop() - m.Timer.GetStart()), true
+		}
+	}
+
+	return 0, false
+}
+
+// alignToStep returns the start of the step the timestamp falls into.
+func alignToStep(ts, step int64) int64 {
+	start := ts - ts%step
+	if ts%step < 0 {
+		start -= step
+	}
+
+	return start
+}
+
+// bucket accumulates the values of a single step.
+type bucket struct {
+	start int64
+
+	count int64
+	sum   float64
+	min   float64
+	max   float64
+	last  float64
+}
+
+func (b *bucket) add(v float64) {
+	if b.count == 0 {
+		b.min, b.max = v, v
+	}
+
+	b.count++
+	b.sum += v
+	b.min = math.Min(b.min, v)
+	b.max = math.Max(b.max, v)
+	b.last = v
+}
+
+func (b *bucket) point(aggregation logcache_v1.ReadAggregateRequest_Aggregation) *logcache_v1.ReadAggregateResponse_Point {
+	p := &logcache_v1.ReadAggregateResponse_Point{Timestamp: b.start}
+
+	switch aggregation {
+	case logcache_v1.ReadAggregateRequest_MIN:
+		p.Value = b.min
+	case logcache_v1.ReadAggregateRequest_MAX:
+		p.Value = b.max
+	case logcache_v1.ReadAggregateRequest_AVG:
+		p.Value = b.sum / float64(b.count)
+	case logcache_v1.ReadAggregateRequest_SUM:
+		p.Value = b.sum
+	case logcache_v1.ReadAggregateRequest_COUNT:
+		p.Value = float64(b.count)
+	default:
+		p.Value = b.last
+	}
+
+	return p
+}
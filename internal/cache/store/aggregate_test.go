@@ -0,0 +1,110 @@
+package store_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/internal/cache/store"
+	rpc "code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Aggregate", func() {
+	for _, engine := range []store.StorageEngine{store.AVLTreeEngine, store.CompactEngine, store.SegmentEngine} {
+		engine := engine
+		Context(engine.String(), func() { describeAggregate(engine) })
+	}
+})
+
+func describeAggregate(engine store.StorageEngine) {
+	var (
+		s *store.Store
+	)
+
+	buildGauge := func(timestamp int64, name string, value float64) *loggregator_v2.Envelope {
+		return &loggregator_v2.Envelope{
+			Timestamp: timestamp,
+			SourceId:  "a",
+			Message: &loggregator_v2.Envelope_Gauge{
+				Gauge: &loggregator_v2.Gauge{
+					Metrics: map[string]*loggregator_v2.GaugeValue{
+						name: {Value: value},
+					},
+				},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		s = store.NewStore(100, newSpyPruner(), testhelpers.NewMetricsRegistry(), store.WithStorageEngine(engine))
+
+		for i, v := range []float64{3, 1, 2, 10, 4} {
+			s.Put(buildGauge(int64(i)*5, "cpu", v), "a")
+		}
+		s.Put(buildGauge(7, "memory", 99), "a")
+	})
+
+	DescribeTable("downsamples gauge values into step aligned buckets",
+		func(aggregation rpc.ReadAggregateRequest_Aggregation, values []float64) {
+			points := s.Aggregate("a", time.Unix(0, 0), time.Unix(0, 100), "cpu", 10, aggregation)
+			Expect(points).To(Equal([]*rpc.ReadAggregateResponse_Point{
+				{Timestamp: 0, Value: values[0]},
+				{Timestamp: 10, Value: values[1]},
+				{Timestamp: 20, Value: values[2]},
+			}))
+		},
+		Entry("LAST", rpc.ReadAggregateRequest_LAST, []float64{1, 10, 4}),
+		Entry("MIN", rpc.ReadAggregateRequest_MIN, []float64{1, 2, 4}),
+		Entry("MAX", rpc.ReadAggregateRequest_MAX, []float64{3, 10, 4}),
+		Entry("AVG", rpc.ReadAggregateRequest_AVG, []float64{2, 6, 4}),
+		Entry("SUM", rpc.ReadAggregateRequest_SUM, []float64{4, 12, 4}),
+		Entry("COUNT", rpc.ReadAggregateRequest_COUNT, []float64{2, 2, 1}),
+	)
+
+	It("only aggregates values within the time range", func() {
+		points := s.Aggregate("a", time.Unix(0, 5), time.Unix(0, 15), "cpu", 10, rpc.ReadAggregateRequest_COUNT)
+		Expect(points).To(Equal([]*rpc.ReadAggregateResponse_Point{
+			{Timestamp: 0, Value: 1},
+			{Timestamp: 10, Value: 1},
+		}))
+	})
+
+	It("leaves out buckets without values", func() {
+		s.Put(buildGauge(95, "cpu", 7), "a")
+
+		points := s.Aggregate("a", time.Unix(0, 0), time.Unix(0, 100), "cpu", 10, rpc.ReadAggregateRequest_LAST)
+		Expect(points).To(HaveLen(4))
+		Expect(points[3]).To(Equal(&rpc.ReadAggregateResponse_Point{Timestamp: 90, Value: 7}))
+	})
+
+	It("aggregates counter totals and timer durations", func() {
+		s.Put(&loggregator_v2.Envelope{
+			Timestamp: 1,
+			SourceId:  "b",
+			Message: &loggregator_v2.Envelope_Counter{
+				Counter: &loggregator_v2.Counter{Name: "requests", Total: 20},
+			},
+		}, "b")
+		s.Put(&loggregator_v2.Envelope{
+			Timestamp: 2,
+			SourceId:  "b",
+			Message: &loggregator_v2.Envelope_Timer{
+				Timer: &loggregator_v2.Timer{Name: "requests", Start: 100, Stop: 140},
+			},
+		}, "b")
+
+		points := s.Aggregate("b", time.Unix(0, 0), time.Unix(0, 100), "requests", 10, rpc.ReadAggregateRequest_SUM)
+		Expect(points).To(Equal([]*rpc.ReadAggregateResponse_Point{
+			{Timestamp: 0, Value: 60},
+		}))
+	})
+
+	It("returns nothing for an unknown source ID or name", func() {
+		Expect(s.Aggregate("b", time.Unix(0, 0), time.Unix(0, 100), "cpu", 10, rpc.ReadAggregateRequest_LAST)).To(BeEmpty())
+		Expect(s.Aggregate("a", time.Unix(0, 0), time.Unix(0, 100), "disk", 10, rpc.ReadAggregateRequest_LAST)).To(BeEmpty())
+	})
+}
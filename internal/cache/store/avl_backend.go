@@ -0,0 +1,152 @@
+package store
+
+import (
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/emirpasic/gods/trees/avltree"
+)
+
+// avlBackend keeps envelopes in an AVL tree keyed by timestamp and
+// sequence number, so envelopes with the same timestamp do not overwrite
+// each other.
+type avlBackend struct {
+	tree *avltree.Tree
+}
+
+// avlKey orders envelopes by timestamp and then by the order they were put
+// in.
+type avlKey struct {
+	timestamp int64
+	seq       uint64
+}
+
+func compareAVLKeys(a, b interface{}) int {
+	ka, kb := a.(avlKey), b.(avlKey)
+	switch {
+	case ka.timestamp < kb.timestamp:
+		return -1
+	case ka.timestamp > kb.timestamp:
+		return 1
+	case ka.seq < kb.seq:
+		return -1
+	case ka.seq > kb.seq:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func newAVLBackend() *avlBackend {
+	return &avlBackend{
+		tree: avltree.NewWith(compareAVLKeys),
+	}
+}
+
+func (b *avlBackend) Put(e *loggregator_v2.Envelope, seq uint64) {
+	b.tree.Put(avlKey{timestamp: e.GetTimestamp(), seq: seq}, e)
+}
+
+func (b *avlBackend) Size() int {
+	return b.tree.Size()
+}
+
+func (b *avlBackend) Oldest() (int64, bool) {
+	if b.tree.Size() == 0 {
+		return 0, false
+	}
+
+	return b.tree.Left().Key.(avlKey).timestamp, true
+}
+
+func (b *avlBackend) RemoveOldest() envelopeStats {
+	if b.tree.Size() == 0 {
+		return envelopeStats{}
+	}
+
+	oldest := b.tree.Left()
+	b.tree.Remove(oldest.Key)
+
+	return statsOf(oldest.Value.(*loggregator_v2.Envelope))
+}
+
+func (b *avlBackend) RemoveBefore(cutoff int64) envelopeStats {
+	return removeOldestBefore(b, cutoff)
+}
+
+func (b *avlBackend) Traverse(start, end int64, descending bool, f visitor) {
+	em := &envelopeEmitter{f: f}
+	if descending {
+		treeDescTraverse(b.tree.Root, start, end, em)
+		return
+	}
+
+	treeAscTraverse(b.tree.Root, start, end, em)
+}
+
+func (b *avlBackend) TraverseTimestamps(timestamps []int64, descending bool, f visitor) {
+	em := &envelopeEmitter{f: f}
+	for i := range timestamps {
+		ts := timestamps[i]
+		if descending {
+			ts = timestamps[len(timestamps)-1-i]
+			treeDescTraverse(b.tree.Root, ts, ts+1, em)
+		} else {
+			treeAscTraverse(b.tree.Root, ts, ts+1, em)
+		}
+
+		// Each lookup is limited to a single timestamp, which is always
+		// visited completely.
+		if em.stopped {
+			return
+		}
+	}
+}
+
+// treeAscTraverse emits the envelopes within [start..end) in ascending
+// order. It returns true once the traversal is done.
+func treeAscTraverse(n *avltree.Node, start, end int64, em *envelopeEmitter) bool {
+	if n == nil {
+		return false
+	}
+
+	k := n.Key.(avlKey)
+	if k.timestamp >= start {
+		if treeAscTraverse(n.Children[0], start, end, em) {
+			return true
+		}
+
+		if k.timestamp >= end {
+			return true
+		}
+
+		if em.emit(entry{e: n.Value.(*loggregator_v2.Envelope), seq: k.seq}) {
+			return true
+		}
+	}
+
+	return treeAscTraverse(n.Children[1], start, end, em)
+}
+
+// treeDescTraverse emits the envelopes within [start..end) in descending
+// order. It returns true once the traversal is done.
+func treeDescTraverse(n *avltree.Node, start, end int64, em *envelopeEmitter) bool {
+	if n == nil {
+		return false
+	}
+
+	k := n.Key.(avlKey)
+	if k.timestamp < end {
+		if treeDescTraverse(n.Children[1], start, end, em) {
+			return true
+		}
+
+		if k.timestamp < start {
+			return true
+		}
+
+		if em.emit(entry{e: n.Value.(*loggregator_v2.Envelope), seq: k.seq}) {
+			return true
+		}
+	}
+
+	return treeDescTraverse(n.Children[0], start, end, em)
+}
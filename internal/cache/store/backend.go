@@ -0,0 +1,192 @@
+package store
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// backend keeps the envelopes of a single source ID ordered by timestamp.
+// Envelopes that share a timestamp are ordered by the sequence number they
+// were put with. Implementations are not thread safe. The lock of the
+// storage that owns the backend has to be held.
+type backend interface {
+	// Put adds the envelope. The sequence number has to be greater than
+	// that of every envelope with the same timestamp.
+	Put(e *loggregator_v2.Envelope, seq uint64)
+
+	// Size returns the number of envelopes.
+	Size() int
+
+	// Oldest returns the timestamp of the oldest envelope. It returns false
+	// if there are no envelopes.
+	Oldest() (int64, bool)
+
+	// RemoveOldest removes the oldest envelope and returns its stats.
+	RemoveOldest() envelopeStats
+
+	// RemoveBefore removes every envelope that is older than cutoff and
+	// returns the stats of the removed envelopes.
+	RemoveBefore(cutoff int64) envelopeStats
+
+	// Traverse invokes f with each envelope within [start..end) in
+	// ascending or descending order until f returns true. Envelopes that
+	// share their timestamp with the envelope f returned true for are
+	// still passed to f, so that they are never split up.
+	Traverse(start, end int64, descending bool, f visitor)
+
+	// TraverseTimestamps behaves like Traverse but only visits envelopes
+	// with one of the given timestamps. The timestamps are distinct and in
+	// ascending order.
+	TraverseTimestamps(timestamps []int64, descending bool, f visitor)
+}
+
+// visitor is invoked with each envelope of a traversal and the sequence
+// number the envelope was put with. It returns true to stop the traversal.
+type visitor func(e *loggregator_v2.Envelope, seq uint64) bool
+
+// entry is an envelope along with the sequence number it was put with.
+type entry struct {
+	e   *loggregator_v2.Envelope
+	seq uint64
+}
+
+func (en entry) timestamp() int64 { return en.e.GetTimestamp() }
+
+// before returns true if the entry is ordered before the other one.
+func (en entry) before(other entry) bool {
+	if en.timestamp() != other.timestamp() {
+		return en.timestamp() < other.timestamp()
+	}
+
+	return en.seq < other.seq
+}
+
+// StorageEngine determines how the envelopes of each source ID are kept in
+// memory.
+type StorageEngine int
+
+const (
+	// AVLTreeEngine keeps each envelope in an AVL tree node.
+	AVLTreeEngine StorageEngine = iota
+
+	// CompactEngine encodes envelopes into compressed blocks with interned
+	// tags. It trades CPU time on Get for a considerably smaller heap.
+	CompactEngine
+
+	// SegmentEngine appends envelopes to segments that each cover a fixed
+	// span of time. It keeps any number of envelopes with the same
+	// timestamp and evicts whole segments by age.
+	SegmentEngine
+)
+
+// ParseStorageEngine returns the StorageEngine for the given name.
+func ParseStorageEngine(name string) (StorageEngine, error) {
+	switch name {
+	case "", "avltree":
+		return AVLTreeEngine, nil
+	case "compact":
+		return CompactEngine, nil
+	case "segment":
+		return SegmentEngine, nil
+	default:
+		return 0, fmt.Errorf("unknown storage engine %q", name)
+	}
+}
+
+func (e StorageEngine) String() string {
+	switch e {
+	case AVLTreeEngine:
+		return "avltree"
+	case CompactEngine:
+		return "compact"
+	case SegmentEngine:
+		return "segment"
+	default:
+		return "unknown"
+	}
+}
+
+// WithStorageEngine returns a StoreOption that selects the StorageEngine.
+// Defaults to AVLTreeEngine.
+func WithStorageEngine(e StorageEngine) StoreOption {
+	return func(s *Store) {
+		s.engine = e
+	}
+}
+
+func (store *Store) newBackend() backend {
+	switch store.engine {
+	case CompactEngine:
+		return newCompactBackend()
+	case SegmentEngine:
+		return newSegmentBackend()
+	default:
+		return newAVLBackend()
+	}
+}
+
+// removeOldestBefore removes the oldest envelope of the backend until none
+// is older than cutoff and returns the stats of the removed envelopes.
+func removeOldestBefore(b backend, cutoff int64) envelopeStats {
+	var removed envelopeStats
+	for {
+		oldest, ok := b.Oldest()
+		if !ok || oldest >= cutoff {
+			return removed
+		}
+
+		removed.add(b.RemoveOldest())
+	}
+}
+
+// envelopeEmitter passes envelopes on to a visitor. Once the visitor
+// returns true, only envelopes with the same timestamp are still passed on.
+type envelopeEmitter struct {
+	f       visitor
+	stopped bool
+	stopTs  int64
+}
+
+// emitAsc passes the entries on in order. It returns true once the
+// traversal is done.
+func (em *envelopeEmitter) emitAsc(entries []entry) bool {
+	for _, en := range entries {
+		if em.emit(en) {
+			return true
+		}
+	}
+
+	return em.stopped
+}
+
+// emitDesc passes the entries on in reverse order. It returns true once the
+// traversal is done.
+func (em *envelopeEmitter) emitDesc(entries []entry) bool {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if em.emit(entries[i]) {
+			return true
+		}
+	}
+
+	return em.stopped
+}
+
+// emit passes the entry on. It returns true once the traversal is done.
+func (em *envelopeEmitter) emit(en entry) bool {
+	if em.stopped {
+		if en.timestamp() != em.stopTs {
+			return true
+		}
+
+		em.f(en.e, en.seq)
+		return false
+	}
+
+	if em.f(en.e, en.seq) {
+		em.stopped = true
+		em.stopTs = en.timestamp()
+	}
+
+	return false
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"math"
+	"sync"
+
+	"code.cloudfoundry.org/go-loggregator/metrics"
+)
+
+// ByteBudgetConsultant prunes the store down to a fixed number of bytes,
+// measured by the estimated serialized size of the stored envelopes. Unlike
+// the PruneConsultant it does not depend on the heap, so it does not lag
+// behind the garbage collector.
+type ByteBudgetConsultant struct {
+	budget            int64
+	maxBytesPerSource int64
+
+	mu           sync.Mutex
+	storeBytes   func() int64
+	reportMemory metrics.Gauge
+}
+
+// byteConsultant is implemented by MemoryConsultants that need to know the
+// size of the stored envelopes. The Store hands itself over on creation.
+type byteConsultant interface {
+	trackBytes(storeBytes func() int64)
+	perSourceBytes() int64
+}
+
+// NewByteBudgetConsultant returns a new ByteBudgetConsultant that keeps the
+// store within budget bytes. If maxBytesPerSource is greater than zero, the
+// oldest envelopes of a source ID are also evicted once its envelopes
+// exceed that many bytes.
+func NewByteBudgetConsultant(budget, maxBytesPerSource int64) *ByteBudgetConsultant {
+	return &ByteBudgetConsultant{
+		budget:            budget,
+		maxBytesPerSource: maxBytesPerSource,
+	}
+}
+
+// SetMemoryReporter accepts a gauge that is set to the percentage of the
+// budget in use.
+func (c *ByteBudgetConsultant) SetMemoryReporter(mr metrics.Gauge) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reportMemory = mr
+}
+
+// GetQuantityToPrune reports how many entries should be removed. It
+// assumes every envelope is of the average size.
+func (c *ByteBudgetConsultant) GetQuantityToPrune(storeCount int64) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.storeBytes == nil || storeCount == 0 {
+		return 0
+	}
+
+	bytes := c.storeBytes()
+
+	if c.reportMemory != nil {
+		c.reportMemory.Set(float64(bytes*100) / float64(c.budget))
+	}
+
+	if bytes <= c.budget {
+		return 0
+	}
+
+	percentageToPrune := float64(bytes-c.budget) / float64(bytes)
+	return int(math.Ceil(float64(storeCount) * percentageToPrune))
+}
+
+func (c *ByteBudgetConsultant) trackBytes(storeBytes func() int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.storeBytes = storeBytes
+}
+
+func (c *ByteBudgetConsultant) perSourceBytes() int64 {
+	return c.maxBytesPerSource
+}
@@ -0,0 +1,86 @@
+package store_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/internal/cache/store"
+	"github.com/golang/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ByteBudgetConsultant", func() {
+	var (
+		sm   *testhelpers.SpyMetricsRegistry
+		size int64
+	)
+
+	buildLogEnvelope := func(timestamp int64, sourceID string) *loggregator_v2.Envelope {
+		return &loggregator_v2.Envelope{
+			Timestamp: timestamp,
+			SourceId:  sourceID,
+			Message: &loggregator_v2.Envelope_Log{
+				Log: &loggregator_v2.Log{Payload: make([]byte, 100)},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		sm = testhelpers.NewMetricsRegistry()
+		size = int64(proto.Size(buildLogEnvelope(1, "a")))
+	})
+
+	It("does not prune any entries before it is given to a store", func() {
+		c := store.NewByteBudgetConsultant(1, 0)
+		Expect(c.GetQuantityToPrune(1000)).To(Equal(0))
+	})
+
+	It("prunes the store down to the budget", func() {
+		c := store.NewByteBudgetConsultant(5*size, 0)
+		s := store.NewStore(100, c, sm)
+
+		for i := int64(1); i <= 10; i++ {
+			s.Put(buildLogEnvelope(i, "a"), "a")
+		}
+
+		Eventually(func() int64 {
+			m, ok := s.Meta()["a"]
+			if !ok {
+				return 0
+			}
+			return m.Count
+		}, 5).Should(Equal(int64(5)))
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(0, 100), store.ReadOptions{Limit: 10})
+		Expect(envelopes).To(HaveLen(5))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(6)))
+
+		Eventually(func() float64 {
+			return sm.GetMetricValue("log_cache_memory_utilization", map[string]string{"unit": "percentage"})
+		}, 5).Should(Equal(100.0))
+	})
+
+	It("limits the bytes of each source ID", func() {
+		c := store.NewByteBudgetConsultant(100*size, 3*size)
+		s := store.NewStore(100, c, sm)
+
+		for i := int64(1); i <= 5; i++ {
+			s.Put(buildLogEnvelope(i, "a"), "a")
+		}
+		s.Put(buildLogEnvelope(1, "b"), "b")
+
+		meta := s.Meta()
+		Expect(meta["a"].Count).To(Equal(int64(3)))
+		Expect(meta["a"].Expired).To(Equal(int64(2)))
+		Expect(meta["a"].Bytes).To(Equal(3 * size))
+		Expect(meta["b"].Count).To(Equal(int64(1)))
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(0, 100), store.ReadOptions{Limit: 10})
+		Expect(envelopes).To(HaveLen(3))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(3)))
+		Expect(sm.GetMetricValue("log_cache_store_size", map[string]string{"unit": "entries"})).To(Equal(4.0))
+	})
+})
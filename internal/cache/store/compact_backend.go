@@ -0,0 +1,547 @@
+package store
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	compactBlockSize   = 256
+	maxInternedStrings = 16384
+)
+
+var (
+	flateWriters = sync.Pool{
+		New: func() interface{} {
+			w, _ := flate.NewWriter(nil, flate.BestSpeed)
+			return w
+		},
+	}
+	flateReaders sync.Pool
+)
+
+// compactBackend keeps the most recent envelopes as they are and seals
+// them into compressed blocks once compactBlockSize of them have
+// accumulated. Source IDs, instance IDs and tags are interned per source
+// ID, so each block only refers to them. Blocks are decoded lazily on
+// Traverse and evicted one envelope at a time, but only release their
+// memory once every envelope in them has been evicted.
+//
+// Late envelopes are inserted into the head in timestamp order, so blocks
+// may overlap in time. Traverse merges them back into timestamp and
+// sequence number order.
+type compactBackend struct {
+	head    []entry
+	blocks  []*compactBlock
+	size    int
+	strings *stringTable
+}
+
+type compactBlock struct {
+	// timestamps holds the timestamp of every encoded envelope in
+	// ascending order.
+	timestamps []int64
+
+	// seqs holds the sequence number of every encoded envelope.
+	seqs []uint64
+
+	// kinds holds the type and size of every encoded envelope, packed by
+	// packKind, so they can be accounted for when the envelope is evicted
+	// without decoding the block.
+	kinds []uint32
+
+	// removed is the number of leading envelopes that have been evicted.
+	removed int
+
+	data []byte
+}
+
+// packKind packs the type and size of an envelope into 32 bits. Sizes are
+// capped at 512MiB.
+func packKind(kind logcache_v1.EnvelopeType, size int) uint32 {
+	if size >= 1<<29 {
+		size = 1<<29 - 1
+	}
+
+	return uint32(kind)<<29 | uint32(size)
+}
+
+func unpackKind(k uint32) envelopeStats {
+	return statsOfKind(logcache_v1.EnvelopeType(k>>29), int(k&(1<<29-1)))
+}
+
+func (b *compactBlock) min() int64 { return b.timestamps[b.removed] }
+func (b *compactBlock) max() int64 { return b.timestamps[len(b.timestamps)-1] }
+
+// contains returns true if keep returns true for the timestamp of any
+// envelope that has not been evicted.
+func (b *compactBlock) contains(keep func(ts int64) bool) bool {
+	for _, ts := range b.timestamps[b.removed:] {
+		if keep(ts) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func newCompactBackend() *compactBackend {
+	return &compactBackend{
+		head:    make([]entry, 0, compactBlockSize),
+		strings: newStringTable(),
+	}
+}
+
+func (b *compactBackend) Put(e *loggregator_v2.Envelope, seq uint64) {
+	i := sort.Search(len(b.head), func(i int) bool {
+		return b.head[i].timestamp() > e.GetTimestamp()
+	})
+
+	b.head = append(b.head, entry{})
+	copy(b.head[i+1:], b.head[i:])
+	b.head[i] = entry{e: e, seq: seq}
+	b.size++
+
+	if len(b.head) >= compactBlockSize {
+		b.seal()
+	}
+}
+
+// seal encodes the head into a new block. If the head cannot be encoded,
+// its envelopes are kept as they are.
+func (b *compactBackend) seal() {
+	block, err := b.encode(b.head)
+	if err != nil {
+		return
+	}
+
+	b.blocks = append(b.blocks, block)
+	for i := range b.head {
+		b.head[i] = entry{}
+	}
+	b.head = b.head[:0]
+}
+
+func (b *compactBackend) Size() int {
+	return b.size
+}
+
+func (b *compactBackend) Oldest() (int64, bool) {
+	i := b.oldest()
+	switch {
+	case i < 0:
+		return 0, false
+	case i == len(b.blocks):
+		return b.head[0].timestamp(), true
+	default:
+		return b.blocks[i].min(), true
+	}
+}
+
+func (b *compactBackend) RemoveOldest() envelopeStats {
+	var stats envelopeStats
+
+	i := b.oldest()
+	switch {
+	case i < 0:
+		return stats
+	case i == len(b.blocks):
+		stats = statsOf(b.head[0].e)
+		copy(b.head, b.head[1:])
+		b.head[len(b.head)-1] = entry{}
+		b.head = b.head[:len(b.head)-1]
+	default:
+		block := b.blocks[i]
+		stats = unpackKind(block.kinds[block.removed])
+		block.removed++
+		if block.removed == len(block.timestamps) {
+			copy(b.blocks[i:], b.blocks[i+1:])
+			b.blocks[len(b.blocks)-1] = nil
+			b.blocks = b.blocks[:len(b.blocks)-1]
+		}
+	}
+
+	b.size--
+	return stats
+}
+
+func (b *compactBackend) RemoveBefore(cutoff int64) envelopeStats {
+	return removeOldestBefore(b, cutoff)
+}
+
+// oldest returns the index of the block that holds the oldest envelope.
+// len(b.blocks) refers to the head. It returns -1 if there are no
+// envelopes.
+func (b *compactBackend) oldest() int {
+	idx := -1
+	var oldest int64
+	for i, block := range b.blocks {
+		if idx < 0 || block.min() < oldest {
+			idx, oldest = i, block.min()
+		}
+	}
+
+	if len(b.head) > 0 && (idx < 0 || b.head[0].timestamp() < oldest) {
+		idx = len(b.blocks)
+	}
+
+	return idx
+}
+
+// compactSource is either a block or, if block is nil, the head.
+type compactSource struct {
+	min, max int64
+	block    *compactBlock
+}
+
+func (b *compactBackend) Traverse(start, end int64, descending bool, f visitor) {
+	b.traverse(start, end, nil, descending, f)
+}
+
+func (b *compactBackend) TraverseTimestamps(timestamps []int64, descending bool, f visitor) {
+	if len(timestamps) == 0 {
+		return
+	}
+
+	keep := func(ts int64) bool {
+		i := sort.Search(len(timestamps), func(i int) bool { return timestamps[i] >= ts })
+		return i < len(timestamps) && timestamps[i] == ts
+	}
+
+	b.traverse(timestamps[0], timestamps[len(timestamps)-1]+1, keep, descending, f)
+}
+
+// traverse visits the envelopes within [start..end). If keep is not nil,
+// only envelopes with a timestamp it returns true for are visited.
+func (b *compactBackend) traverse(start, end int64, keep func(ts int64) bool, descending bool, f visitor) {
+	var sources []compactSource
+	for _, block := range b.blocks {
+		if block.min() < end && block.max() >= start {
+			sources = append(sources, compactSource{min: block.min(), max: block.max(), block: block})
+		}
+	}
+
+	if len(b.head) > 0 {
+		min, max := b.head[0].timestamp(), b.head[len(b.head)-1].timestamp()
+		if min < end && max >= start {
+			sources = append(sources, compactSource{min: min, max: max})
+		}
+	}
+
+	if descending {
+		sort.SliceStable(sources, func(i, j int) bool { return sources[i].max > sources[j].max })
+	} else {
+		sort.SliceStable(sources, func(i, j int) bool { return sources[i].min < sources[j].min })
+	}
+
+	// Sources are decoded one at a time. Any pending envelope that is
+	// beyond the bounds of the next source is already in its final
+	// position and can be passed on.
+	em := &envelopeEmitter{f: f}
+	var pending []entry
+	for _, src := range sources {
+		if descending {
+			i := sort.Search(len(pending), func(i int) bool { return pending[i].timestamp() > src.max })
+			if em.emitDesc(pending[i:]) {
+				return
+			}
+			pending = pending[:i]
+		} else {
+			i := sort.Search(len(pending), func(i int) bool { return pending[i].timestamp() >= src.min })
+			if em.emitAsc(pending[:i]) {
+				return
+			}
+			pending = pending[i:]
+		}
+
+		pending = mergeEntries(pending, b.entries(src, start, end, keep))
+	}
+
+	if descending {
+		em.emitDesc(pending)
+		return
+	}
+	em.emitAsc(pending)
+}
+
+// entries returns the entries of the source within [start..end) that keep
+// returns true for.
+func (b *compactBackend) entries(src compactSource, start, end int64, keep func(ts int64) bool) []entry {
+	if src.block == nil {
+		lo := sort.Search(len(b.head), func(i int) bool { return b.head[i].timestamp() >= start })
+		hi := sort.Search(len(b.head), func(i int) bool { return b.head[i].timestamp() >= end })
+		if keep == nil {
+			return b.head[lo:hi]
+		}
+
+		var entries []entry
+		for _, en := range b.head[lo:hi] {
+			if keep(en.timestamp()) {
+				entries = append(entries, en)
+			}
+		}
+		return entries
+	}
+
+	if keep != nil && !src.block.contains(keep) {
+		return nil
+	}
+
+	entries, err := b.decode(src.block, start, end, keep)
+	if err != nil {
+		// This should never happen as blocks are only ever encoded by this
+		// backend.
+		return nil
+	}
+
+	return entries
+}
+
+func (b *compactBackend) encode(entries []entry) (*compactBlock, error) {
+	block := &compactBlock{
+		timestamps: make([]int64, 0, len(entries)),
+		seqs:       make([]uint64, 0, len(entries)),
+		kinds:      make([]uint32, 0, len(entries)),
+	}
+
+	var raw []byte
+	for _, en := range entries {
+		e := en.e
+		body, err := proto.Marshal(&loggregator_v2.Envelope{
+			DeprecatedTags: e.GetDeprecatedTags(),
+			Message:        e.GetMessage(),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		block.timestamps = append(block.timestamps, e.GetTimestamp())
+		block.seqs = append(block.seqs, en.seq)
+		block.kinds = append(block.kinds, packKind(envelopeKind(e), proto.Size(e)))
+		raw = b.strings.appendRef(raw, e.GetSourceId())
+		raw = b.strings.appendRef(raw, e.GetInstanceId())
+		raw = appendUvarint(raw, uint64(len(e.GetTags())))
+		for k, v := range e.GetTags() {
+			raw = b.strings.appendRef(raw, k)
+			raw = b.strings.appendRef(raw, v)
+		}
+		raw = appendUvarint(raw, uint64(len(body)))
+		raw = append(raw, body...)
+	}
+
+	data, err := compress(raw)
+	if err != nil {
+		return nil, err
+	}
+	block.data = data
+
+	return block, nil
+}
+
+func (b *compactBackend) decode(block *compactBlock, start, end int64, keep func(ts int64) bool) ([]entry, error) {
+	raw, err := decompress(block.data)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &blockDecoder{buf: raw, strings: b.strings}
+	var entries []entry
+	for i, ts := range block.timestamps {
+		skip := i < block.removed || ts < start || ts >= end || (keep != nil && !keep(ts))
+
+		sourceID := d.ref()
+		instanceID := d.ref()
+
+		var tags map[string]string
+		n := d.uvarint()
+		if !skip && n > 0 {
+			tags = make(map[string]string, n)
+		}
+		for j := uint64(0); j < n; j++ {
+			k, v := d.ref(), d.ref()
+			if tags != nil {
+				tags[k] = v
+			}
+		}
+
+		body := d.bytes()
+		if d.err != nil {
+			return nil, d.err
+		}
+
+		if skip {
+			continue
+		}
+
+		var e loggregator_v2.Envelope
+		if err := proto.Unmarshal(body, &e); err != nil {
+			return nil, err
+		}
+		e.Timestamp = ts
+		e.SourceId = sourceID
+		e.InstanceId = instanceID
+		e.Tags = tags
+
+		entries = append(entries, entry{e: &e, seq: block.seqs[i]})
+	}
+
+	return entries, nil
+}
+
+func compress(raw []byte) ([]byte, error) {
+	w := flateWriters.Get().(*flate.Writer)
+	defer flateWriters.Put(w)
+
+	var buf bytes.Buffer
+	w.Reset(&buf)
+	if _, err := w.Write(raw); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	// Copy the data so the block does not hold on to the spare capacity of
+	// the buffer.
+	return append([]byte(nil), buf.Bytes()...), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	var r io.ReadCloser
+	if v := flateReaders.Get(); v != nil {
+		r = v.(io.ReadCloser)
+		if err := r.(flate.Resetter).Reset(bytes.NewReader(data), nil); err != nil {
+			return nil, err
+		}
+	} else {
+		r = flate.NewReader(bytes.NewReader(data))
+	}
+	defer flateReaders.Put(r)
+
+	return ioutil.ReadAll(r)
+}
+
+// blockDecoder reads the fields of encoded envelopes. Once an error has
+// occurred every read returns a zero value and the error is kept in err.
+type blockDecoder struct {
+	buf     []byte
+	strings *stringTable
+	err     error
+}
+
+func (d *blockDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = fmt.Errorf("invalid varint in block")
+		return 0
+	}
+	d.buf = d.buf[n:]
+
+	return v
+}
+
+func (d *blockDecoder) bytes() []byte {
+	n := d.uvarint()
+	if d.err != nil {
+		return nil
+	}
+
+	if uint64(len(d.buf)) < n {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+
+	return b
+}
+
+func (d *blockDecoder) ref() string {
+	id := d.uvarint()
+	if id == 0 {
+		return string(d.bytes())
+	}
+
+	s, ok := d.strings.lookup(id)
+	if !ok && d.err == nil {
+		d.err = fmt.Errorf("unknown string reference %d", id)
+	}
+
+	return s
+}
+
+// stringTable interns strings so that encoded envelopes only have to refer
+// to them. Once the table is full, strings are encoded as they are.
+type stringTable struct {
+	ids     map[string]uint64
+	strings []string
+}
+
+func newStringTable() *stringTable {
+	return &stringTable{
+		ids: make(map[string]uint64),
+	}
+}
+
+// appendRef appends a reference to the string. References start at 1, a
+// reference of 0 is followed by the length prefixed string itself.
+func (t *stringTable) appendRef(buf []byte, s string) []byte {
+	id, ok := t.ids[s]
+	if !ok && len(t.strings) < maxInternedStrings {
+		t.strings = append(t.strings, s)
+		id = uint64(len(t.strings))
+		t.ids[s] = id
+		ok = true
+	}
+
+	if ok {
+		return appendUvarint(buf, id)
+	}
+
+	buf = appendUvarint(buf, 0)
+	buf = appendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func (t *stringTable) lookup(id uint64) (string, bool) {
+	if id == 0 || id > uint64(len(t.strings)) {
+		return "", false
+	}
+
+	return t.strings[id-1], true
+}
+
+// mergeEntries merges two slices that are ordered by timestamp and
+// sequence number.
+func mergeEntries(a, b []entry) []entry {
+	merged := make([]entry, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		if b[0].before(a[0]) {
+			merged = append(merged, b[0])
+			b = b[1:]
+			continue
+		}
+
+		merged = append(merged, a[0])
+		a = a[1:]
+	}
+
+	merged = append(merged, a...)
+	return append(merged, b...)
+}
@@ -0,0 +1,110 @@
+package store_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/internal/cache/store"
+	"github.com/golang/protobuf/proto"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Compact storage engine", func() {
+	var (
+		s  *store.Store
+		sm *testhelpers.SpyMetricsRegistry
+		sp *spyPruner
+	)
+
+	BeforeEach(func() {
+		sp = newSpyPruner()
+		sm = testhelpers.NewMetricsRegistry()
+		s = store.NewStore(5000, sp, sm, store.WithStorageEngine(store.CompactEngine))
+	})
+
+	It("returns envelopes across sealed blocks in order", func() {
+		for i := int64(0); i < 1000; i++ {
+			s.Put(buildEnvelope(i, "a"), "a")
+		}
+
+		envelopes := s.Get("a", time.Unix(0, 100), time.Unix(0, 900), store.ReadOptions{Limit: 1000})
+		Expect(envelopes).To(HaveLen(800))
+		for i, e := range envelopes {
+			Expect(e.Timestamp).To(Equal(int64(100 + i)))
+		}
+
+		envelopes = s.Get("a", time.Unix(0, 0), time.Unix(0, 1000), store.ReadOptions{Limit: 300, Descending: true})
+		Expect(envelopes).To(HaveLen(300))
+		for i, e := range envelopes {
+			Expect(e.Timestamp).To(Equal(int64(999 - i)))
+		}
+	})
+
+	It("merges late envelopes into the timestamp order", func() {
+		for i := int64(0); i < 600; i++ {
+			s.Put(buildEnvelope(i*2+1000, "a"), "a")
+		}
+
+		for i := int64(0); i < 600; i++ {
+			s.Put(buildEnvelope(i*2+1001, "a"), "a")
+		}
+		s.Put(buildEnvelope(1, "a"), "a")
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(0, 3000), store.ReadOptions{Limit: 2000})
+		Expect(envelopes).To(HaveLen(1201))
+		Expect(envelopes[0].Timestamp).To(Equal(int64(1)))
+		for i, e := range envelopes[1:] {
+			Expect(e.Timestamp).To(Equal(int64(1000 + i)))
+		}
+
+		envelopes = s.Get("a", time.Unix(0, 0), time.Unix(0, 3000), store.ReadOptions{Limit: 2000, Descending: true})
+		Expect(envelopes).To(HaveLen(1201))
+		Expect(envelopes[1200].Timestamp).To(Equal(int64(1)))
+	})
+
+	It("does not split envelopes with the same timestamp at the limit", func() {
+		for i := int64(0); i < 300; i++ {
+			s.Put(buildEnvelope(i/3, "a"), "a")
+		}
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(0, 100), store.ReadOptions{Limit: 4})
+		Expect(envelopes).To(HaveLen(6))
+		Expect(envelopes[5].Timestamp).To(Equal(int64(1)))
+	})
+
+	It("evicts the oldest envelopes across blocks", func() {
+		s = store.NewStore(300, sp, sm, store.WithStorageEngine(store.CompactEngine))
+
+		for i := int64(0); i < 1000; i++ {
+			s.Put(buildEnvelope(i, "a"), "a")
+		}
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(0, 1000), store.ReadOptions{Limit: 1000})
+		Expect(envelopes).To(HaveLen(300))
+		Expect(envelopes[0].Timestamp).To(Equal(int64(700)))
+		Expect(s.Meta()["a"].OldestTimestamp).To(Equal(int64(700)))
+	})
+
+	It("keeps every field of the envelopes", func() {
+		var expected []*loggregator_v2.Envelope
+		for i := int64(0); i < 300; i++ {
+			e := buildLogEnvelope(i, "a", "some-payload")
+			e.InstanceId = "3"
+			e.Tags = map[string]string{"deployment": "cf", "job": "diego-cell"}
+			e.DeprecatedTags = map[string]*loggregator_v2.Value{
+				"ip": {Data: &loggregator_v2.Value_Text{Text: "10.0.0.1"}},
+			}
+			expected = append(expected, e)
+			s.Put(e, "a")
+		}
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(0, 300), store.ReadOptions{Limit: 300})
+		Expect(envelopes).To(HaveLen(len(expected)))
+		for i, e := range envelopes {
+			Expect(proto.Equal(e, expected[i])).To(BeTrue())
+		}
+	})
+})
@@ -0,0 +1,100 @@
+package store
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/proto"
+)
+
+// deduplicator remembers the content hashes of the envelopes of a single
+// source ID whose timestamps are within a window of the newest timestamp it
+// has seen. Envelopes that are older than the window are never considered
+// duplicates.
+type deduplicator struct {
+	window int64
+	newest int64
+	seen   map[uint64]struct{}
+
+	// order holds the hashes in the order they were seen so they can be
+	// forgotten once they fall out of the window.
+	order []dedupEntry
+}
+
+type dedupEntry struct {
+	timestamp int64
+	hash      uint64
+}
+
+func newDeduplicator(window int64) *deduplicator {
+	return &deduplicator{
+		window: window,
+		seen:   make(map[uint64]struct{}),
+	}
+}
+
+// isDuplicate returns true if an envelope with the same content has been
+// seen within the window. Otherwise the envelope is remembered.
+func (d *deduplicator) isDuplicate(e *loggregator_v2.Envelope) bool {
+	h, ok := envelopeHash(e)
+	if !ok {
+		return false
+	}
+
+	if _, ok := d.seen[h]; ok {
+		return true
+	}
+
+	ts := e.GetTimestamp()
+	if len(d.order) > 0 && ts < d.newest-d.window {
+		return false
+	}
+
+	d.seen[h] = struct{}{}
+	d.order = append(d.order, dedupEntry{timestamp: ts, hash: h})
+
+	if len(d.order) == 1 || ts > d.newest {
+		d.newest = ts
+		d.forget()
+	}
+
+	return false
+}
+
+// forget drops the hashes that are older than the window. Hashes of late
+// envelopes are dropped once every hash that was seen before them is.
+func (d *deduplicator) forget() {
+	cutoff := d.newest - d.window
+
+	var i int
+	for i < len(d.order) && d.order[i].timestamp < cutoff {
+		delete(d.seen, d.order[i].hash)
+		i++
+	}
+
+	d.order = d.order[i:]
+}
+
+// envelopeHash returns a hash of the source ID, timestamp, instance ID and
+// message of the envelope. Tags are not part of the hash.
+func envelopeHash(e *loggregator_v2.Envelope) (uint64, bool) {
+	buf := proto.NewBuffer(nil)
+	buf.SetDeterministic(true)
+	if err := buf.Marshal(&loggregator_v2.Envelope{Message: e.Message}); err != nil {
+		return 0, false
+	}
+
+	var ts [8]byte
+	binary.LittleEndian.PutUint64(ts[:], uint64(e.GetTimestamp()))
+
+	h := fnv.New64a()
+	h.Write([]byte(e.GetSourceId()))
+	h.Write([]byte{0})
+	h.Write(ts[:])
+	h.Write([]byte(e.GetInstanceId()))
+	h.Write([]byte{0})
+	h.Write(buf.Bytes())
+
+	return h.Sum64(), true
+}
@@ -0,0 +1,167 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/go-loggregator/metrics"
+)
+
+// DefaultEvictionClass is the name of the eviction class of every source ID
+// that no class of the EvictionPolicy applies to.
+const DefaultEvictionClass = "default"
+
+// EvictionPolicy groups source IDs into weighted eviction classes. When the
+// store prunes envelopes due to memory pressure, each class is entitled to
+// a share of the store that is proportional to its weight. Envelopes are
+// pruned from the classes that exceed their share the most, oldest first
+// within a class. A chatty source therefore mostly evicts its own history
+// instead of the history of every other source.
+type EvictionPolicy struct {
+	defaultWeight float64
+	classes       []EvictionClass
+}
+
+// EvictionClass assigns either a single source ID or every source ID that
+// matches a pattern to a named class with the given weight. Classes with the
+// same name form a single class that has the weight of the first of them.
+type EvictionClass struct {
+	Name     string
+	SourceID string
+	Pattern  *regexp.Regexp
+	Weight   float64
+}
+
+// NewEvictionPolicy returns a new EvictionPolicy. Source IDs that no class
+// applies to belong to the DefaultEvictionClass with the given default
+// weight. Classes that target a source ID directly take precedence over
+// patterns. Otherwise the first matching pattern wins.
+func NewEvictionPolicy(defaultWeight float64, classes ...EvictionClass) *EvictionPolicy {
+	return &EvictionPolicy{
+		defaultWeight: defaultWeight,
+		classes:       classes,
+	}
+}
+
+// ParseEvictionClass parses a class of the form <name>:<source-id>=<weight>
+// or <name>:/<pattern>/=<weight>, e.g. system:doppler=4 or
+// apps:/^app-.*$/=1.
+func ParseEvictionClass(class string) (EvictionClass, error) {
+	i := strings.LastIndex(class, "=")
+	j := strings.Index(class, ":")
+	if i < 0 || j < 0 || j > i {
+		return EvictionClass{}, fmt.Errorf("eviction class %q must be of the form <name>:<source-id>=<weight>", class)
+	}
+
+	name, target, rawWeight := class[:j], class[j+1:i], class[i+1:]
+	if name == "" {
+		return EvictionClass{}, fmt.Errorf("eviction class %q is missing a name", class)
+	}
+
+	if name == DefaultEvictionClass {
+		return EvictionClass{}, fmt.Errorf("eviction class %q uses the reserved name %q", class, DefaultEvictionClass)
+	}
+
+	weight, err := strconv.ParseFloat(rawWeight, 64)
+	if err != nil || weight <= 0 {
+		return EvictionClass{}, fmt.Errorf("eviction class %q must have a positive weight", class)
+	}
+
+	if len(target) > 1 && strings.HasPrefix(target, "/") && strings.HasSuffix(target, "/") {
+		pattern, err := regexp.Compile(target[1 : len(target)-1])
+		if err != nil {
+			return EvictionClass{}, fmt.Errorf("eviction class %q has an invalid pattern: %s", class, err)
+		}
+
+		return EvictionClass{Name: name, Pattern: pattern, Weight: weight}, nil
+	}
+
+	if target == "" {
+		return EvictionClass{}, fmt.Errorf("eviction class %q is missing a source ID", class)
+	}
+
+	return EvictionClass{Name: name, SourceID: target, Weight: weight}, nil
+}
+
+// Class returns the name of the eviction class of the given source ID.
+func (p *EvictionPolicy) Class(sourceID string) string {
+	if p == nil {
+		return DefaultEvictionClass
+	}
+
+	for _, c := range p.classes {
+		if c.Pattern == nil && c.SourceID == sourceID {
+			return c.Name
+		}
+	}
+
+	for _, c := range p.classes {
+		if c.Pattern != nil && c.Pattern.MatchString(sourceID) {
+			return c.Name
+		}
+	}
+
+	return DefaultEvictionClass
+}
+
+// weights returns the weight of every class by name, including the
+// DefaultEvictionClass.
+func (p *EvictionPolicy) weights() map[string]float64 {
+	weights := map[string]float64{DefaultEvictionClass: 1}
+	if p == nil {
+		return weights
+	}
+
+	weights[DefaultEvictionClass] = p.defaultWeight
+	for _, c := range p.classes {
+		if _, ok := weights[c.Name]; !ok {
+			weights[c.Name] = c.Weight
+		}
+	}
+
+	return weights
+}
+
+// evictionClass is the state the store keeps for each class of its
+// EvictionPolicy.
+type evictionClass struct {
+	name       string
+	weight     float64
+	incExpired metrics.Counter
+}
+
+// classExpiration holds the trees of an eviction class ordered by their
+// oldest envelope along with the number of envelopes in the class.
+type classExpiration struct {
+	class *evictionClass
+	count int64
+	heap  ExpirationHeap
+}
+
+// mostOverQuota returns the class that exceeds its weighted share of the
+// store the most. Ties are broken by the age of the oldest envelope. It
+// returns nil if every class is empty.
+func mostOverQuota(classes []*classExpiration) *classExpiration {
+	var most *classExpiration
+	for _, c := range classes {
+		if c.heap.Len() == 0 {
+			continue
+		}
+
+		if most == nil {
+			most = c
+			continue
+		}
+
+		// Compare count/weight without dividing by the weights.
+		lhs := float64(c.count) * most.class.weight
+		rhs := float64(most.count) * c.class.weight
+		if lhs > rhs || (lhs == rhs && c.heap[0].timestamp < most.heap[0].timestamp) {
+			most = c
+		}
+	}
+
+	return most
+}
@@ -0,0 +1,78 @@
+package store_test
+
+import (
+	"regexp"
+
+	"code.cloudfoundry.org/log-cache/internal/cache/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EvictionPolicy", func() {
+	It("uses the default class when no class applies", func() {
+		p := store.NewEvictionPolicy(1)
+		Expect(p.Class("some-id")).To(Equal(store.DefaultEvictionClass))
+	})
+
+	It("prefers source ID classes over patterns", func() {
+		p := store.NewEvictionPolicy(1,
+			store.EvictionClass{Name: "system", Pattern: regexp.MustCompile("^doppler"), Weight: 4},
+			store.EvictionClass{Name: "important", SourceID: "doppler-1", Weight: 8},
+		)
+
+		Expect(p.Class("doppler-1")).To(Equal("important"))
+		Expect(p.Class("doppler-2")).To(Equal("system"))
+		Expect(p.Class("app")).To(Equal(store.DefaultEvictionClass))
+	})
+
+	It("uses the first matching pattern", func() {
+		p := store.NewEvictionPolicy(1,
+			store.EvictionClass{Name: "apps", Pattern: regexp.MustCompile("^app-"), Weight: 1},
+			store.EvictionClass{Name: "other", Pattern: regexp.MustCompile("app"), Weight: 2},
+		)
+
+		Expect(p.Class("app-1")).To(Equal("apps"))
+		Expect(p.Class("my-app")).To(Equal("other"))
+	})
+
+	It("puts everything in the default class without a policy", func() {
+		var p *store.EvictionPolicy
+		Expect(p.Class("some-id")).To(Equal(store.DefaultEvictionClass))
+	})
+
+	Describe("ParseEvictionClass", func() {
+		It("parses a source ID class", func() {
+			c, err := store.ParseEvictionClass("system:doppler=4")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Name).To(Equal("system"))
+			Expect(c.SourceID).To(Equal("doppler"))
+			Expect(c.Pattern).To(BeNil())
+			Expect(c.Weight).To(Equal(4.0))
+		})
+
+		It("parses a pattern class", func() {
+			c, err := store.ParseEvictionClass("apps:/^app=.*$/=0.5")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(c.Name).To(Equal("apps"))
+			Expect(c.SourceID).To(BeEmpty())
+			Expect(c.Pattern.String()).To(Equal("^app=.*$"))
+			Expect(c.Weight).To(Equal(0.5))
+		})
+
+		DescribeTable("invalid classes", func(class string) {
+			_, err := store.ParseEvictionClass(class)
+			Expect(err).To(HaveOccurred())
+		},
+			Entry("missing name", "doppler=4"),
+			Entry("empty name", ":doppler=4"),
+			Entry("reserved name", "default:doppler=4"),
+			Entry("missing weight", "system:doppler"),
+			Entry("invalid weight", "system:doppler=heavy"),
+			Entry("non-positive weight", "system:doppler=0"),
+			Entry("missing source ID", "system:=4"),
+			Entry("invalid pattern", "system:/(/=4"),
+		)
+	})
+})
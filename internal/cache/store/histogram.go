@@ -0,0 +1,111 @@
+package store
+
+import (
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+)
+
+// Histogram counts the envelopes within [start..end) in buckets of the given
+// step, split by envelope type and the given grouping. Buckets start at
+// multiples of the step and buckets without envelopes are left out. The
+// envelopes are visited in a single traversal.
+func (store *Store) Histogram(
+	index string,
+	start time.Time,
+	end time.Time,
+	step time.Duration,
+	groupBy logcache_v1.ReadHistogramRequest_GroupBy,
+) []*logcache_v1.ReadHistogramResponse_Bucket {
+	tree, ok := store.storageIndex.Load(index)
+	if !ok {
+		return nil
+	}
+
+	tree.(*storage).RLock()
+	defer tree.(*storage).RUnlock()
+
+	var (
+		buckets []*logcache_v1.ReadHistogramResponse_Bucket
+		b       *histogramBucket
+	)
+	visit := func(e *loggregator_v2.Envelope, _ uint64) bool {
+		bucketStart := alignToStep(e.GetTimestamp(), int64(step))
+		if b != nil && b.start != bucketStart {
+			buckets = append(buckets, b.bucket())
+			b = nil
+		}
+
+		if b == nil {
+			b = &histogramBucket{
+				start:  bucketStart,
+				counts: make(map[histogramKey]int64),
+			}
+		}
+		b.counts[histogramKey{
+			envelopeType: envelopeKind(e),
+			group:        histogramGroup(e, groupBy),
+		}]++
+
+		return false
+	}
+
+	tree.(*storage).Traverse(start.UnixNano(), end.UnixNano(), false, visit)
+
+	if b != nil {
+		buckets = append(buckets, b.bucket())
+	}
+
+	return buckets
+}
+
+// histogramGroup returns the group the envelope is counted in.
+func histogramGroup(e *loggregator_v2.Envelope, groupBy logcache_v1.ReadHistogramRequest_GroupBy) string {
+	switch groupBy {
+	case logcache_v1.ReadHistogramRequest_LOG_TYPE:
+		if l, ok := e.Message.(*loggregator_v2.Envelope_Log); ok {
+			return l.Log.GetType().String()
+		}
+	case logcache_v1.ReadHistogramRequest_INSTANCE_ID:
+		return e.GetInstanceId()
+	}
+
+	return ""
+}
+
+type histogramKey struct {
+	envelopeType logcache_v1.EnvelopeType
+	group        string
+}
+
+// histogramBucket counts the envelopes of a single step.
+type histogramBucket struct {
+	start  int64
+	counts map[histogramKey]int64
+}
+
+// bucket returns the counts ordered by envelope type and group.
+func (b *histogramBucket) bucket() *logcache_v1.ReadHistogramResponse_Bucket {
+	counts := make([]*logcache_v1.ReadHistogramResponse_Count, 0, len(b.counts))
+	for k, n := range b.counts {
+		counts = append(counts, &logcache_v1.ReadHistogramResponse_Count{
+			EnvelopeType: k.envelopeType,
+			Group:        k.group,
+			Count:        n,
+		})
+	}
+
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].EnvelopeType != counts[j].EnvelopeType {
+			return counts[i].EnvelopeType < counts[j].EnvelopeType
+		}
+		return counts[i].Group < counts[j].Group
+	})
+
+	return &logcache_v1.ReadHistogramResponse_Bucket{
+		Timestamp: b.start,
+		Counts:    counts,
+	}
+}
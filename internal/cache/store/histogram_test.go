@@ -0,0 +1,109 @@
+package store_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/internal/cache/store"
+	rpc "code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Histogram", func() {
+	for _, engine := range []store.StorageEngine{store.AVLTreeEngine, store.CompactEngine, store.SegmentEngine} {
+		engine := engine
+		Context(engine.String(), func() { describeHistogram(engine) })
+	}
+})
+
+func describeHistogram(engine store.StorageEngine) {
+	var (
+		s *store.Store
+	)
+
+	buildLog := func(timestamp int64, instanceID string, t loggregator_v2.Log_Type) *loggregator_v2.Envelope {
+		return &loggregator_v2.Envelope{
+			Timestamp:  timestamp,
+			SourceId:   "a",
+			InstanceId: instanceID,
+			Message: &loggregator_v2.Envelope_Log{
+				Log: &loggregator_v2.Log{Payload: []byte("some-payload"), Type: t},
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		s = store.NewStore(100, newSpyPruner(), testhelpers.NewMetricsRegistry(), store.WithStorageEngine(engine))
+
+		s.Put(buildLog(1, "0", loggregator_v2.Log_OUT), "a")
+		s.Put(buildLog(2, "1", loggregator_v2.Log_ERR), "a")
+		s.Put(buildLog(3, "0", loggregator_v2.Log_OUT), "a")
+		s.Put(&loggregator_v2.Envelope{
+			Timestamp:  4,
+			SourceId:   "a",
+			InstanceId: "1",
+			Message: &loggregator_v2.Envelope_Counter{
+				Counter: &loggregator_v2.Counter{Name: "requests", Total: 1},
+			},
+		}, "a")
+		s.Put(buildLog(25, "1", loggregator_v2.Log_OUT), "a")
+	})
+
+	It("counts the envelopes of each step by envelope type", func() {
+		buckets := s.Histogram("a", time.Unix(0, 0), time.Unix(0, 100), 10, rpc.ReadHistogramRequest_NONE)
+		Expect(buckets).To(Equal([]*rpc.ReadHistogramResponse_Bucket{
+			{
+				Timestamp: 0,
+				Counts: []*rpc.ReadHistogramResponse_Count{
+					{EnvelopeType: rpc.EnvelopeType_LOG, Count: 3},
+					{EnvelopeType: rpc.EnvelopeType_COUNTER, Count: 1},
+				},
+			},
+			{
+				Timestamp: 20,
+				Counts: []*rpc.ReadHistogramResponse_Count{
+					{EnvelopeType: rpc.EnvelopeType_LOG, Count: 1},
+				},
+			},
+		}))
+	})
+
+	It("splits logs by log type", func() {
+		buckets := s.Histogram("a", time.Unix(0, 0), time.Unix(0, 10), 10, rpc.ReadHistogramRequest_LOG_TYPE)
+		Expect(buckets).To(HaveLen(1))
+		Expect(buckets[0].Counts).To(Equal([]*rpc.ReadHistogramResponse_Count{
+			{EnvelopeType: rpc.EnvelopeType_LOG, Group: "ERR", Count: 1},
+			{EnvelopeType: rpc.EnvelopeType_LOG, Group: "OUT", Count: 2},
+			{EnvelopeType: rpc.EnvelopeType_COUNTER, Count: 1},
+		}))
+	})
+
+	It("splits envelopes by instance ID", func() {
+		buckets := s.Histogram("a", time.Unix(0, 0), time.Unix(0, 10), 10, rpc.ReadHistogramRequest_INSTANCE_ID)
+		Expect(buckets).To(HaveLen(1))
+		Expect(buckets[0].Counts).To(Equal([]*rpc.ReadHistogramResponse_Count{
+			{EnvelopeType: rpc.EnvelopeType_LOG, Group: "0", Count: 2},
+			{EnvelopeType: rpc.EnvelopeType_LOG, Group: "1", Count: 1},
+			{EnvelopeType: rpc.EnvelopeType_COUNTER, Group: "1", Count: 1},
+		}))
+	})
+
+	It("only counts envelopes within the time range", func() {
+		buckets := s.Histogram("a", time.Unix(0, 2), time.Unix(0, 4), 10, rpc.ReadHistogramRequest_NONE)
+		Expect(buckets).To(Equal([]*rpc.ReadHistogramResponse_Bucket{
+			{
+				Timestamp: 0,
+				Counts: []*rpc.ReadHistogramResponse_Count{
+					{EnvelopeType: rpc.EnvelopeType_LOG, Count: 2},
+				},
+			},
+		}))
+	})
+
+	It("returns nothing for an unknown source ID", func() {
+		Expect(s.Histogram("b", time.Unix(0, 0), time.Unix(0, 100), 10, rpc.ReadHistogramRequest_NONE)).To(BeEmpty())
+	})
+}
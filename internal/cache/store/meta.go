@@ -0,0 +1,177 @@
+package store
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	"github.com/golang/protobuf/proto"
+)
+
+// envelopeStats counts envelopes by type along with the approximate number
+// of bytes they occupy.
+type envelopeStats struct {
+	envelopes int64
+
+	logs     int64
+	counters int64
+	gauges   int64
+	timers   int64
+	events   int64
+	bytes    int64
+}
+
+// statsOf returns the stats of a single envelope.
+func statsOf(e *loggregator_v2.Envelope) envelopeStats {
+	return statsOfKind(envelopeKind(e), proto.Size(e))
+}
+
+// statsOfKind returns the stats of a single envelope of the given type and
+// size.
+func statsOfKind(kind logcache_v1.EnvelopeType, size int) envelopeStats {
+	s := envelopeStats{envelopes: 1, bytes: int64(size)}
+	switch kind {
+	case logcache_v1.EnvelopeType_LOG:
+		s.logs = 1
+	case logcache_v1.EnvelopeType_COUNTER:
+		s.counters = 1
+	case logcache_v1.EnvelopeType_GAUGE:
+		s.gauges = 1
+	case logcache_v1.EnvelopeType_TIMER:
+		s.timers = 1
+	case logcache_v1.EnvelopeType_EVENT:
+		s.events = 1
+	}
+
+	return s
+}
+
+// count returns the number of envelopes of the given type.
+func (s envelopeStats) count(t logcache_v1.EnvelopeType) int64 {
+	switch t {
+	case logcache_v1.EnvelopeType_LOG:
+		return s.logs
+	case logcache_v1.EnvelopeType_COUNTER:
+		return s.counters
+	case logcache_v1.EnvelopeType_GAUGE:
+		return s.gauges
+	case logcache_v1.EnvelopeType_TIMER:
+		return s.timers
+	case logcache_v1.EnvelopeType_EVENT:
+		return s.events
+	default:
+		return s.envelopes
+	}
+}
+
+// minority returns true if the envelopes of the given types make up less
+// than half of the envelopes.
+func (s envelopeStats) minority(types []logcache_v1.EnvelopeType) bool {
+	var n int64
+	for _, t := range types {
+		n += s.count(t)
+	}
+
+	return 2*n < s.envelopes
+}
+
+// envelopeKind returns the EnvelopeType of the envelope. Envelopes without
+// a message are of type ANY.
+func envelopeKind(e *loggregator_v2.Envelope) logcache_v1.EnvelopeType {
+	switch e.Message.(type) {
+	case *loggregator_v2.Envelope_Log:
+		return logcache_v1.EnvelopeType_LOG
+	case *loggregator_v2.Envelope_Counter:
+		return logcache_v1.EnvelopeType_COUNTER
+	case *loggregator_v2.Envelope_Gauge:
+		return logcache_v1.EnvelopeType_GAUGE
+	case *loggregator_v2.Envelope_Timer:
+		return logcache_v1.EnvelopeType_TIMER
+	case *loggregator_v2.Envelope_Event:
+		return logcache_v1.EnvelopeType_EVENT
+	default:
+		return logcache_v1.EnvelopeType_ANY
+	}
+}
+
+func (s *envelopeStats) add(o envelopeStats) {
+	s.envelopes += o.envelopes
+	s.logs += o.logs
+	s.counters += o.counters
+	s.gauges += o.gauges
+	s.timers += o.timers
+	s.events += o.events
+	s.bytes += o.bytes
+}
+
+func (s *envelopeStats) sub(o envelopeStats) {
+	s.envelopes -= o.envelopes
+	s.logs -= o.logs
+	s.counters -= o.counters
+	s.gauges -= o.gauges
+	s.timers -= o.timers
+	s.events -= o.events
+	s.bytes -= o.bytes
+}
+
+// apply copies the stats into the MetaInfo.
+func (s envelopeStats) apply(m *logcache_v1.MetaInfo) {
+	m.LogCount = s.logs
+	m.CounterCount = s.counters
+	m.GaugeCount = s.gauges
+	m.TimerCount = s.timers
+	m.EventCount = s.events
+	m.Bytes = s.bytes
+}
+
+// rateWindow is the number of seconds an ingest rate is averaged over.
+const rateWindow = 60
+
+// rateCounter counts the envelopes written in each of the last rateWindow
+// seconds.
+type rateCounter struct {
+	buckets [rateWindow]int64
+
+	// last is the second, since the epoch, of the most recent write.
+	last int64
+}
+
+// add records a write at the given time.
+func (r *rateCounter) add(now time.Time) {
+	sec := now.Unix()
+	if sec <= r.last-rateWindow {
+		return
+	}
+
+	r.advance(sec)
+	r.buckets[sec%rateWindow]++
+}
+
+// rate returns the average number of writes per second over the last
+// rateWindow seconds.
+func (r *rateCounter) rate(now time.Time) float64 {
+	sec := now.Unix()
+
+	var total int64
+	for i := sec - rateWindow + 1; i <= sec; i++ {
+		if i > r.last || i <= r.last-rateWindow {
+			continue
+		}
+		total += r.buckets[i%rateWindow]
+	}
+
+	return float64(total) / rateWindow
+}
+
+// advance clears the buckets of the seconds between the most recent write
+// and the given second.
+func (r *rateCounter) advance(sec int64) {
+	if sec <= r.last {
+		return
+	}
+
+	for i := r.last + 1; i <= sec && i <= r.last+rateWindow; i++ {
+		r.buckets[i%rateWindow] = 0
+	}
+	r.last = sec
+}
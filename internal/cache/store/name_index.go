@@ -0,0 +1,199 @@
+package store
+
+import (
+	"math"
+	"regexp"
+	"sort"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// nameIndex maps the metric names of counter, gauge and timer envelopes to
+// the timestamps they were seen at. It allows reads with a name filter to
+// only visit the envelopes that can match instead of every envelope of a
+// source ID.
+//
+// The index is only ever a superset of the stored envelopes. Timestamps of
+// envelopes that have been removed are pruned lazily, so any envelope the
+// index leads to still has to be filtered.
+type nameIndex struct {
+	timestamps map[string][]int64
+}
+
+func newNameIndex() *nameIndex {
+	return &nameIndex{
+		timestamps: make(map[string][]int64),
+	}
+}
+
+// add records the metric names of the envelope.
+func (idx *nameIndex) add(e *loggregator_v2.Envelope) {
+	switch m := e.Message.(type) {
+	case *loggregator_v2.Envelope_Counter:
+		idx.addName(m.Counter.GetName(), e.GetTimestamp())
+	case *loggregator_v2.Envelope_Timer:
+		idx.addName(m.Timer.GetName(), e.GetTimestamp())
+	case *loggregator_v2.Envelope_Gauge:
+		for name := range m.Gauge.GetMetrics() {
+			idx.addName(name, e.GetTimestamp())
+		}
+	}
+}
+
+func (idx *nameIndex) addName(name string, ts int64) {
+	idx.timestamps[name] = insertTimestamp(idx.timestamps[name], ts)
+}
+
+// prune drops every timestamp that is older than oldest.
+func (idx *nameIndex) prune(oldest int64) {
+	for name, timestamps := range idx.timestamps {
+		timestamps = pruneTimestamps(timestamps, oldest)
+		if len(timestamps) == 0 {
+			delete(idx.timestamps, name)
+			continue
+		}
+
+		idx.timestamps[name] = timestamps
+	}
+}
+
+// lookupName returns the timestamps within [start..end) of the given name
+// in ascending order.
+func (idx *nameIndex) lookupName(name string, start, end int64) []int64 {
+	return timestampsWithin(idx.timestamps[name], start, end)
+}
+
+// lookup returns the timestamps within [start..end) of every name that
+// matches the filter. Each list is in ascending order and may share
+// timestamps with the others.
+func (idx *nameIndex) lookup(nameFilter *regexp.Regexp, start, end int64) [][]int64 {
+	var lists [][]int64
+	for name, timestamps := range idx.timestamps {
+		if !nameFilter.MatchString(name) {
+			continue
+		}
+
+		if timestamps = timestampsWithin(timestamps, start, end); len(timestamps) > 0 {
+			lists = append(lists, timestamps)
+		}
+	}
+
+	return lists
+}
+
+// insertTimestamp adds ts to the ascending timestamps unless it is already
+// present.
+func insertTimestamp(timestamps []int64, ts int64) []int64 {
+	n := len(timestamps)
+
+	// Envelopes mostly arrive in order, so appending is the common case.
+	if n == 0 || timestamps[n-1] < ts {
+		return append(timestamps, ts)
+	}
+
+	i := sort.Search(n, func(i int) bool { return timestamps[i] >= ts })
+	if timestamps[i] == ts {
+		return timestamps
+	}
+
+	timestamps = append(timestamps, 0)
+	copy(timestamps[i+1:], timestamps[i:])
+	timestamps[i] = ts
+	return timestamps
+}
+
+// pruneTimestamps drops every timestamp that is older than oldest.
+func pruneTimestamps(timestamps []int64, oldest int64) []int64 {
+	i := sort.Search(len(timestamps), func(i int) bool { return timestamps[i] >= oldest })
+	if i == 0 {
+		return timestamps
+	}
+
+	if i == len(timestamps) {
+		return nil
+	}
+
+	// Copy the remaining timestamps so the pruned ones can be collected.
+	return append([]int64(nil), timestamps[i:]...)
+}
+
+// timestampsWithin returns the timestamps within [start..end).
+func timestampsWithin(timestamps []int64, start, end int64) []int64 {
+	lo := sort.Search(len(timestamps), func(i int) bool { return timestamps[i] >= start })
+	hi := sort.Search(len(timestamps), func(i int) bool { return timestamps[i] >= end })
+
+	return timestamps[lo:hi]
+}
+
+// mergeTimestamps returns up to n of the distinct timestamps of the
+// ascending lists in ascending order. These are the oldest timestamps, or
+// the newest if descending is set. A single list is not copied.
+func mergeTimestamps(lists [][]int64, n int, descending bool) []int64 {
+	if len(lists) == 1 {
+		timestamps := lists[0]
+		if len(timestamps) <= n {
+			return timestamps
+		}
+
+		if descending {
+			return timestamps[len(timestamps)-n:]
+		}
+		return timestamps[:n]
+	}
+
+	// Each list only has to contribute its first (or last) n timestamps.
+	var result []int64
+	for _, timestamps := range lists {
+		if len(timestamps) > n {
+			if descending {
+				timestamps = timestamps[len(timestamps)-n:]
+			} else {
+				timestamps = timestamps[:n]
+			}
+		}
+
+		result = append(result, timestamps...)
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+
+	distinct := result[:1]
+	for _, ts := range result[1:] {
+		if ts != distinct[len(distinct)-1] {
+			distinct = append(distinct, ts)
+		}
+	}
+
+	if len(distinct) <= n {
+		return distinct
+	}
+
+	if descending {
+		return distinct[len(distinct)-n:]
+	}
+	return distinct[:n]
+}
+
+// dropTimestamps returns the lists without the timestamps up to and
+// including last, or from last on if descending is set. Lists that become
+// empty are dropped.
+func dropTimestamps(lists [][]int64, last int64, descending bool) [][]int64 {
+	remaining := lists[:0]
+	for _, timestamps := range lists {
+		if descending {
+			timestamps = timestampsWithin(timestamps, math.MinInt64, last)
+		} else {
+			timestamps = timestampsWithin(timestamps, last+1, math.MaxInt64)
+		}
+
+		if len(timestamps) > 0 {
+			remaining = append(remaining, timestamps)
+		}
+	}
+
+	return remaining
+}
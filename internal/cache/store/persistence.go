@@ -0,0 +1,464 @@
+package store
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"github.com/golang/protobuf/proto"
+)
+
+const (
+	snapshotFile      = "snapshot"
+	segmentPrefix     = "wal."
+	walFlushInterval  = time.Second
+	maxRecordFieldLen = 64 * 1024 * 1024
+)
+
+// persister writes every envelope that is put into the Store to an on-disk
+// write-ahead log. Periodically it rotates the log and writes a snapshot of
+// the entire store, after which the rotated log segments are removed.
+//
+// Each record carries a sequence number. The snapshot records the latest
+// sequence number seen for each source ID, so records that made it into
+// both the snapshot and the active log segment are only restored once.
+type persister struct {
+	dir              string
+	snapshotInterval time.Duration
+	log              *log.Logger
+
+	seq uint64
+
+	// snapshotMu keeps snapshots that are written on the interval and on
+	// demand from overlapping.
+	snapshotMu sync.Mutex
+
+	mu      sync.Mutex
+	segment int
+	wal     *os.File
+	w       *bufio.Writer
+	closed  bool
+
+	done chan struct{}
+}
+
+func newPersister(dir string, snapshotInterval time.Duration, log *log.Logger) *persister {
+	return &persister{
+		dir:              dir,
+		snapshotInterval: snapshotInterval,
+		log:              log,
+		done:             make(chan struct{}),
+	}
+}
+
+// nextSeq returns the sequence number for the next record.
+func (p *persister) nextSeq() uint64 {
+	return atomic.AddUint64(&p.seq, 1)
+}
+
+// observeSeq makes sure that any sequence number handed out by nextSeq is
+// larger than seq.
+func (p *persister) observeSeq(seq uint64) {
+	for {
+		current := atomic.LoadUint64(&p.seq)
+		if seq <= current || atomic.CompareAndSwapUint64(&p.seq, current, seq) {
+			return
+		}
+	}
+}
+
+// append writes the envelope to the active log segment. It is a no-op until
+// the persister has been opened via replay.
+func (p *persister) append(sourceID string, seq uint64, e *loggregator_v2.Envelope) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.w == nil {
+		return nil
+	}
+
+	return writeRecord(p.w, sourceID, seq, e)
+}
+
+// flush writes any buffered records to the active log segment.
+func (p *persister) flush() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.w == nil {
+		return nil
+	}
+
+	return p.w.Flush()
+}
+
+// rotate closes the active log segment and opens the next one. It returns
+// the number of the segment that was closed.
+func (p *persister) rotate() (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return 0, fmt.Errorf("persister is closed")
+	}
+
+	previous := p.segment
+	if err := p.closeSegment(); err != nil {
+		return 0, err
+	}
+
+	f, err := os.OpenFile(
+		filepath.Join(p.dir, segmentPrefix+strconv.Itoa(previous+1)),
+		os.O_CREATE|os.O_WRONLY|os.O_APPEND,
+		0600,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	p.segment = previous + 1
+	p.wal = f
+	p.w = bufio.NewWriter(f)
+
+	return previous, nil
+}
+
+func (p *persister) closeSegment() error {
+	if p.wal == nil {
+		return nil
+	}
+
+	defer func() {
+		p.wal = nil
+		p.w = nil
+	}()
+
+	if err := p.w.Flush(); err != nil {
+		p.wal.Close()
+		return err
+	}
+
+	return p.wal.Close()
+}
+
+// replay reads the snapshot and every log segment and invokes f for each
+// record that has not already been restored.
+func (p *persister) replay(f func(sourceID string, seq uint64, e *loggregator_v2.Envelope)) error {
+	if err := os.MkdirAll(p.dir, 0700); err != nil {
+		return err
+	}
+
+	snapshotSeqs := make(map[string]uint64)
+	err := p.readFile(filepath.Join(p.dir, snapshotFile), func(sourceID string, seq uint64, e *loggregator_v2.Envelope) {
+		if seq > snapshotSeqs[sourceID] {
+			snapshotSeqs[sourceID] = seq
+		}
+		f(sourceID, seq, e)
+	})
+	if err != nil {
+		return err
+	}
+
+	segments, err := p.segments()
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		err := p.readFile(filepath.Join(p.dir, segmentPrefix+strconv.Itoa(segment)), func(sourceID string, seq uint64, e *loggregator_v2.Envelope) {
+			if seq <= snapshotSeqs[sourceID] {
+				return
+			}
+			f(sourceID, seq, e)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(segments) > 0 {
+		p.mu.Lock()
+		p.segment = segments[len(segments)-1]
+		p.mu.Unlock()
+	}
+
+	return nil
+}
+
+func (p *persister) readFile(path string, f func(sourceID string, seq uint64, e *loggregator_v2.Envelope)) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	r := bufio.NewReader(file)
+	for {
+		sourceID, seq, e, err := readRecord(r)
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			// A partially written record at the end of a file is expected
+			// if the process was killed while writing it.
+			p.log.Printf("stopped reading %s: %s", path, err)
+			return nil
+		}
+
+		p.observeSeq(seq)
+		f(sourceID, seq, e)
+	}
+}
+
+// segments returns the numbers of every log segment on disk in ascending
+// order.
+func (p *persister) segments() ([]int, error) {
+	infos, err := ioutil.ReadDir(p.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []int
+	for _, info := range infos {
+		if !strings.HasPrefix(info.Name(), segmentPrefix) {
+			continue
+		}
+
+		segment, err := strconv.Atoi(strings.TrimPrefix(info.Name(), segmentPrefix))
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+
+	sort.Ints(segments)
+	return segments, nil
+}
+
+// snapshot rotates the log and writes every envelope in the store to a new
+// snapshot. Log segments that are covered by the snapshot are removed.
+func (p *persister) snapshot(store *Store) error {
+	p.snapshotMu.Lock()
+	defer p.snapshotMu.Unlock()
+
+	previous, err := p.rotate()
+	if err != nil {
+		return err
+	}
+
+	tmpPath := filepath.Join(p.dir, snapshotFile+".tmp")
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	store.storageIndex.Range(func(_ interface{}, s interface{}) bool {
+		sourceID, seq, envelopes := copyEnvelopes(s.(*storage))
+
+		// The envelopes are written without holding the lock of the
+		// storage so that puts for the source ID are not blocked on I/O.
+		for _, e := range envelopes {
+			if err = writeRecord(w, sourceID, seq, e); err != nil {
+				return false
+			}
+		}
+
+		return true
+	})
+
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if err == nil {
+		err = f.Sync()
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, filepath.Join(p.dir, snapshotFile)); err != nil {
+		return err
+	}
+
+	segments, err := p.segments()
+	if err != nil {
+		return err
+	}
+
+	for _, segment := range segments {
+		if segment > previous {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(p.dir, segmentPrefix+strconv.Itoa(segment))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyEnvelopes returns the source ID, the latest sequence number and every
+// envelope of the storage.
+func copyEnvelopes(storage *storage) (string, uint64, []*loggregator_v2.Envelope) {
+	storage.RLock()
+	defer storage.RUnlock()
+
+	var envelopes []*loggregator_v2.Envelope
+	storage.Traverse(math.MinInt64, math.MaxInt64, false, func(e *loggregator_v2.Envelope, _ uint64) bool {
+		envelopes = append(envelopes, e)
+		return false
+	})
+
+	return storage.sourceId, storage.lastSeq, envelopes
+}
+
+// run flushes the active log segment every second and writes a snapshot
+// every snapshot interval until the persister is closed. Without a positive
+// snapshot interval, snapshots are only written on Restore and Purge.
+func (p *persister) run(store *Store) {
+	flushTicker := time.NewTicker(walFlushInterval)
+	defer flushTicker.Stop()
+
+	var snapshots <-chan time.Time
+	if p.snapshotInterval > 0 {
+		snapshotTicker := time.NewTicker(p.snapshotInterval)
+		defer snapshotTicker.Stop()
+		snapshots = snapshotTicker.C
+	}
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-flushTicker.C:
+			if err := p.flush(); err != nil {
+				store.metrics.incPersistenceErrors.Add(1)
+				p.log.Printf("failed to flush write-ahead log: %s", err)
+			}
+		case <-snapshots:
+			startTime := time.Now()
+			if err := p.snapshot(store); err != nil {
+				store.metrics.incPersistenceErrors.Add(1)
+				p.log.Printf("failed to write snapshot: %s", err)
+				continue
+			}
+			store.metrics.setSnapshotDuration.Set(float64(time.Since(startTime) / time.Millisecond))
+		}
+	}
+}
+
+// close flushes and closes the active log segment. Any further appends are
+// dropped.
+func (p *persister) close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil
+	}
+
+	p.closed = true
+	close(p.done)
+
+	return p.closeSegment()
+}
+
+// writeRecord encodes a record as the length prefixed source ID, the
+// sequence number and the length prefixed marshalled envelope.
+func writeRecord(w io.Writer, sourceID string, seq uint64, e *loggregator_v2.Envelope) error {
+	data, err := proto.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, 0, 3*binary.MaxVarintLen64+len(sourceID)+len(data))
+	buf = appendUvarint(buf, uint64(len(sourceID)))
+	buf = append(buf, sourceID...)
+	buf = appendUvarint(buf, seq)
+	buf = appendUvarint(buf, uint64(len(data)))
+	buf = append(buf, data...)
+
+	_, err = w.Write(buf)
+	return err
+}
+
+func readRecord(r *bufio.Reader) (string, uint64, *loggregator_v2.Envelope, error) {
+	sourceID, err := readBytes(r)
+	if err != nil {
+		return "", 0, nil, err
+	}
+
+	seq, err := binary.ReadUvarint(r)
+	if err != nil {
+		return "", 0, nil, unexpectedEOF(err)
+	}
+
+	data, err := readBytes(r)
+	if err != nil {
+		return "", 0, nil, unexpectedEOF(err)
+	}
+
+	var e loggregator_v2.Envelope
+	if err := proto.Unmarshal(data, &e); err != nil {
+		return "", 0, nil, err
+	}
+
+	return string(sourceID), seq, &e, nil
+}
+
+func readBytes(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if n > maxRecordFieldLen {
+		return nil, fmt.Errorf("record field length %d exceeds maximum", n)
+	}
+
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	return buf, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
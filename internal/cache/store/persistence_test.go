@@ -0,0 +1,168 @@
+package store_test
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	"code.cloudfoundry.org/log-cache/internal/cache/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Persistence", func() {
+	var (
+		dir    string
+		sm     *testhelpers.SpyMetricsRegistry
+		sp     *spyPruner
+		logger *log.Logger
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "log-cache-persistence")
+		Expect(err).ToNot(HaveOccurred())
+
+		sp = newSpyPruner()
+		sm = testhelpers.NewMetricsRegistry()
+		logger = log.New(GinkgoWriter, "", 0)
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	newPersistentStore := func(maxPerSource int) *store.Store {
+		s := store.NewStore(maxPerSource, sp, sm, store.WithPersistence(dir, time.Hour, logger))
+		Expect(s.Restore()).To(Succeed())
+		return s
+	}
+
+	It("restores envelopes that were put into a previous store", func() {
+		s := newPersistentStore(5)
+		s.Put(buildEnvelope(1, "a"), "a")
+		s.Put(buildEnvelope(2, "b"), "b")
+		s.Put(buildEnvelope(3, "a"), "a")
+		Expect(s.Close()).To(Succeed())
+
+		s = newPersistentStore(5)
+		defer s.Close()
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(0, 10), store.ReadOptions{Limit: 10})
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(1)))
+		Expect(envelopes[1].GetTimestamp()).To(Equal(int64(3)))
+
+		envelopes = s.Get("b", time.Unix(0, 0), time.Unix(0, 10), store.ReadOptions{Limit: 10})
+		Expect(envelopes).To(HaveLen(1))
+	})
+
+	It("restores envelopes from both the snapshot and the write-ahead log exactly once", func() {
+		s := newPersistentStore(5)
+		s.Put(buildEnvelope(1, "a"), "a")
+		Expect(s.Close()).To(Succeed())
+
+		// Restoring writes a snapshot containing the first envelope.
+		s = newPersistentStore(5)
+		s.Put(buildEnvelope(2, "a"), "a")
+		Expect(s.Close()).To(Succeed())
+
+		s = newPersistentStore(5)
+		defer s.Close()
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(0, 10), store.ReadOptions{Limit: 10})
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(1)))
+		Expect(envelopes[1].GetTimestamp()).To(Equal(int64(2)))
+	})
+
+	It("does not restore purged envelopes", func() {
+		s := newPersistentStore(5)
+		s.Put(buildEnvelope(1, "a"), "a")
+		s.Put(buildEnvelope(2, "a"), "a")
+		s.Put(buildEnvelope(3, "a"), "a")
+
+		purged, err := s.Purge("a", time.Unix(0, 2), time.Unix(0, 3))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(purged).To(Equal(1))
+
+		s.Put(buildEnvelope(4, "a"), "a")
+		Expect(s.Close()).To(Succeed())
+
+		s = newPersistentStore(5)
+		defer s.Close()
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(0, 10), store.ReadOptions{Limit: 10})
+		Expect(envelopes).To(HaveLen(3))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(1)))
+		Expect(envelopes[1].GetTimestamp()).To(Equal(int64(3)))
+		Expect(envelopes[2].GetTimestamp()).To(Equal(int64(4)))
+	})
+
+	It("applies the per-source maximum to restored envelopes", func() {
+		s := newPersistentStore(5)
+		for i := int64(0); i < 5; i++ {
+			s.Put(buildEnvelope(i, "a"), "a")
+		}
+		Expect(s.Close()).To(Succeed())
+
+		s = newPersistentStore(2)
+		defer s.Close()
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(0, 10), store.ReadOptions{Limit: 10})
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(3)))
+		Expect(envelopes[1].GetTimestamp()).To(Equal(int64(4)))
+	})
+
+	It("ignores a partially written record at the end of the log", func() {
+		s := newPersistentStore(5)
+		s.Put(buildEnvelope(1, "a"), "a")
+		Expect(s.Close()).To(Succeed())
+
+		segments, err := filepath.Glob(filepath.Join(dir, "wal.*"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(segments).ToNot(BeEmpty())
+
+		f, err := os.OpenFile(segments[len(segments)-1], os.O_APPEND|os.O_WRONLY, 0600)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = f.Write([]byte{0x01, 'a', 0x07, 0x40})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+
+		s = newPersistentStore(5)
+		defer s.Close()
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(0, 10), store.ReadOptions{Limit: 10})
+		Expect(envelopes).To(HaveLen(1))
+	})
+
+	It("only writes snapshots on restore without a snapshot interval", func() {
+		s := store.NewStore(5, sp, sm, store.WithPersistence(dir, 0, logger))
+		Expect(s.Restore()).To(Succeed())
+		s.Put(buildEnvelope(1, "a"), "a")
+		Expect(s.Close()).To(Succeed())
+
+		s = store.NewStore(5, sp, sm, store.WithPersistence(dir, -time.Second, logger))
+		Expect(s.Restore()).To(Succeed())
+		defer s.Close()
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(0, 10), store.ReadOptions{Limit: 10})
+		Expect(envelopes).To(HaveLen(1))
+	})
+
+	It("does not persist anything without WithPersistence", func() {
+		s := store.NewStore(5, sp, sm)
+		Expect(s.Restore()).To(Succeed())
+		s.Put(buildEnvelope(1, "a"), "a")
+		Expect(s.Close()).To(Succeed())
+
+		files, err := ioutil.ReadDir(dir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+})
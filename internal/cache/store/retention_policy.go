@@ -0,0 +1,87 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RetentionPolicy determines the maximum age of envelopes for each source
+// ID. Envelopes older than the maximum age are expired by the store
+// regardless of memory pressure.
+type RetentionPolicy struct {
+	defaultMaxAge time.Duration
+	rules         []RetentionRule
+}
+
+// RetentionRule overrides the default maximum age for either a single
+// source ID or every source ID that matches a pattern.
+type RetentionRule struct {
+	SourceID string
+	Pattern  *regexp.Regexp
+	MaxAge   time.Duration
+}
+
+// NewRetentionPolicy returns a new RetentionPolicy. A default maximum age
+// of zero disables age based expiry for source IDs that no rule applies to.
+// Rules that target a source ID directly take precedence over patterns.
+// Otherwise the first matching pattern wins.
+func NewRetentionPolicy(defaultMaxAge time.Duration, rules ...RetentionRule) *RetentionPolicy {
+	return &RetentionPolicy{
+		defaultMaxAge: defaultMaxAge,
+		rules:         rules,
+	}
+}
+
+// ParseRetentionRule parses a rule of the form <source-id>=<duration> or
+// /<pattern>/=<duration>, e.g. doppler=10m or /^app-.*$/=2h.
+func ParseRetentionRule(rule string) (RetentionRule, error) {
+	i := strings.LastIndex(rule, "=")
+	if i < 0 {
+		return RetentionRule{}, fmt.Errorf("retention rule %q must be of the form <source-id>=<duration>", rule)
+	}
+
+	target, rawMaxAge := rule[:i], rule[i+1:]
+	maxAge, err := time.ParseDuration(rawMaxAge)
+	if err != nil {
+		return RetentionRule{}, fmt.Errorf("retention rule %q has an invalid duration: %s", rule, err)
+	}
+
+	if len(target) > 1 && strings.HasPrefix(target, "/") && strings.HasSuffix(target, "/") {
+		pattern, err := regexp.Compile(target[1 : len(target)-1])
+		if err != nil {
+			return RetentionRule{}, fmt.Errorf("retention rule %q has an invalid pattern: %s", rule, err)
+		}
+
+		return RetentionRule{Pattern: pattern, MaxAge: maxAge}, nil
+	}
+
+	if target == "" {
+		return RetentionRule{}, fmt.Errorf("retention rule %q is missing a source ID", rule)
+	}
+
+	return RetentionRule{SourceID: target, MaxAge: maxAge}, nil
+}
+
+// MaxAge returns the maximum age of envelopes for the given source ID. Zero
+// means that envelopes do not expire based on their age.
+func (p *RetentionPolicy) MaxAge(sourceID string) time.Duration {
+	if p == nil {
+		return 0
+	}
+
+	for _, r := range p.rules {
+		if r.Pattern == nil && r.SourceID == sourceID {
+			return r.MaxAge
+		}
+	}
+
+	for _, r := range p.rules {
+		if r.Pattern != nil && r.Pattern.MatchString(sourceID) {
+			return r.MaxAge
+		}
+	}
+
+	return p.defaultMaxAge
+}
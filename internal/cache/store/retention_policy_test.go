@@ -0,0 +1,74 @@
+package store_test
+
+import (
+	"regexp"
+	"time"
+
+	"code.cloudfoundry.org/log-cache/internal/cache/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RetentionPolicy", func() {
+	It("uses the default max age when no rule applies", func() {
+		p := store.NewRetentionPolicy(time.Hour)
+		Expect(p.MaxAge("some-id")).To(Equal(time.Hour))
+	})
+
+	It("prefers source ID rules over patterns", func() {
+		p := store.NewRetentionPolicy(time.Hour,
+			store.RetentionRule{Pattern: regexp.MustCompile("^doppler"), MaxAge: 10 * time.Minute},
+			store.RetentionRule{SourceID: "doppler-1", MaxAge: time.Minute},
+		)
+
+		Expect(p.MaxAge("doppler-1")).To(Equal(time.Minute))
+		Expect(p.MaxAge("doppler-2")).To(Equal(10 * time.Minute))
+		Expect(p.MaxAge("app")).To(Equal(time.Hour))
+	})
+
+	It("uses the first matching pattern", func() {
+		p := store.NewRetentionPolicy(0,
+			store.RetentionRule{Pattern: regexp.MustCompile("^app-"), MaxAge: 2 * time.Hour},
+			store.RetentionRule{Pattern: regexp.MustCompile("app"), MaxAge: time.Minute},
+		)
+
+		Expect(p.MaxAge("app-1")).To(Equal(2 * time.Hour))
+		Expect(p.MaxAge("my-app")).To(Equal(time.Minute))
+		Expect(p.MaxAge("other")).To(BeZero())
+	})
+
+	It("does not expire anything without a policy", func() {
+		var p *store.RetentionPolicy
+		Expect(p.MaxAge("some-id")).To(BeZero())
+	})
+
+	Describe("ParseRetentionRule", func() {
+		It("parses a source ID rule", func() {
+			r, err := store.ParseRetentionRule("doppler=10m")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.SourceID).To(Equal("doppler"))
+			Expect(r.Pattern).To(BeNil())
+			Expect(r.MaxAge).To(Equal(10 * time.Minute))
+		})
+
+		It("parses a pattern rule", func() {
+			r, err := store.ParseRetentionRule("/^app=.*$/=2h")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.SourceID).To(BeEmpty())
+			Expect(r.Pattern.String()).To(Equal("^app=.*$"))
+			Expect(r.MaxAge).To(Equal(2 * time.Hour))
+		})
+
+		DescribeTable("invalid rules", func(rule string) {
+			_, err := store.ParseRetentionRule(rule)
+			Expect(err).To(HaveOccurred())
+		},
+			Entry("missing duration", "doppler"),
+			Entry("invalid duration", "doppler=forever"),
+			Entry("missing source ID", "=10m"),
+			Entry("invalid pattern", "/(/=10m"),
+		)
+	})
+})
@@ -0,0 +1,216 @@
+package store
+
+import (
+	"sort"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// segmentWidth is the span of time that the envelopes of a single segment
+// fall into.
+const segmentWidth = int64(10e9)
+
+// segmentBackend keeps envelopes in segments that each cover a fixed span
+// of time. Segments do not overlap, so the envelopes of consecutive
+// segments are already in timestamp order. Envelopes are appended to the
+// newest segment and only late envelopes have to be inserted into the
+// middle of an older one. Envelopes with the same timestamp are kept in
+// the order of their sequence numbers.
+//
+// Segments release their memory once every envelope in them has been
+// removed. Removing every envelope before a cutoff drops whole segments at
+// once.
+type segmentBackend struct {
+	segments []*segment
+	size     int
+}
+
+type segment struct {
+	// start is the beginning of the span of time the segment covers.
+	start int64
+
+	// entries are ordered by timestamp and sequence number.
+	entries []entry
+
+	// removed is the number of leading envelopes that have been evicted.
+	removed int
+}
+
+func (s *segment) live() []entry { return s.entries[s.removed:] }
+func (s *segment) min() int64    { return s.entries[s.removed].timestamp() }
+func (s *segment) max() int64    { return s.entries[len(s.entries)-1].timestamp() }
+
+// insert adds the entry after every entry with the same or an earlier
+// timestamp.
+func (s *segment) insert(en entry) {
+	n := len(s.entries)
+	if n == s.removed || s.entries[n-1].timestamp() <= en.timestamp() {
+		s.entries = append(s.entries, en)
+		return
+	}
+
+	live := s.live()
+	i := s.removed + sort.Search(len(live), func(i int) bool {
+		return live[i].timestamp() > en.timestamp()
+	})
+
+	s.entries = append(s.entries, entry{})
+	copy(s.entries[i+1:], s.entries[i:])
+	s.entries[i] = en
+}
+
+func newSegmentBackend() *segmentBackend {
+	return &segmentBackend{}
+}
+
+// segmentStart returns the beginning of the span of time that the given
+// timestamp falls into.
+func segmentStart(ts int64) int64 {
+	start := ts - ts%segmentWidth
+	if ts%segmentWidth < 0 {
+		start -= segmentWidth
+	}
+
+	return start
+}
+
+func (b *segmentBackend) Put(e *loggregator_v2.Envelope, seq uint64) {
+	en := entry{e: e, seq: seq}
+	start := segmentStart(e.GetTimestamp())
+	b.size++
+
+	// Envelopes mostly arrive in order, so the newest segment is the
+	// common case.
+	n := len(b.segments)
+	if n > 0 && b.segments[n-1].start == start {
+		b.segments[n-1].insert(en)
+		return
+	}
+
+	i := sort.Search(n, func(i int) bool { return b.segments[i].start >= start })
+	if i < n && b.segments[i].start == start {
+		b.segments[i].insert(en)
+		return
+	}
+
+	s := &segment{
+		start:   start,
+		entries: []entry{en},
+	}
+
+	b.segments = append(b.segments, nil)
+	copy(b.segments[i+1:], b.segments[i:])
+	b.segments[i] = s
+}
+
+func (b *segmentBackend) Size() int {
+	return b.size
+}
+
+func (b *segmentBackend) Oldest() (int64, bool) {
+	if len(b.segments) == 0 {
+		return 0, false
+	}
+
+	return b.segments[0].min(), true
+}
+
+func (b *segmentBackend) RemoveOldest() envelopeStats {
+	if len(b.segments) == 0 {
+		return envelopeStats{}
+	}
+
+	s := b.segments[0]
+	stats := statsOf(s.entries[s.removed].e)
+	s.entries[s.removed] = entry{}
+	s.removed++
+	b.size--
+
+	if s.removed == len(s.entries) {
+		b.dropOldestSegment()
+	}
+
+	return stats
+}
+
+func (b *segmentBackend) RemoveBefore(cutoff int64) envelopeStats {
+	var removed envelopeStats
+	for len(b.segments) > 0 && b.segments[0].max() < cutoff {
+		for _, en := range b.segments[0].live() {
+			removed.add(statsOf(en.e))
+		}
+		b.dropOldestSegment()
+	}
+
+	for len(b.segments) > 0 && b.segments[0].min() < cutoff {
+		removed.add(b.RemoveOldest())
+	}
+
+	return removed
+}
+
+func (b *segmentBackend) dropOldestSegment() {
+	b.size -= len(b.segments[0].live())
+	b.segments[0] = nil
+	b.segments = b.segments[1:]
+}
+
+func (b *segmentBackend) Traverse(start, end int64, descending bool, f visitor) {
+	em := &envelopeEmitter{f: f}
+
+	if descending {
+		i := sort.Search(len(b.segments), func(i int) bool { return b.segments[i].start >= end })
+		for i--; i >= 0; i-- {
+			live := b.segments[i].live()
+			lo := sort.Search(len(live), func(i int) bool { return live[i].timestamp() >= start })
+			hi := sort.Search(len(live), func(i int) bool { return live[i].timestamp() >= end })
+			if em.emitDesc(live[lo:hi]) || lo > 0 {
+				return
+			}
+		}
+
+		return
+	}
+
+	i := sort.Search(len(b.segments), func(i int) bool { return b.segments[i].start+segmentWidth > start })
+	for ; i < len(b.segments); i++ {
+		live := b.segments[i].live()
+		lo := sort.Search(len(live), func(i int) bool { return live[i].timestamp() >= start })
+		hi := sort.Search(len(live), func(i int) bool { return live[i].timestamp() >= end })
+		if em.emitAsc(live[lo:hi]) || hi < len(live) {
+			return
+		}
+	}
+}
+
+func (b *segmentBackend) TraverseTimestamps(timestamps []int64, descending bool, f visitor) {
+	em := &envelopeEmitter{f: f}
+
+	for i := range timestamps {
+		ts := timestamps[i]
+		if descending {
+			ts = timestamps[len(timestamps)-1-i]
+		}
+
+		start := segmentStart(ts)
+		j := sort.Search(len(b.segments), func(i int) bool { return b.segments[i].start >= start })
+		if j == len(b.segments) || b.segments[j].start != start {
+			continue
+		}
+
+		live := b.segments[j].live()
+		lo := sort.Search(len(live), func(i int) bool { return live[i].timestamp() >= ts })
+		hi := sort.Search(len(live), func(i int) bool { return live[i].timestamp() > ts })
+
+		if descending {
+			if em.emitDesc(live[lo:hi]) {
+				return
+			}
+			continue
+		}
+
+		if em.emitAsc(live[lo:hi]) {
+			return
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package store_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	"code.cloudfoundry.org/log-cache/internal/cache/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Segment storage engine", func() {
+	var (
+		s  *store.Store
+		sm *testhelpers.SpyMetricsRegistry
+		sp *spyPruner
+	)
+
+	BeforeEach(func() {
+		sp = newSpyPruner()
+		sm = testhelpers.NewMetricsRegistry()
+		s = store.NewStore(20000, sp, sm, store.WithStorageEngine(store.SegmentEngine))
+	})
+
+	It("keeps any number of envelopes with the same timestamp", func() {
+		for i := 0; i < 10000; i++ {
+			s.Put(buildEnvelope(5, "a"), "a")
+		}
+		s.Put(buildEnvelope(4, "a"), "a")
+		s.Put(buildEnvelope(6, "a"), "a")
+
+		Expect(s.Meta()["a"].Count).To(Equal(int64(10002)))
+
+		envelopes := s.Get("a", time.Unix(0, 5), time.Unix(0, 6), store.ReadOptions{Limit: 1})
+		Expect(envelopes).To(HaveLen(10000))
+
+		envelopes = s.Get("a", time.Unix(0, 0), time.Unix(0, 10), store.ReadOptions{Limit: 2, Descending: true})
+		Expect(envelopes).To(HaveLen(10001))
+		Expect(envelopes[0].Timestamp).To(Equal(int64(6)))
+	})
+
+	It("returns envelopes across segments in order", func() {
+		for i := int64(0); i < 60; i++ {
+			s.Put(buildEnvelope(i*int64(time.Second), "a"), "a")
+		}
+
+		// Late envelopes end up in the segments they belong to.
+		s.Put(buildEnvelope(int64(15500*time.Millisecond), "a"), "a")
+		s.Put(buildEnvelope(-int64(time.Second), "a"), "a")
+
+		envelopes := s.Get("a", time.Unix(-1, 0), time.Unix(60, 0), store.ReadOptions{Limit: 100})
+		Expect(envelopes).To(HaveLen(62))
+		for i := 1; i < len(envelopes); i++ {
+			Expect(envelopes[i].Timestamp).To(BeNumerically(">=", envelopes[i-1].Timestamp))
+		}
+
+		envelopes = s.Get("a", time.Unix(14, 0), time.Unix(31, 0), store.ReadOptions{Limit: 5, Descending: true})
+		Expect(envelopes).To(HaveLen(5))
+		Expect(envelopes[0].Timestamp).To(Equal(int64(30 * time.Second)))
+		Expect(envelopes[4].Timestamp).To(Equal(int64(26 * time.Second)))
+
+		envelopes = s.Get("a", time.Unix(14, 0), time.Unix(31, 0), store.ReadOptions{Limit: 3})
+		Expect(envelopes).To(HaveLen(3))
+		Expect(envelopes[2].Timestamp).To(Equal(int64(15500 * time.Millisecond)))
+	})
+
+	It("expires whole segments by age", func() {
+		s = store.NewStore(20000, sp, sm,
+			store.WithStorageEngine(store.SegmentEngine),
+			store.WithRetentionPolicy(store.NewRetentionPolicy(time.Minute)),
+		)
+
+		now := time.Now()
+		for i := 0; i < 300; i++ {
+			s.Put(buildEnvelope(now.Add(-time.Duration(i)*time.Second).UnixNano(), "a"), "a")
+		}
+
+		Eventually(func() int64 {
+			return s.Meta()["a"].Count
+		}).Should(BeNumerically("<=", 60))
+
+		envelopes := s.Get("a", time.Unix(0, 0), now.Add(time.Second), store.ReadOptions{Limit: 300})
+		Expect(envelopes).ToNot(BeEmpty())
+		Expect(envelopes[0].Timestamp).To(BeNumerically(">=", now.Add(-time.Minute).UnixNano()))
+		Expect(sm.GetMetricValue("log_cache_expired", nil)).To(BeNumerically(">=", 240))
+	})
+})
@@ -3,6 +3,8 @@ package store
 import (
 	"code.cloudfoundry.org/go-loggregator/metrics"
 	"container/heap"
+	"log"
+	"math"
 	"regexp"
 	"sync"
 	"sync/atomic"
@@ -10,8 +12,6 @@ import (
 
 	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
 	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
-	"github.com/emirpasic/gods/trees/avltree"
-	"github.com/emirpasic/gods/utils"
 )
 
 type MetricsRegistry interface {
@@ -41,12 +41,40 @@ type Store struct {
 	count           int64
 	oldestTimestamp int64
 
-	maxPerSource      int
-	maxTimestampFudge int64
+	maxPerSource int
+
+	// maxBytesPerSource is zero unless the MemoryConsultant limits the
+	// bytes of each source ID.
+	maxBytesPerSource int64
+	engine            StorageEngine
 
 	metrics Metrics
 	mc      MemoryConsultant
 
+	// persister is nil unless the store was configured WithPersistence.
+	persister *persister
+
+	// retentionPolicy is nil unless the store was configured
+	// WithRetentionPolicy.
+	retentionPolicy *RetentionPolicy
+
+	// evictionPolicy is nil unless the store was configured
+	// WithEvictionPolicy. Every tree belongs to one of the evictionClasses
+	// regardless.
+	evictionPolicy  *EvictionPolicy
+	evictionClasses map[string]*evictionClass
+
+	// dedupWindow is zero unless the store was configured
+	// WithDeduplication.
+	dedupWindow time.Duration
+
+	// subscriptions holds the subscriptions for each source ID. The slices
+	// are never modified once stored so they can be used without holding
+	// subscriptionsMu.
+	subscriptionsMu   sync.RWMutex
+	subscriptions     map[string][]*subscription
+	subscriptionCount int64
+
 	truncationCompleted chan bool
 }
 
@@ -58,13 +86,59 @@ type Metrics struct {
 	setStoreSize          metrics.Gauge
 	setTruncationDuration metrics.Gauge
 	setMemoryUtilization  metrics.Gauge
+	incPersistenceErrors  metrics.Counter
+	setSnapshotDuration   metrics.Gauge
+	incDuplicatesDropped  metrics.Counter
+	incPurged             metrics.Counter
+}
+
+// StoreOption configures a Store.
+type StoreOption func(*Store)
+
+// WithPersistence returns a StoreOption that writes every envelope to a
+// write-ahead log in the given directory and snapshots the whole store on
+// the given interval. An interval of zero or less disables periodic
+// snapshots, so the log is only compacted on Restore and Purge. The data is
+// only read back when Restore is invoked.
+func WithPersistence(dir string, snapshotInterval time.Duration, log *log.Logger) StoreOption {
+	return func(s *Store) {
+		s.persister = newPersister(dir, snapshotInterval, log)
+	}
+}
+
+// WithRetentionPolicy returns a StoreOption that expires envelopes once
+// they are older than the maximum age the policy allows for their source ID.
+// Expiry happens alongside memory based pruning.
+func WithRetentionPolicy(p *RetentionPolicy) StoreOption {
+	return func(s *Store) {
+		s.retentionPolicy = p
+	}
+}
+
+// WithEvictionPolicy returns a StoreOption that prunes envelopes by
+// weighted eviction class instead of strictly oldest first when memory is
+// scarce.
+func WithEvictionPolicy(p *EvictionPolicy) StoreOption {
+	return func(s *Store) {
+		s.evictionPolicy = p
+	}
+}
+
+// WithDeduplication returns a StoreOption that drops envelopes that have
+// already been put for the same source ID. Envelopes are considered equal
+// if their source ID, timestamp, instance ID and message are. Only
+// envelopes within the given window of the newest envelope of a source ID
+// are remembered.
+func WithDeduplication(window time.Duration) StoreOption {
+	return func(s *Store) {
+		s.dedupWindow = window
+	}
 }
 
-func NewStore(maxPerSource int, mc MemoryConsultant, m MetricsRegistry) *Store {
+func NewStore(maxPerSource int, mc MemoryConsultant, m MetricsRegistry, opts ...StoreOption) *Store {
 	store := &Store{
-		maxPerSource:      maxPerSource,
-		maxTimestampFudge: 4000,
-		oldestTimestamp:   MIN_INT64,
+		maxPerSource:    maxPerSource,
+		oldestTimestamp: MIN_INT64,
 
 		metrics: Metrics{
 			incExpired:            m.NewCounter("log_cache_expired"),
@@ -74,13 +148,35 @@ func NewStore(maxPerSource int, mc MemoryConsultant, m MetricsRegistry) *Store {
 			setStoreSize:          m.NewGauge("log_cache_store_size", metrics.WithMetricTags(map[string]string{"unit": "entries"})),
 			setTruncationDuration: m.NewGauge("log_cache_truncation_duration", metrics.WithMetricTags(map[string]string{"unit": "milliseconds"})),
 			setMemoryUtilization:  m.NewGauge("log_cache_memory_utilization", metrics.WithMetricTags(map[string]string{"unit": "percentage"})),
+			incPersistenceErrors:  m.NewCounter("log_cache_persistence_errors"),
+			setSnapshotDuration:   m.NewGauge("log_cache_snapshot_duration", metrics.WithMetricTags(map[string]string{"unit": "milliseconds"})),
+			incDuplicatesDropped:  m.NewCounter("log_cache_duplicates_dropped"),
+			incPurged:             m.NewCounter("log_cache_purged"),
 		},
 
 		mc:                  mc,
+		subscriptions:       make(map[string][]*subscription),
 		truncationCompleted: make(chan bool),
 	}
 
+	for _, o := range opts {
+		o(store)
+	}
+
+	store.evictionClasses = make(map[string]*evictionClass)
+	for name, weight := range store.evictionPolicy.weights() {
+		store.evictionClasses[name] = &evictionClass{
+			name:       name,
+			weight:     weight,
+			incExpired: m.NewCounter("log_cache_class_expired", metrics.WithMetricTags(map[string]string{"class": name})),
+		}
+	}
+
 	store.mc.SetMemoryReporter(store.metrics.setMemoryUtilization)
+	if bc, ok := store.mc.(byteConsultant); ok {
+		bc.trackBytes(store.bytes)
+		store.maxBytesPerSource = bc.perSourceBytes()
+	}
 
 	go store.truncationLoop(500 * time.Millisecond)
 
@@ -96,9 +192,19 @@ func (store *Store) getOrInitializeStorage(sourceId string) (*storage, bool) {
 	envelopeStorage, existingSourceId := store.storageIndex.Load(sourceId)
 
 	if !existingSourceId {
+		var dedup *deduplicator
+		if store.dedupWindow > 0 {
+			dedup = newDeduplicator(int64(store.dedupWindow))
+		}
+
 		envelopeStorage = &storage{
 			sourceId: sourceId,
-			Tree:     avltree.NewWith(utils.Int64Comparator),
+			dedup:    dedup,
+			maxAge:   store.retentionPolicy.MaxAge(sourceId),
+			class:    store.evictionClasses[store.evictionPolicy.Class(sourceId)],
+			backend:  store.newBackend(),
+			names:    newNameIndex(),
+			types:    newTypeIndex(),
 		}
 		store.storageIndex.Store(sourceId, envelopeStorage.(*storage))
 		newStorage = true
@@ -107,37 +213,61 @@ func (store *Store) getOrInitializeStorage(sourceId string) (*storage, bool) {
 	return envelopeStorage.(*storage), newStorage
 }
 
-func (storage *storage) insertOrSwap(store *Store, e *loggregator_v2.Envelope) {
+// insertOrSwap stores the envelope. It returns false if the envelope was
+// dropped as a duplicate.
+func (storage *storage) insertOrSwap(store *Store, e *loggregator_v2.Envelope) bool {
 	storage.Lock()
 	defer storage.Unlock()
 
+	// Duplicates have to be dropped before anything is evicted to make
+	// room for them.
+	if storage.dedup != nil && storage.dedup.isDuplicate(e) {
+		store.metrics.incDuplicatesDropped.Add(1)
+		return false
+	}
+
 	// If we're at our maximum capacity, remove an envelope before inserting
 	if storage.Size() >= store.maxPerSource {
-		oldestTimestamp := storage.Left().Key.(int64)
-		storage.Remove(oldestTimestamp)
-		storage.meta.Expired++
-		store.metrics.incExpired.Add(1)
+		storage.stats.sub(storage.RemoveOldest())
+		store.expired(storage, 1)
 	} else {
 		atomic.AddInt64(&store.count, 1)
 		store.metrics.setStoreSize.Set(float64(atomic.LoadInt64(&store.count)))
 	}
 
-	var timestampFudge int64
-	for timestampFudge = 0; timestampFudge < store.maxTimestampFudge; timestampFudge++ {
-		_, exists := storage.Get(e.Timestamp + timestampFudge)
+	storage.putSeq++
+	storage.Put(e, storage.putSeq)
+	storage.names.add(e)
+	storage.types.add(e)
+	storage.stats.add(statsOf(e))
+	storage.rate.add(time.Now())
+
+	if store.maxBytesPerSource > 0 {
+		var removed int
+		for storage.Size() > 1 && storage.stats.bytes > store.maxBytesPerSource {
+			storage.stats.sub(storage.RemoveOldest())
+			removed++
+		}
 
-		if !exists {
-			break
+		if removed > 0 {
+			atomic.AddInt64(&store.count, -int64(removed))
+			store.expired(storage, removed)
+			store.metrics.setStoreSize.Set(float64(atomic.LoadInt64(&store.count)))
 		}
 	}
 
-	storage.Put(e.Timestamp+timestampFudge, e)
+	if store.persister != nil {
+		storage.lastSeq = store.persister.nextSeq()
+		if err := store.persister.append(storage.sourceId, storage.lastSeq, e); err != nil {
+			store.metrics.incPersistenceErrors.Add(1)
+		}
+	}
 
 	if e.Timestamp > storage.meta.NewestTimestamp {
 		storage.meta.NewestTimestamp = e.Timestamp
 	}
 
-	oldestTimestamp := storage.Left().Key.(int64)
+	oldestTimestamp, _ := storage.Oldest()
 	storage.meta.OldestTimestamp = oldestTimestamp
 	storeOldestTimestamp := atomic.LoadInt64(&store.oldestTimestamp)
 
@@ -148,6 +278,19 @@ func (storage *storage) insertOrSwap(store *Store, e *loggregator_v2.Envelope) {
 
 	cachePeriod := calculateCachePeriod(storeOldestTimestamp)
 	store.metrics.setCachePeriod.Set(float64(cachePeriod))
+
+	return true
+}
+
+// OldestTimestamp returns the timestamp of the oldest envelope in the store.
+// It returns false if the store is empty.
+func (store *Store) OldestTimestamp() (int64, bool) {
+	oldestTimestamp := atomic.LoadInt64(&store.oldestTimestamp)
+	if oldestTimestamp == MIN_INT64 {
+		return 0, false
+	}
+
+	return oldestTimestamp, true
 }
 
 func (store *Store) WaitForTruncationToComplete() bool {
@@ -182,27 +325,98 @@ func (store *Store) Put(envelope *loggregator_v2.Envelope, sourceId string) {
 	store.metrics.incIngress.Add(1)
 
 	envelopeStorage, _ := store.getOrInitializeStorage(sourceId)
-	envelopeStorage.insertOrSwap(store, envelope)
+	if !envelopeStorage.insertOrSwap(store, envelope) {
+		return
+	}
+
+	store.publish(envelope, sourceId)
 }
 
-func (store *Store) BuildExpirationHeap() *ExpirationHeap {
-	expirationHeap := &ExpirationHeap{}
-	heap.Init(expirationHeap)
+// Restore reads back the envelopes that were persisted by a previous store
+// configured with the same persistence directory. Envelopes are inserted the
+// same way as with Put, so the per-source maximum and memory based pruning
+// still apply. Once restored, a fresh snapshot is written and the store
+// starts persisting new envelopes. Restore is a no-op unless the store was
+// configured WithPersistence and should be invoked before any calls to Put.
+func (store *Store) Restore() error {
+	if store.persister == nil {
+		return nil
+	}
+
+	err := store.persister.replay(func(sourceId string, seq uint64, e *loggregator_v2.Envelope) {
+		envelopeStorage, _ := store.getOrInitializeStorage(sourceId)
+		envelopeStorage.insertOrSwap(store, e)
+
+		envelopeStorage.Lock()
+		envelopeStorage.lastSeq = seq
+		envelopeStorage.Unlock()
+	})
+	if err != nil {
+		return err
+	}
 
+	if err := store.persister.snapshot(store); err != nil {
+		return err
+	}
+
+	go store.persister.run(store)
+
+	return nil
+}
+
+// Close flushes and closes the write-ahead log. Envelopes that are put
+// afterwards are no longer persisted.
+func (store *Store) Close() error {
+	if store.persister == nil {
+		return nil
+	}
+
+	return store.persister.close()
+}
+
+// buildExpirationHeaps returns an expiration heap of the trees of every
+// eviction class.
+func (store *Store) buildExpirationHeaps() []*classExpiration {
+	byClass := make(map[*evictionClass]*classExpiration)
+	var classes []*classExpiration
 	store.storageIndex.Range(func(sourceId interface{}, tree interface{}) bool {
 		tree.(*storage).RLock()
-		oldestTimestamp := tree.(*storage).Left().Key.(int64)
-		heap.Push(expirationHeap, storageExpiration{timestamp: oldestTimestamp, sourceId: sourceId.(string), tree: tree.(*storage)})
-		tree.(*storage).RUnlock()
+		defer tree.(*storage).RUnlock()
+
+		oldestTimestamp, ok := tree.(*storage).Oldest()
+		if !ok {
+			return true
+		}
+
+		c, ok := byClass[tree.(*storage).class]
+		if !ok {
+			c = &classExpiration{class: tree.(*storage).class}
+			byClass[c.class] = c
+			classes = append(classes, c)
+		}
+
+		c.heap = append(c.heap, storageExpiration{timestamp: oldestTimestamp, sourceId: sourceId.(string), tree: tree.(*storage)})
+		c.count += int64(tree.(*storage).Size())
 
 		return true
 	})
 
-	return expirationHeap
+	for _, c := range classes {
+		heap.Init(&c.heap)
+	}
+
+	return classes
 }
 
 // truncate removes the n oldest envelopes across all trees
 func (store *Store) truncate() {
+	defer store.pruneIndexes()
+
+	if store.expireByAge() {
+		store.metrics.setStoreSize.Set(float64(atomic.LoadInt64(&store.count)))
+		store.updateCachePeriod()
+	}
+
 	storeCount := atomic.LoadInt64(&store.count)
 
 	numberToPrune := store.mc.GetQuantityToPrune(storeCount)
@@ -217,14 +431,21 @@ func (store *Store) truncate() {
 		numberToPrune = int(storeCount)
 	}
 
-	expirationHeap := store.BuildExpirationHeap()
+	classes := store.buildExpirationHeaps()
 
-	// Remove envelopes one at a time, popping state from the expirationHeap
+	// Remove envelopes one at a time from the class that exceeds its share
+	// of the store the most, popping state from its expiration heap
 	for i := 0; i < numberToPrune; i++ {
-		oldest := heap.Pop(expirationHeap)
-		newOldestTimestamp, valid := store.removeOldestEnvelope(oldest.(storageExpiration).tree, oldest.(storageExpiration).sourceId)
+		c := mostOverQuota(classes)
+		if c == nil {
+			break
+		}
+
+		oldest := heap.Pop(&c.heap).(storageExpiration)
+		c.count--
+		newOldestTimestamp, valid := store.removeOldestEnvelope(oldest.tree, oldest.sourceId)
 		if valid {
-			heap.Push(expirationHeap, storageExpiration{timestamp: newOldestTimestamp, sourceId: oldest.(storageExpiration).sourceId, tree: oldest.(storageExpiration).tree})
+			heap.Push(&c.heap, storageExpiration{timestamp: newOldestTimestamp, sourceId: oldest.sourceId, tree: oldest.tree})
 		}
 	}
 
@@ -234,20 +455,135 @@ func (store *Store) truncate() {
 		store.sendTruncationCompleted(true)
 	}()
 
-	// If there's nothing left on the heap, our store is empty, so we can
+	// Grab the oldest remaining timestamp to update the cache period
+	oldestTimestamp := MIN_INT64
+	for _, c := range classes {
+		if c.heap.Len() > 0 && c.heap[0].timestamp < oldestTimestamp {
+			oldestTimestamp = c.heap[0].timestamp
+		}
+	}
+
+	// If there's nothing left on the heaps, our store is empty, so we can
 	// reset everything to default values and bail out
-	if expirationHeap.Len() == 0 {
+	if oldestTimestamp == MIN_INT64 {
 		atomic.StoreInt64(&store.oldestTimestamp, MIN_INT64)
 		store.metrics.setCachePeriod.Set(0)
 		return
 	}
 
-	// Otherwise, grab the next oldest timestamp and use it to update the cache period
-	if oldest := expirationHeap.Pop(); oldest.(storageExpiration).tree != nil {
-		atomic.StoreInt64(&store.oldestTimestamp, oldest.(storageExpiration).timestamp)
-		cachePeriod := calculateCachePeriod(oldest.(storageExpiration).timestamp)
-		store.metrics.setCachePeriod.Set(float64(cachePeriod))
+	atomic.StoreInt64(&store.oldestTimestamp, oldestTimestamp)
+	store.metrics.setCachePeriod.Set(float64(calculateCachePeriod(oldestTimestamp)))
+}
+
+// bytes returns the estimated serialized size of every stored envelope.
+func (store *Store) bytes() int64 {
+	var total int64
+	store.storageIndex.Range(func(_ interface{}, tree interface{}) bool {
+		tree.(*storage).RLock()
+		defer tree.(*storage).RUnlock()
+
+		total += tree.(*storage).stats.bytes
+		return true
+	})
+
+	return total
+}
+
+// expireByAge removes every envelope that is older than the maximum age
+// of its source ID. It returns true if any envelopes were removed.
+func (store *Store) expireByAge() bool {
+	if store.retentionPolicy == nil {
+		return false
+	}
+
+	now := time.Now().UnixNano()
+	var expired bool
+	store.storageIndex.Range(func(sourceId interface{}, tree interface{}) bool {
+		if tree.(*storage).maxAge <= 0 {
+			return true
+		}
+
+		cutoff := now - int64(tree.(*storage).maxAge)
+		if store.removeEnvelopesBefore(tree.(*storage), sourceId.(string), cutoff) > 0 {
+			expired = true
+		}
+
+		return true
+	})
+
+	return expired
+}
+
+func (store *Store) removeEnvelopesBefore(treeToPrune *storage, sourceId string, cutoff int64) int {
+	treeToPrune.Lock()
+	defer treeToPrune.Unlock()
+
+	stats := treeToPrune.RemoveBefore(cutoff)
+	treeToPrune.stats.sub(stats)
+
+	removed := int(stats.envelopes)
+	if removed == 0 {
+		return 0
+	}
+
+	atomic.AddInt64(&store.count, -int64(removed))
+	store.expired(treeToPrune, removed)
+
+	if treeToPrune.Size() == 0 {
+		store.storageIndex.Delete(sourceId)
+		return removed
+	}
+
+	treeToPrune.meta.OldestTimestamp, _ = treeToPrune.Oldest()
+
+	return removed
+}
+
+// pruneIndexes drops the timestamps of evicted envelopes from the name and
+// type indexes of every tree.
+func (store *Store) pruneIndexes() {
+	store.storageIndex.Range(func(_ interface{}, tree interface{}) bool {
+		tree.(*storage).Lock()
+		defer tree.(*storage).Unlock()
+
+		if oldestTimestamp, ok := tree.(*storage).Oldest(); ok {
+			tree.(*storage).names.prune(oldestTimestamp)
+			tree.(*storage).types.prune(oldestTimestamp)
+		}
+
+		return true
+	})
+}
+
+// expired records that n envelopes of the tree were expired.
+func (store *Store) expired(tree *storage, n int) {
+	store.metrics.incExpired.Add(float64(n))
+	tree.class.incExpired.Add(float64(n))
+	tree.meta.Expired += int64(n)
+}
+
+// updateCachePeriod recalculates the oldest timestamp across all trees and
+// updates the cache period metric accordingly.
+func (store *Store) updateCachePeriod() {
+	oldestTimestamp := MIN_INT64
+	store.storageIndex.Range(func(_ interface{}, tree interface{}) bool {
+		tree.(*storage).RLock()
+		defer tree.(*storage).RUnlock()
+
+		if t, ok := tree.(*storage).Oldest(); ok && t < oldestTimestamp {
+			oldestTimestamp = t
+		}
+
+		return true
+	})
+
+	atomic.StoreInt64(&store.oldestTimestamp, oldestTimestamp)
+	if oldestTimestamp == MIN_INT64 {
+		store.metrics.setCachePeriod.Set(0)
+		return
 	}
+
+	store.metrics.setCachePeriod.Set(float64(calculateCachePeriod(oldestTimestamp)))
 }
 
 func (store *Store) removeOldestEnvelope(treeToPrune *storage, sourceId string) (int64, bool) {
@@ -259,67 +595,265 @@ func (store *Store) removeOldestEnvelope(treeToPrune *storage, sourceId string)
 	}
 
 	atomic.AddInt64(&store.count, -1)
-	store.metrics.incExpired.Add(1)
+	store.expired(treeToPrune, 1)
 
-	oldestEnvelope := treeToPrune.Left()
-
-	treeToPrune.Remove(oldestEnvelope.Key.(int64))
+	treeToPrune.stats.sub(treeToPrune.RemoveOldest())
 
 	if treeToPrune.Size() == 0 {
 		store.storageIndex.Delete(sourceId)
 		return 0, false
 	}
 
-	newOldestEnvelope := treeToPrune.Left()
-	oldestTimestampAfterRemoval := newOldestEnvelope.Key.(int64)
+	oldestTimestampAfterRemoval, _ := treeToPrune.Oldest()
 
-	treeToPrune.meta.Expired++
 	treeToPrune.meta.OldestTimestamp = oldestTimestampAfterRemoval
 
 	return oldestTimestampAfterRemoval, true
 }
 
+// Purge deletes the envelopes of the source ID with a timestamp between
+// start and end. Start is inclusive while end is not: [start..end). Purged
+// envelopes do not count as expired. If the store is persisted, a snapshot
+// is written so that the purged envelopes are removed from disk as well. It
+// returns the number of envelopes that were deleted.
+func (store *Store) Purge(sourceId string, start, end time.Time) (int, error) {
+	tree, ok := store.storageIndex.Load(sourceId)
+	if !ok {
+		return 0, nil
+	}
+
+	purged := store.purgeRange(tree.(*storage), sourceId, start.UnixNano(), end.UnixNano())
+	if purged == 0 {
+		return 0, nil
+	}
+
+	store.metrics.incPurged.Add(float64(purged))
+	store.metrics.setStoreSize.Set(float64(atomic.LoadInt64(&store.count)))
+	store.updateCachePeriod()
+
+	if store.persister != nil {
+		if err := store.persister.snapshot(store); err != nil {
+			store.metrics.incPersistenceErrors.Add(1)
+			return purged, err
+		}
+	}
+
+	return purged, nil
+}
+
+// purgeRange rebuilds the tree from the envelopes outside of [start..end).
+func (store *Store) purgeRange(tree *storage, sourceId string, start, end int64) int {
+	tree.Lock()
+	defer tree.Unlock()
+
+	var kept []entry
+	tree.Traverse(math.MinInt64, math.MaxInt64, false, func(e *loggregator_v2.Envelope, seq uint64) bool {
+		if e.GetTimestamp() < start || e.GetTimestamp() >= end {
+			kept = append(kept, entry{e: e, seq: seq})
+		}
+		return false
+	})
+
+	purged := tree.Size() - len(kept)
+	if purged == 0 {
+		return 0
+	}
+
+	atomic.AddInt64(&store.count, -int64(purged))
+
+	tree.backend = store.newBackend()
+	tree.names = newNameIndex()
+	tree.types = newTypeIndex()
+	tree.stats = envelopeStats{}
+	tree.meta.NewestTimestamp = 0
+	for _, en := range kept {
+		e := en.e
+		tree.Put(e, en.seq)
+		tree.names.add(e)
+		tree.types.add(e)
+		tree.stats.add(statsOf(e))
+
+		if e.GetTimestamp() > tree.meta.NewestTimestamp {
+			tree.meta.NewestTimestamp = e.GetTimestamp()
+		}
+	}
+
+	if tree.Size() == 0 {
+		store.storageIndex.Delete(sourceId)
+		return purged
+	}
+
+	tree.meta.OldestTimestamp, _ = tree.Oldest()
+
+	return purged
+}
+
+// ReadOptions restricts the envelopes that are returned by Get and GetPage.
+type ReadOptions struct {
+	// EnvelopeTypes only returns envelopes of the given types. If empty,
+	// envelopes of every type are returned.
+	EnvelopeTypes []logcache_v1.EnvelopeType
+
+	// NameFilter only returns counters, gauge metrics and timers with a
+	// matching name.
+	NameFilter *regexp.Regexp
+
+	// PayloadFilter only returns log envelopes with a matching payload.
+	PayloadFilter *regexp.Regexp
+
+	// InstanceID only returns envelopes of the given instance ID.
+	InstanceID string
+
+	// TagMatchers only returns envelopes with tags that satisfy every
+	// matcher.
+	TagMatchers []TagMatcher
+
+	// Limit is the number of matching envelopes after which the read
+	// stops.
+	Limit int
+
+	// Descending returns the newest envelopes first.
+	Descending bool
+}
+
 // Get fetches envelopes from the store based on the source ID, start and end
-// time. Start is inclusive while end is not: [start..end).
-func (store *Store) Get(
+// time. Start is inclusive while end is not: [start..end). The envelopes are
+// further restricted by the read options.
+func (store *Store) Get(index string, start, end time.Time, opts ReadOptions) []*loggregator_v2.Envelope {
+	res, _ := store.GetPage(index, start, end, opts, nil)
+	return res
+}
+
+// Cursor is the position of an envelope in the store. Envelopes that share
+// a timestamp are told apart by the sequence number they were put with, so
+// the position does not change as envelopes are put or evicted.
+type Cursor struct {
+	Timestamp int64
+	Seq       uint64
+}
+
+// GetPage behaves like Get but resumes after the given cursor, if any. For
+// ascending reads the cursor replaces start, for descending reads it
+// replaces end. It returns the cursor after the last envelope that was
+// read, which is nil unless the read stopped at the limit.
+func (store *Store) GetPage(
 	index string,
 	start time.Time,
 	end time.Time,
-	envelopeTypes []logcache_v1.EnvelopeType,
-	nameFilter *regexp.Regexp,
-	limit int,
-	descending bool,
-) []*loggregator_v2.Envelope {
+	opts ReadOptions,
+	after *Cursor,
+) ([]*loggregator_v2.Envelope, *Cursor) {
 	tree, ok := store.storageIndex.Load(index)
 	if !ok {
-		return nil
+		return nil, nil
 	}
 
 	tree.(*storage).RLock()
 	defer tree.(*storage).RUnlock()
 
-	traverser := store.treeAscTraverse
-	if descending {
-		traverser = store.treeDescTraverse
+	startNano, endNano := start.UnixNano(), end.UnixNano()
+
+	// pos is the position of the last envelope that was traversed,
+	// regardless of whether it matched.
+	var pos Cursor
+	if after != nil {
+		if opts.Descending {
+			endNano = after.Timestamp + 1
+		} else {
+			startNano = after.Timestamp
+		}
 	}
 
-	var res []*loggregator_v2.Envelope
-	traverser(tree.(*storage).Root, start.UnixNano(), end.UnixNano(), func(e *loggregator_v2.Envelope) bool {
-		e = store.filterByName(e, nameFilter)
+	var (
+		res  []*loggregator_v2.Envelope
+		done bool
+	)
+	visit := func(e *loggregator_v2.Envelope, seq uint64) bool {
+		// Once the limit is reached only the envelopes that share the
+		// timestamp of the last one are still returned.
+		if done && e.GetTimestamp() != pos.Timestamp {
+			return true
+		}
+
+		// Skip the envelopes the cursor has already passed.
+		if after != nil && e.GetTimestamp() == after.Timestamp {
+			if (!opts.Descending && seq <= after.Seq) || (opts.Descending && seq >= after.Seq) {
+				return false
+			}
+		}
+		pos = Cursor{Timestamp: e.GetTimestamp(), Seq: seq}
+
+		e = store.filterByName(e, opts.NameFilter)
 		if e == nil {
 			return false
 		}
 
-		if store.validEnvelopeType(e, envelopeTypes) {
+		if !store.filterByPayload(e, opts.PayloadFilter) {
+			return false
+		}
+
+		if opts.InstanceID != "" && e.GetInstanceId() != opts.InstanceID {
+			return false
+		}
+
+		if !store.filterByTags(e, opts.TagMatchers) {
+			return false
+		}
+
+		if store.validEnvelopeType(e, opts.EnvelopeTypes) {
 			res = append(res, e)
 		}
 
 		// Return true to stop traversing
-		return len(res) >= limit
-	})
+		done = len(res) >= opts.Limit
+		return done
+	}
+
+	// With a name filter only the envelopes with a matching metric name can
+	// be returned, so the name index is used to skip every other envelope.
+	// Likewise the type index skips the envelopes of other types, which is
+	// only worth the lookups if most envelopes are of other types.
+	if opts.NameFilter != nil {
+		lists := tree.(*storage).names.lookup(opts.NameFilter, startNano, endNano)
+		traverseIndexed(tree.(*storage), lists, opts, visit, &done)
+	} else if lists, ok := tree.(*storage).types.lookup(opts.EnvelopeTypes, startNano, endNano); ok && tree.(*storage).stats.minority(opts.EnvelopeTypes) {
+		traverseIndexed(tree.(*storage), lists, opts, visit, &done)
+	} else {
+		tree.(*storage).Traverse(startNano, endNano, opts.Descending, visit)
+	}
 
 	store.metrics.incEgress.Add(float64(len(res)))
-	return res
+	if !done {
+		return res, nil
+	}
+
+	return res, &pos
+}
+
+// traverseIndexed visits the envelopes at the timestamps of the index lists
+// until done is set. The timestamps are merged a batch at a time, starting
+// with as many as the limit, so that a read does not have to merge every
+// timestamp of the range.
+func traverseIndexed(tree *storage, lists [][]int64, opts ReadOptions, f visitor, done *bool) {
+	n := opts.Limit
+	if n < 1 {
+		n = 1
+	}
+
+	for len(lists) > 0 && !*done {
+		timestamps := mergeTimestamps(lists, n, opts.Descending)
+		tree.TraverseTimestamps(timestamps, opts.Descending, f)
+
+		last := timestamps[len(timestamps)-1]
+		if opts.Descending {
+			last = timestamps[0]
+		}
+		lists = dropTimestamps(lists, last, opts.Descending)
+
+		// Filters may reject the envelopes of most timestamps, so each
+		// batch is larger than the last.
+		n *= 2
+	}
 }
 
 func (store *Store) filterByName(envelope *loggregator_v2.Envelope, nameFilter *regexp.Regexp) *loggregator_v2.Envelope {
@@ -369,91 +903,39 @@ func (store *Store) filterByName(envelope *loggregator_v2.Envelope, nameFilter *
 	return nil
 }
 
-func (s *Store) validEnvelopeType(e *loggregator_v2.Envelope, types []logcache_v1.EnvelopeType) bool {
-	if types == nil {
+func (store *Store) filterByPayload(envelope *loggregator_v2.Envelope, payloadFilter *regexp.Regexp) bool {
+	if payloadFilter == nil {
 		return true
 	}
-	for _, t := range types {
-		if s.checkEnvelopeType(e, t) {
-			return true
-		}
-	}
-	return false
-}
 
-func (s *Store) treeAscTraverse(
-	n *avltree.Node,
-	start int64,
-	end int64,
-	f func(e *loggregator_v2.Envelope) bool,
-) bool {
-	if n == nil {
+	log := envelope.GetLog()
+	if log == nil {
 		return false
 	}
 
-	e := n.Value.(*loggregator_v2.Envelope)
-	t := e.GetTimestamp()
-
-	if t >= start {
-		if s.treeAscTraverse(n.Children[0], start, end, f) {
-			return true
-		}
+	return payloadFilter.Match(log.GetPayload())
+}
 
-		if (t >= end || f(e)) && !isNodeAFudgeSequenceMember(n, 1) {
-			return true
+func (store *Store) filterByTags(envelope *loggregator_v2.Envelope, tagMatchers []TagMatcher) bool {
+	for _, m := range tagMatchers {
+		if !m.Matches(envelope) {
+			return false
 		}
 	}
 
-	return s.treeAscTraverse(n.Children[1], start, end, f)
+	return true
 }
 
-func isNodeAFudgeSequenceMember(node *avltree.Node, nextChildIndex int) bool {
-	e := node.Value.(*loggregator_v2.Envelope)
-	timestamp := e.GetTimestamp()
-
-	// check if node is internal to a fudge sequence
-	if timestamp != node.Key.(int64) {
+func (s *Store) validEnvelopeType(e *loggregator_v2.Envelope, types []logcache_v1.EnvelopeType) bool {
+	if types == nil {
 		return true
 	}
-
-	// node is not internal, but could initiate a fudge sequence, so
-	// check next child
-	nextChild := node.Children[nextChildIndex]
-	if nextChild == nil {
-		return false
-	}
-
-	// if next child exists, check it for fudge sequence membership.
-	// if the child's timestamps don't match, then the parent is the first
-	// member of a fudge sequence.
-	nextEnvelope := nextChild.Value.(*loggregator_v2.Envelope)
-	return (nextEnvelope.GetTimestamp() != nextChild.Key.(int64))
-}
-
-func (s *Store) treeDescTraverse(
-	n *avltree.Node,
-	start int64,
-	end int64,
-	f func(e *loggregator_v2.Envelope) bool,
-) bool {
-	if n == nil {
-		return false
-	}
-
-	e := n.Value.(*loggregator_v2.Envelope)
-	t := e.GetTimestamp()
-
-	if t < end {
-		if s.treeDescTraverse(n.Children[1], start, end, f) {
-			return true
-		}
-
-		if (t < start || f(e)) && !isNodeAFudgeSequenceMember(n, 0) {
+	for _, t := range types {
+		if s.checkEnvelopeType(e, t) {
 			return true
 		}
 	}
-
-	return s.treeDescTraverse(n.Children[0], start, end, f)
+	return false
 }
 
 func (s *Store) checkEnvelopeType(e *loggregator_v2.Envelope, t logcache_v1.EnvelopeType) bool {
@@ -482,10 +964,14 @@ func (s *Store) checkEnvelopeType(e *loggregator_v2.Envelope, t logcache_v1.Enve
 // Meta returns each source ID tracked in the store.
 func (store *Store) Meta() map[string]logcache_v1.MetaInfo {
 	metaReport := make(map[string]logcache_v1.MetaInfo)
+	now := time.Now()
 
 	store.storageIndex.Range(func(sourceId interface{}, tree interface{}) bool {
 		tree.(*storage).RLock()
-		metaReport[sourceId.(string)] = tree.(*storage).meta
+		meta := tree.(*storage).meta
+		tree.(*storage).stats.apply(&meta)
+		meta.Rate = tree.(*storage).rate.rate(now)
+		metaReport[sourceId.(string)] = meta
 		tree.(*storage).RUnlock()
 
 		return true
@@ -508,7 +994,35 @@ type storage struct {
 	sourceId string
 	meta     logcache_v1.MetaInfo
 
-	*avltree.Tree
+	// lastSeq is the sequence number of the most recently persisted
+	// envelope.
+	lastSeq uint64
+
+	// putSeq is the sequence number of the most recently put envelope.
+	// It orders the envelopes that share a timestamp.
+	putSeq uint64
+
+	// maxAge is the retention policy's maximum age for the source ID. Zero
+	// disables age based expiry.
+	maxAge time.Duration
+
+	// stats and rate are reported as part of the meta information.
+	stats envelopeStats
+	rate  rateCounter
+
+	// dedup is nil unless the store drops duplicate envelopes.
+	dedup *deduplicator
+
+	// class is the eviction class the tree is pruned by.
+	class *evictionClass
+
+	// names indexes the metric names of the envelopes in the backend.
+	names *nameIndex
+
+	// types indexes the envelope types of the envelopes in the backend.
+	types *typeIndex
+
+	backend
 	sync.RWMutex
 }
 
@@ -5,6 +5,8 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"regexp"
+	"runtime"
 	"testing"
 	"time"
 
@@ -71,7 +73,83 @@ func BenchmarkStoreGetTime5MinRange(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		results = s.Get(sourceIDs[i%len(sourceIDs)], fiveMinAgo, now, nil, nil, b.N, false)
+		results = s.Get(sourceIDs[i%len(sourceIDs)], fiveMinAgo, now, store.ReadOptions{Limit: b.N})
+	}
+}
+
+func BenchmarkStoreWriteCompact(b *testing.B) {
+	s := store.NewStore(MaxPerSource, &staticPruner{}, nopMetrics{}, store.WithStorageEngine(store.CompactEngine))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e := gen()
+		s.Put(e, e.GetSourceId())
+	}
+}
+
+func BenchmarkStoreGetTime5MinRangeCompact(b *testing.B) {
+	s := store.NewStore(MaxPerSource, &staticPruner{}, nopMetrics{}, store.WithStorageEngine(store.CompactEngine))
+
+	for i := 0; i < MaxPerSource/10; i++ {
+		e := gen()
+		s.Put(e, e.GetSourceId())
+	}
+	now := time.Now()
+	fiveMinAgo := now.Add(-5 * time.Minute)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		results = s.Get(sourceIDs[i%len(sourceIDs)], fiveMinAgo, now, store.ReadOptions{Limit: b.N})
+	}
+}
+
+func BenchmarkStoreWriteSegment(b *testing.B) {
+	s := store.NewStore(MaxPerSource, &staticPruner{}, nopMetrics{}, store.WithStorageEngine(store.SegmentEngine))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		e := gen()
+		s.Put(e, e.GetSourceId())
+	}
+}
+
+func BenchmarkStoreGetTime5MinRangeSegment(b *testing.B) {
+	s := store.NewStore(MaxPerSource, &staticPruner{}, nopMetrics{}, store.WithStorageEngine(store.SegmentEngine))
+
+	for i := 0; i < MaxPerSource/10; i++ {
+		e := gen()
+		s.Put(e, e.GetSourceId())
+	}
+	now := time.Now()
+	fiveMinAgo := now.Add(-5 * time.Minute)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		results = s.Get(sourceIDs[i%len(sourceIDs)], fiveMinAgo, now, store.ReadOptions{Limit: b.N})
+	}
+}
+
+// BenchmarkStoreRetainedHeap reports how much heap each engine retains per
+// stored envelope. Run with -benchtime=100000x or similar to get a stable
+// figure.
+func BenchmarkStoreRetainedHeap(b *testing.B) {
+	for _, engine := range []store.StorageEngine{store.AVLTreeEngine, store.CompactEngine, store.SegmentEngine} {
+		b.Run(engine.String(), func(b *testing.B) {
+			var before, after runtime.MemStats
+			runtime.GC()
+			runtime.ReadMemStats(&before)
+
+			s := store.NewStore(MaxPerSource, &staticPruner{}, nopMetrics{}, store.WithStorageEngine(engine))
+			for i := 0; i < b.N; i++ {
+				e := benchBuildTaggedLog(sourceIDs[i%len(sourceIDs)], int64(i))
+				s.Put(e, e.GetSourceId())
+			}
+
+			runtime.GC()
+			runtime.ReadMemStats(&after)
+			b.Logf("%d envelopes: %.1f bytes/envelope", b.N, float64(int64(after.HeapAlloc)-int64(before.HeapAlloc))/float64(b.N))
+			runtime.KeepAlive(s)
+		})
 	}
 }
 
@@ -87,7 +165,56 @@ func BenchmarkStoreGetLogType(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		results = s.Get(sourceIDs[i%len(sourceIDs)], MinTime, MaxTime, logType, nil, b.N, false)
+		results = s.Get(sourceIDs[i%len(sourceIDs)], MinTime, MaxTime, store.ReadOptions{EnvelopeTypes: logType, Limit: b.N})
+	}
+}
+
+// BenchmarkStoreGetLogTypeAmongGauges reads the logs of a source that mostly
+// holds gauges. With the type index, the cost of a read depends on the logs
+// rather than on every envelope of the source.
+func BenchmarkStoreGetLogTypeAmongGauges(b *testing.B) {
+	for _, engine := range []store.StorageEngine{store.AVLTreeEngine, store.CompactEngine, store.SegmentEngine} {
+		for _, logEvery := range []int{2, 20, 200} {
+			b.Run(fmt.Sprintf("%s/1-in-%d-logs", engine, logEvery), func(b *testing.B) {
+				s := store.NewStore(MaxPerSource, &staticPruner{}, nopMetrics{}, store.WithStorageEngine(engine))
+				for i := 0; i < 100000; i++ {
+					if i%logEvery == 0 {
+						s.Put(benchBuildTaggedLog("source-id", int64(i)), "source-id")
+						continue
+					}
+					s.Put(benchBuildGauge("source-id", int64(i)), "source-id")
+				}
+				logType := []logcache_v1.EnvelopeType{logcache_v1.EnvelopeType_LOG}
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					results = s.Get("source-id", MinTime, MaxTime, store.ReadOptions{EnvelopeTypes: logType, Limit: 100, Descending: true})
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkStoreGetMetricName reads a single metric from sources with an
+// increasing number of distinct metric names. With the name index, the cost
+// of a read depends on the envelopes of the metric rather than on the width
+// of the source.
+func BenchmarkStoreGetMetricName(b *testing.B) {
+	for _, engine := range []store.StorageEngine{store.AVLTreeEngine, store.CompactEngine, store.SegmentEngine} {
+		for _, width := range []int{10, 100, 1000} {
+			b.Run(fmt.Sprintf("%s/%d-names", engine, width), func(b *testing.B) {
+				s := store.NewStore(MaxPerSource, &staticPruner{}, nopMetrics{}, store.WithStorageEngine(engine))
+				for i := 0; i < 100*width; i++ {
+					s.Put(benchBuildCounter("source-id", fmt.Sprintf("metric-%d", i%width), int64(i)), "source-id")
+				}
+				nameFilter := regexp.MustCompile("^metric-7$")
+
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					results = s.Get("source-id", MinTime, MaxTime, store.ReadOptions{NameFilter: nameFilter, Limit: 1000})
+				}
+			})
+		}
 	}
 }
 
@@ -137,7 +264,7 @@ func BenchmarkMetaWhileReading(b *testing.B) {
 	go func() {
 		close(ready)
 		for i := 0; i < b.N; i++ {
-			results = s.Get(sourceIDs[i%len(sourceIDs)], fiveMinAgo, now, nil, nil, b.N, false)
+			results = s.Get(sourceIDs[i%len(sourceIDs)], fiveMinAgo, now, store.ReadOptions{Limit: b.N})
 		}
 	}()
 	<-ready
@@ -185,6 +312,53 @@ func benchBuildLog(appID string, ts int64) *loggregator_v2.Envelope {
 	}
 }
 
+func benchBuildCounter(sourceID, name string, ts int64) *loggregator_v2.Envelope {
+	return &loggregator_v2.Envelope{
+		SourceId:  sourceID,
+		Timestamp: ts,
+		Message: &loggregator_v2.Envelope_Counter{
+			Counter: &loggregator_v2.Counter{
+				Name:  name,
+				Total: uint64(ts),
+			},
+		},
+	}
+}
+
+func benchBuildGauge(sourceID string, ts int64) *loggregator_v2.Envelope {
+	return &loggregator_v2.Envelope{
+		SourceId:  sourceID,
+		Timestamp: ts,
+		Message: &loggregator_v2.Envelope_Gauge{
+			Gauge: &loggregator_v2.Gauge{
+				Metrics: map[string]*loggregator_v2.GaugeValue{
+					"cpu":    {Unit: "percentage", Value: float64(ts % 100)},
+					"memory": {Unit: "bytes", Value: float64(ts)},
+				},
+			},
+		},
+	}
+}
+
+func benchBuildTaggedLog(sourceID string, ts int64) *loggregator_v2.Envelope {
+	return &loggregator_v2.Envelope{
+		SourceId:   sourceID,
+		InstanceId: fmt.Sprintf("%d", ts%4),
+		Timestamp:  ts,
+		Tags: map[string]string{
+			"deployment": "cf",
+			"job":        "diego-cell",
+			"index":      "a8d4fdc2-8a1e-4d1d-9d3e-1d0e7c0b8f7e",
+			"ip":         "10.0.16.21",
+		},
+		Message: &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{
+				Payload: []byte(fmt.Sprintf("GET /v2/apps/%d 200 %dms", ts%100, ts%37)),
+			},
+		},
+	}
+}
+
 type nopMetrics struct{}
 
 type nopCounter struct {}
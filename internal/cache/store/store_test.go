@@ -3,6 +3,7 @@ package store_test
 import (
 	"code.cloudfoundry.org/go-loggregator/metrics"
 	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	"math"
 	"regexp"
 	"strconv"
 	"sync"
@@ -11,6 +12,7 @@ import (
 	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
 	"code.cloudfoundry.org/log-cache/internal/cache/store"
 	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	"github.com/golang/protobuf/proto"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -18,6 +20,13 @@ import (
 )
 
 var _ = Describe("Store", func() {
+	for _, engine := range []store.StorageEngine{store.AVLTreeEngine, store.CompactEngine, store.SegmentEngine} {
+		engine := engine
+		Context(engine.String(), func() { describeStore(engine) })
+	}
+})
+
+func describeStore(engine store.StorageEngine) {
 	var (
 		s  *store.Store
 		sm *testhelpers.SpyMetricsRegistry
@@ -27,7 +36,7 @@ var _ = Describe("Store", func() {
 	BeforeEach(func() {
 		sp = newSpyPruner()
 		sm = testhelpers.NewMetricsRegistry()
-		s = store.NewStore(5, sp, sm)
+		s = store.NewStore(5, sp, sm, store.WithStorageEngine(engine))
 	})
 
 	It("fetches data based on time and source ID", func() {
@@ -43,7 +52,7 @@ var _ = Describe("Store", func() {
 
 		start := time.Unix(0, 0)
 		end := time.Unix(0, 4)
-		envelopes := s.Get("a", start, end, nil, nil, 10, false)
+		envelopes := s.Get("a", start, end, store.ReadOptions{Limit: 10})
 		Expect(envelopes).To(HaveLen(2))
 
 		for _, e := range envelopes {
@@ -68,7 +77,7 @@ var _ = Describe("Store", func() {
 
 		start := time.Unix(0, 0)
 		end := time.Unix(0, 9999)
-		envelopes := s.Get("a", start, end, nil, nil, 3, false)
+		envelopes := s.Get("a", start, end, store.ReadOptions{Limit: 3})
 		Expect(envelopes).To(HaveLen(3))
 		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(1)))
 		Expect(envelopes[1].GetTimestamp()).To(Equal(int64(2)))
@@ -76,8 +85,8 @@ var _ = Describe("Store", func() {
 	})
 
 	Context("in ascending order", func() {
-		It("respects timestamp fudging when checking the time boundaries", func() {
-			s = store.NewStore(50, sp, sm)
+		It("respects envelopes with the same timestamp when checking the time boundaries", func() {
+			s = store.NewStore(50, sp, sm, store.WithStorageEngine(engine))
 
 			e0 := buildEnvelope(0, "a")
 			e1 := buildEnvelope(1, "a")
@@ -101,7 +110,7 @@ var _ = Describe("Store", func() {
 
 			start := time.Unix(0, 1)
 			end := time.Unix(0, 3)
-			envelopes := s.Get("a", start, end, nil, nil, 5, false)
+			envelopes := s.Get("a", start, end, store.ReadOptions{Limit: 5})
 			Expect(envelopes).To(HaveLen(4))
 			Expect(envelopes[0].GetTimestamp()).To(Equal(int64(1)))
 			Expect(envelopes[1].GetTimestamp()).To(Equal(int64(1)))
@@ -109,8 +118,8 @@ var _ = Describe("Store", func() {
 			Expect(envelopes[3].GetTimestamp()).To(Equal(int64(2)))
 		})
 
-		It("intentionally exceeds the limit when it would otherwise break up a group of fudged timestamps", func() {
-			s = store.NewStore(50, sp, sm)
+		It("intentionally exceeds the limit when it would otherwise break up envelopes with the same timestamp", func() {
+			s = store.NewStore(50, sp, sm, store.WithStorageEngine(engine))
 
 			e0 := buildEnvelope(0, "a")
 			e1 := buildEnvelope(1, "a")
@@ -121,7 +130,7 @@ var _ = Describe("Store", func() {
 
 			start := time.Unix(0, 0)
 			end := time.Unix(0, 2)
-			envelopes := s.Get("a", start, end, nil, nil, 2, false)
+			envelopes := s.Get("a", start, end, store.ReadOptions{Limit: 2})
 			Expect(envelopes).To(HaveLen(3))
 			Expect(envelopes[0].GetTimestamp()).To(Equal(int64(0)))
 			Expect(envelopes[1].GetTimestamp()).To(Equal(int64(1)))
@@ -130,8 +139,8 @@ var _ = Describe("Store", func() {
 	})
 
 	Context("in descending order", func() {
-		It("respects timestamp fudging when checking the time boundaries", func() {
-			s = store.NewStore(50, sp, sm)
+		It("respects envelopes with the same timestamp when checking the time boundaries", func() {
+			s = store.NewStore(50, sp, sm, store.WithStorageEngine(engine))
 
 			e0 := buildEnvelope(0, "a")
 			e1 := buildEnvelope(1, "a")
@@ -155,7 +164,7 @@ var _ = Describe("Store", func() {
 
 			start := time.Unix(0, 1)
 			end := time.Unix(0, 3)
-			envelopes := s.Get("a", start, end, nil, nil, 5, true)
+			envelopes := s.Get("a", start, end, store.ReadOptions{Limit: 5, Descending: true})
 			Expect(envelopes).To(HaveLen(4))
 			Expect(envelopes[0].GetTimestamp()).To(Equal(int64(2)))
 			Expect(envelopes[1].GetTimestamp()).To(Equal(int64(2)))
@@ -163,8 +172,8 @@ var _ = Describe("Store", func() {
 			Expect(envelopes[3].GetTimestamp()).To(Equal(int64(1)))
 		})
 
-		It("intentionally exceeds the limit when it would otherwise break up a group of fudged timestamps", func() {
-			s = store.NewStore(50, sp, sm)
+		It("intentionally exceeds the limit when it would otherwise break up envelopes with the same timestamp", func() {
+			s = store.NewStore(50, sp, sm, store.WithStorageEngine(engine))
 
 			e0 := buildEnvelope(0, "a")
 			e1 := buildEnvelope(1, "a")
@@ -175,7 +184,7 @@ var _ = Describe("Store", func() {
 
 			start := time.Unix(0, 0)
 			end := time.Unix(0, 2)
-			envelopes := s.Get("a", start, end, nil, nil, 2, true)
+			envelopes := s.Get("a", start, end, store.ReadOptions{Limit: 2, Descending: true})
 			Expect(envelopes).To(HaveLen(3))
 			Expect(envelopes[0].GetTimestamp()).To(Equal(int64(1)))
 			Expect(envelopes[1].GetTimestamp()).To(Equal(int64(0)))
@@ -183,6 +192,150 @@ var _ = Describe("Store", func() {
 		})
 	})
 
+	Context("with a cursor", func() {
+		buildInstanceEnvelope := func(timestamp int64, instanceID string) *loggregator_v2.Envelope {
+			return &loggregator_v2.Envelope{
+				Timestamp:  timestamp,
+				SourceId:   "a",
+				InstanceId: instanceID,
+			}
+		}
+
+		instanceIDs := func(es []*loggregator_v2.Envelope) []string {
+			var ids []string
+			for _, e := range es {
+				ids = append(ids, e.GetInstanceId())
+			}
+			return ids
+		}
+
+		BeforeEach(func() {
+			s = store.NewStore(50, sp, sm, store.WithStorageEngine(engine))
+
+			for _, e := range []*loggregator_v2.Envelope{
+				buildInstanceEnvelope(10, "0"),
+				buildInstanceEnvelope(10, "1"),
+				buildInstanceEnvelope(10, "2"),
+				buildInstanceEnvelope(20, "3"),
+				buildInstanceEnvelope(20, "4"),
+			} {
+				s.Put(e, "a")
+			}
+		})
+
+		It("resumes after envelopes that share a timestamp", func() {
+			start, end := time.Unix(0, 0), time.Unix(0, 100)
+
+			envelopes, cursor := s.GetPage("a", start, end, store.ReadOptions{Limit: 2}, nil)
+			Expect(instanceIDs(envelopes)).To(Equal([]string{"0", "1", "2"}))
+			Expect(cursor).To(Equal(&store.Cursor{Timestamp: 10, Seq: 3}))
+
+			// A late envelope with the same timestamp is not skipped.
+			s.Put(buildInstanceEnvelope(10, "5"), "a")
+
+			envelopes, cursor = s.GetPage("a", start, end, store.ReadOptions{Limit: 3}, cursor)
+			Expect(instanceIDs(envelopes)).To(Equal([]string{"5", "3", "4"}))
+			Expect(cursor).To(Equal(&store.Cursor{Timestamp: 20, Seq: 5}))
+
+			envelopes, cursor = s.GetPage("a", start, end, store.ReadOptions{Limit: 5}, cursor)
+			Expect(envelopes).To(BeEmpty())
+			Expect(cursor).To(BeNil())
+		})
+
+		It("does not return a cursor after the last page", func() {
+			start, end := time.Unix(0, 0), time.Unix(0, 100)
+
+			envelopes, cursor := s.GetPage("a", start, end, store.ReadOptions{Limit: 6}, nil)
+			Expect(envelopes).To(HaveLen(5))
+			Expect(cursor).To(BeNil())
+		})
+
+		It("does not skip envelopes if envelopes before the cursor are evicted", func() {
+			s = store.NewStore(5, sp, sm, store.WithStorageEngine(engine))
+			for _, e := range []*loggregator_v2.Envelope{
+				buildInstanceEnvelope(10, "0"),
+				buildInstanceEnvelope(10, "1"),
+				buildInstanceEnvelope(10, "2"),
+				buildInstanceEnvelope(20, "3"),
+				buildInstanceEnvelope(20, "4"),
+			} {
+				s.Put(e, "a")
+			}
+			start, end := time.Unix(0, 0), time.Unix(0, 100)
+
+			envelopes, cursor := s.GetPage("a", start, end, store.ReadOptions{Limit: 2}, nil)
+			Expect(instanceIDs(envelopes)).To(Equal([]string{"0", "1", "2"}))
+
+			// Evicts the envelope of instance 0.
+			s.Put(buildInstanceEnvelope(10, "5"), "a")
+
+			envelopes, _ = s.GetPage("a", start, end, store.ReadOptions{Limit: 5}, cursor)
+			Expect(instanceIDs(envelopes)).To(Equal([]string{"5", "3", "4"}))
+		})
+
+		It("returns every envelope once across pages if timestamps are put out of order", func() {
+			s = store.NewStore(50, sp, sm, store.WithStorageEngine(engine))
+			for _, e := range []*loggregator_v2.Envelope{
+				buildInstanceEnvelope(10, "0"),
+				buildInstanceEnvelope(10, "1"),
+				buildInstanceEnvelope(11, "2"),
+				buildInstanceEnvelope(10, "3"),
+				buildInstanceEnvelope(12, "4"),
+				buildInstanceEnvelope(11, "5"),
+			} {
+				s.Put(e, "a")
+			}
+			start, end := time.Unix(0, 0), time.Unix(0, 100)
+
+			for _, descending := range []bool{false, true} {
+				var (
+					ids    []string
+					cursor *store.Cursor
+				)
+				for i := 0; i < 10; i++ {
+					var envelopes []*loggregator_v2.Envelope
+					envelopes, cursor = s.GetPage("a", start, end, store.ReadOptions{Limit: 1, Descending: descending}, cursor)
+					ids = append(ids, instanceIDs(envelopes)...)
+					if cursor == nil {
+						break
+					}
+				}
+
+				if descending {
+					Expect(ids).To(Equal([]string{"4", "5", "2", "3", "1", "0"}))
+					continue
+				}
+				Expect(ids).To(Equal([]string{"0", "1", "3", "2", "5", "4"}))
+			}
+		})
+
+		It("resumes descending reads", func() {
+			start, end := time.Unix(0, 0), time.Unix(0, 100)
+
+			envelopes, cursor := s.GetPage("a", start, end, store.ReadOptions{Limit: 2, Descending: true}, nil)
+			Expect(envelopes).To(HaveLen(2))
+			Expect(envelopes[0].GetTimestamp()).To(Equal(int64(20)))
+			Expect(envelopes[1].GetTimestamp()).To(Equal(int64(20)))
+
+			envelopes, cursor = s.GetPage("a", start, end, store.ReadOptions{Limit: 2, Descending: true}, cursor)
+			Expect(envelopes).To(HaveLen(3))
+			Expect(cursor.Timestamp).To(Equal(int64(10)))
+
+			envelopes, _ = s.GetPage("a", start, end, store.ReadOptions{Limit: 2, Descending: true}, cursor)
+			Expect(envelopes).To(BeEmpty())
+		})
+
+		It("counts envelopes that do not match towards the cursor", func() {
+			start, end := time.Unix(0, 0), time.Unix(0, 100)
+
+			envelopes, cursor := s.GetPage("a", start, end, store.ReadOptions{InstanceID: "1", Limit: 1}, nil)
+			Expect(instanceIDs(envelopes)).To(Equal([]string{"1"}))
+
+			envelopes, _ = s.GetPage("a", start, end, store.ReadOptions{Limit: 5}, cursor)
+			Expect(instanceIDs(envelopes)).To(Equal([]string{"3", "4"}))
+		})
+	})
+
 	It("returns a maximum number of envelopes in descending order", func() {
 		e1 := buildEnvelope(1, "a")
 		e2 := buildEnvelope(2, "a")
@@ -196,7 +349,7 @@ var _ = Describe("Store", func() {
 
 		start := time.Unix(0, 0)
 		end := time.Unix(0, 9999)
-		envelopes := s.Get("a", start, end, nil, nil, 3, true)
+		envelopes := s.Get("a", start, end, store.ReadOptions{Limit: 3, Descending: true})
 		Expect(envelopes).To(HaveLen(3))
 		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(4)))
 		Expect(envelopes[1].GetTimestamp()).To(Equal(int64(3)))
@@ -234,12 +387,12 @@ var _ = Describe("Store", func() {
 
 			start := time.Unix(0, 0)
 			end := time.Unix(0, 9999)
-			envelopes := s.Get("a", start, end, []logcache_v1.EnvelopeType{envelopeType}, nil, 5, false)
+			envelopes := s.Get("a", start, end, store.ReadOptions{EnvelopeTypes: []logcache_v1.EnvelopeType{envelopeType}, Limit: 5})
 			Expect(envelopes).To(HaveLen(1))
 			Expect(envelopes[0].Message).To(BeAssignableToTypeOf(envelopeWrapper))
 
 			// No Filter
-			envelopes = s.Get("a", start, end, nil, nil, 10, false)
+			envelopes = s.Get("a", start, end, store.ReadOptions{Limit: 10})
 			Expect(envelopes).To(HaveLen(5))
 		},
 
@@ -264,7 +417,7 @@ var _ = Describe("Store", func() {
 
 			start := time.Unix(0, 0)
 			end := time.Unix(0, 9999)
-			envelopes := s.Get("source-id", start, end, nil, filter, 5, false)
+			envelopes := s.Get("source-id", start, end, store.ReadOptions{NameFilter: filter, Limit: 5})
 			Expect(envelopes).To(HaveLen(1))
 
 			targetEnvelope := envelopes[0]
@@ -280,7 +433,7 @@ var _ = Describe("Store", func() {
 			}
 
 			// No Filter
-			envelopes = s.Get("source-id", start, end, nil, nil, 10, false)
+			envelopes = s.Get("source-id", start, end, store.ReadOptions{Limit: 10})
 			Expect(envelopes).To(HaveLen(3))
 		},
 
@@ -289,6 +442,220 @@ var _ = Describe("Store", func() {
 		Entry("Timer", "timer-metric-name", "timer-metric-name"),
 	)
 
+	It("fetches data based on metric name after envelopes were evicted", func() {
+		s = store.NewStore(6, sp, sm, store.WithStorageEngine(engine))
+		for i := int64(0); i < 10; i++ {
+			name := "cpu"
+			if i%2 == 1 {
+				name = "memory"
+			}
+			s.Put(buildTypedEnvelopeWithName(i, name, &loggregator_v2.Counter{}), "source-id")
+
+			switch i {
+			case 6:
+				s.Put(buildTypedEnvelopeWithName(i, "cpu", &loggregator_v2.Timer{}), "source-id")
+			case 8:
+				s.Put(buildLogEnvelope(i, "source-id", "cpu"), "source-id")
+			}
+		}
+
+		// Let the name index be pruned of the evicted envelopes.
+		s.WaitForTruncationToComplete()
+
+		cpu := regexp.MustCompile("^cpu$")
+		start := time.Unix(0, 0)
+		end := time.Unix(0, 9999)
+		envelopes := s.Get("source-id", start, end, store.ReadOptions{NameFilter: cpu, Limit: 10})
+		Expect(envelopes).To(HaveLen(3))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(6)))
+		Expect(envelopes[1].GetTimestamp()).To(Equal(int64(6)))
+		Expect(envelopes[2].GetTimestamp()).To(Equal(int64(8)))
+
+		envelopes = s.Get("source-id", start, end, store.ReadOptions{NameFilter: cpu, Limit: 1, Descending: true})
+		Expect(envelopes).To(HaveLen(1))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(8)))
+		Expect(envelopes[0].GetCounter().GetName()).To(Equal("cpu"))
+
+		envelopes = s.Get("source-id", start, end, store.ReadOptions{NameFilter: regexp.MustCompile("^(cpu|memory)$"), Limit: 10, Descending: true})
+		Expect(envelopes).To(HaveLen(5))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(9)))
+		Expect(envelopes[4].GetTimestamp()).To(Equal(int64(6)))
+	})
+
+	It("fetches indexed envelopes that match beyond the limit of timestamps", func() {
+		s = store.NewStore(100, sp, sm, store.WithStorageEngine(engine))
+		for i := int64(0); i < 40; i++ {
+			e := buildTypedEnvelopeWithName(i, "cpu", &loggregator_v2.Counter{})
+			e.InstanceId = "0"
+			if i >= 37 {
+				e.InstanceId = "1"
+			}
+			s.Put(e, "source-id")
+		}
+		for i := int64(0); i < 50; i++ {
+			s.Put(buildTypedEnvelopeWithName(100+i, "memory", &loggregator_v2.Gauge{}), "source-id")
+		}
+
+		start := time.Unix(0, 0)
+		end := time.Unix(0, 9999)
+		counters := []logcache_v1.EnvelopeType{logcache_v1.EnvelopeType_COUNTER}
+		for _, opts := range []store.ReadOptions{
+			{NameFilter: regexp.MustCompile("^cpu$"), InstanceID: "1", Limit: 2},
+			{EnvelopeTypes: counters, InstanceID: "1", Limit: 2},
+		} {
+			envelopes := s.Get("source-id", start, end, opts)
+			Expect(envelopes).To(HaveLen(2))
+			Expect(envelopes[0].GetTimestamp()).To(Equal(int64(37)))
+			Expect(envelopes[1].GetTimestamp()).To(Equal(int64(38)))
+		}
+
+		envelopes := s.Get("source-id", start, end, store.ReadOptions{NameFilter: regexp.MustCompile("^cpu$"), InstanceID: "0", Limit: 2, Descending: true})
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(36)))
+		Expect(envelopes[1].GetTimestamp()).To(Equal(int64(35)))
+
+		gauges := []logcache_v1.EnvelopeType{logcache_v1.EnvelopeType_GAUGE}
+		envelopes = s.Get("source-id", start, end, store.ReadOptions{EnvelopeTypes: gauges, Limit: 3})
+		Expect(envelopes).To(HaveLen(3))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(100)))
+		Expect(envelopes[2].GetTimestamp()).To(Equal(int64(102)))
+	})
+
+	It("fetches data based on envelope type after envelopes were evicted", func() {
+		s = store.NewStore(8, sp, sm, store.WithStorageEngine(engine))
+		for i := int64(0); i < 20; i++ {
+			if i%5 == 0 {
+				s.Put(buildLogEnvelope(i, "source-id", "some-payload"), "source-id")
+				continue
+			}
+			s.Put(buildTypedEnvelopeWithName(i, "cpu", &loggregator_v2.Gauge{}), "source-id")
+		}
+		s.Put(buildTypedEnvelopeWithName(20, "requests", &loggregator_v2.Counter{}), "source-id")
+
+		// Let the type index be pruned of the evicted envelopes.
+		s.WaitForTruncationToComplete()
+
+		logs := []logcache_v1.EnvelopeType{logcache_v1.EnvelopeType_LOG}
+		start := time.Unix(0, 0)
+		end := time.Unix(0, 9999)
+		envelopes := s.Get("source-id", start, end, store.ReadOptions{EnvelopeTypes: logs, Limit: 10})
+		Expect(envelopes).To(HaveLen(1))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(15)))
+
+		envelopes = s.Get("source-id", start, end, store.ReadOptions{
+			EnvelopeTypes: []logcache_v1.EnvelopeType{
+				logcache_v1.EnvelopeType_COUNTER,
+				logcache_v1.EnvelopeType_LOG,
+			},
+			Limit:      10,
+			Descending: true,
+		})
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(20)))
+		Expect(envelopes[1].GetTimestamp()).To(Equal(int64(15)))
+
+		envelopes = s.Get("source-id", start, end, store.ReadOptions{
+			EnvelopeTypes: []logcache_v1.EnvelopeType{
+				logcache_v1.EnvelopeType_LOG,
+				logcache_v1.EnvelopeType_ANY,
+			},
+			Limit: 10,
+		})
+		Expect(envelopes).To(HaveLen(8))
+	})
+
+	It("fetches log envelopes based on their payload", func() {
+		s = store.NewStore(10, sp, sm, store.WithStorageEngine(engine))
+		s.Put(buildLogEnvelope(1, "a", "GET /v2/apps 200"), "a")
+		s.Put(buildTypedEnvelope(2, "a", &loggregator_v2.Counter{}), "a")
+		s.Put(buildLogEnvelope(3, "a", "GET /v2/apps 500"), "a")
+		s.Put(buildLogEnvelope(4, "a", "POST /v2/apps 500"), "a")
+		s.Put(buildLogEnvelope(5, "a", "GET /v2/apps 503"), "a")
+
+		start := time.Unix(0, 0)
+		end := time.Unix(0, 9999)
+		envelopes := s.Get("a", start, end, store.ReadOptions{PayloadFilter: regexp.MustCompile("GET .* 5"), Limit: 2})
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(3)))
+		Expect(envelopes[1].GetTimestamp()).To(Equal(int64(5)))
+
+		envelopes = s.Get("a", start, end, store.ReadOptions{PayloadFilter: regexp.MustCompile("500"), Limit: 10, Descending: true})
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(4)))
+		Expect(envelopes[1].GetTimestamp()).To(Equal(int64(3)))
+	})
+
+	It("fetches data based on instance ID and tags", func() {
+		s = store.NewStore(10, sp, sm, store.WithStorageEngine(engine))
+		for i, instanceID := range []string{"0", "1", "0", "1", "0"} {
+			e := buildEnvelope(int64(i), "a")
+			e.InstanceId = instanceID
+			e.Tags = map[string]string{"deployment": "cf"}
+			if i%2 == 0 {
+				e.Tags["job"] = "diego-cell"
+			}
+			s.Put(e, "a")
+		}
+
+		matchers := []store.TagMatcher{
+			mustParseTagMatcher("deployment=cf"),
+			mustParseTagMatcher("job=~diego.*"),
+		}
+
+		start := time.Unix(0, 0)
+		end := time.Unix(0, 9999)
+		envelopes := s.Get("a", start, end, store.ReadOptions{TagMatchers: matchers, Limit: 2})
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(0)))
+		Expect(envelopes[1].GetTimestamp()).To(Equal(int64(2)))
+
+		envelopes = s.Get("a", start, end, store.ReadOptions{InstanceID: "1", Limit: 10, Descending: true})
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(3)))
+		Expect(envelopes[1].GetTimestamp()).To(Equal(int64(1)))
+
+		envelopes = s.Get("a", start, end, store.ReadOptions{InstanceID: "1", TagMatchers: matchers, Limit: 10})
+		Expect(envelopes).To(BeEmpty())
+	})
+
+	It("drops duplicate envelopes within the deduplication window", func() {
+		s = store.NewStore(3, sp, sm, store.WithStorageEngine(engine), store.WithDeduplication(time.Second))
+		s.Put(buildLogEnvelope(int64(time.Second), "a", "some-payload"), "a")
+		s.Put(buildLogEnvelope(int64(time.Second), "a", "other-payload"), "a")
+		s.Put(buildLogEnvelope(int64(2*time.Second), "a", "some-payload"), "a")
+
+		// Duplicates neither evict envelopes nor get stored.
+		s.Put(buildLogEnvelope(int64(time.Second), "a", "some-payload"), "a")
+		s.Put(buildLogEnvelope(int64(time.Second), "a", "other-payload"), "a")
+
+		dup := buildLogEnvelope(int64(time.Second), "a", "some-payload")
+		dup.InstanceId = "1"
+		s.Put(dup, "b")
+
+		start := time.Unix(0, 0)
+		end := time.Unix(10, 0)
+		envelopes := s.Get("a", start, end, store.ReadOptions{Limit: 10})
+		Expect(envelopes).To(HaveLen(3))
+		Expect(string(envelopes[0].GetLog().GetPayload())).To(Equal("some-payload"))
+		Expect(string(envelopes[1].GetLog().GetPayload())).To(Equal("other-payload"))
+		Expect(envelopes[2].GetTimestamp()).To(Equal(int64(2 * time.Second)))
+
+		Expect(s.Get("b", start, end, store.ReadOptions{Limit: 10})).To(HaveLen(1))
+		Expect(sm.GetMetricValue("log_cache_duplicates_dropped", nil)).To(Equal(2.0))
+		Expect(sm.GetMetricValue("log_cache_expired", nil)).To(Equal(0.0))
+	})
+
+	It("keeps duplicate envelopes outside of the deduplication window", func() {
+		s = store.NewStore(10, sp, sm, store.WithStorageEngine(engine), store.WithDeduplication(time.Second))
+		s.Put(buildLogEnvelope(int64(time.Second), "a", "some-payload"), "a")
+		s.Put(buildLogEnvelope(int64(5*time.Second), "a", "some-payload"), "a")
+		s.Put(buildLogEnvelope(int64(time.Second), "a", "some-payload"), "a")
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(10, 0), store.ReadOptions{Limit: 10})
+		Expect(envelopes).To(HaveLen(3))
+		Expect(sm.GetMetricValue("log_cache_duplicates_dropped", nil)).To(Equal(0.0))
+	})
+
 	It("is thread safe", func() {
 		var wg sync.WaitGroup
 		wg.Add(2)
@@ -308,19 +675,77 @@ var _ = Describe("Store", func() {
 		start := time.Unix(0, 0)
 		end := time.Unix(9999, 0)
 
-		Eventually(func() int { return len(s.Get("a", start, end, nil, nil, 10, false)) }).Should(Equal(1))
+		Eventually(func() int { return len(s.Get("a", start, end, store.ReadOptions{Limit: 10})) }).Should(Equal(1))
 	})
 
 	It("survives being over pruned", func() {
-		s = store.NewStore(10, sp, sm)
+		s = store.NewStore(10, sp, sm, store.WithStorageEngine(engine))
 		e1 := buildTypedEnvelope(0, "b", &loggregator_v2.Log{})
 		s.Put(e1, e1.GetSourceId())
 		sp.SetNumberToPrune(1000)
 		Expect(func() { s.Put(e1, e1.GetSourceId()) }).ToNot(Panic())
 	})
 
+	It("passes new envelopes for the source ID to subscribers", func() {
+		s.Put(buildEnvelope(1, "a"), "a")
+
+		var mu sync.Mutex
+		var received []*loggregator_v2.Envelope
+		unsubscribe := s.Subscribe("a", nil, nil, func(e *loggregator_v2.Envelope) {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, e)
+		})
+
+		e1 := buildEnvelope(2, "a")
+		s.Put(e1, "a")
+		s.Put(buildEnvelope(3, "b"), "b")
+
+		unsubscribe()
+		s.Put(buildEnvelope(4, "a"), "a")
+
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(received).To(ConsistOf(e1))
+	})
+
+	It("filters envelopes for subscribers by type and name", func() {
+		var received []*loggregator_v2.Envelope
+		unsubscribe := s.Subscribe(
+			"source-id",
+			[]logcache_v1.EnvelopeType{logcache_v1.EnvelopeType_COUNTER},
+			regexp.MustCompile("^cpu$"),
+			func(e *loggregator_v2.Envelope) {
+				received = append(received, e)
+			},
+		)
+		defer unsubscribe()
+
+		e1 := buildTypedEnvelopeWithName(1, "cpu", &loggregator_v2.Counter{})
+		s.Put(e1, "source-id")
+		s.Put(buildTypedEnvelopeWithName(2, "memory", &loggregator_v2.Counter{}), "source-id")
+		s.Put(buildTypedEnvelopeWithName(3, "cpu", &loggregator_v2.Timer{}), "source-id")
+
+		Expect(received).To(ConsistOf(e1))
+	})
+
+	It("supports multiple subscribers for the same source ID", func() {
+		var first, second int
+		unsubscribeFirst := s.Subscribe("a", nil, nil, func(*loggregator_v2.Envelope) { first++ })
+		unsubscribeSecond := s.Subscribe("a", nil, nil, func(*loggregator_v2.Envelope) { second++ })
+		defer unsubscribeSecond()
+
+		s.Put(buildEnvelope(1, "a"), "a")
+		unsubscribeFirst()
+		unsubscribeFirst()
+		s.Put(buildEnvelope(2, "a"), "a")
+
+		Expect(first).To(Equal(1))
+		Expect(second).To(Equal(2))
+	})
+
 	It("truncates older envelopes when max size is reached", func() {
-		s = store.NewStore(10, sp, sm)
+		s = store.NewStore(10, sp, sm, store.WithStorageEngine(engine))
 		// e1 should be truncated and sourceID "b" should be forgotten.
 		e1 := buildTypedEnvelope(1, "b", &loggregator_v2.Log{})
 		// e2 should be truncated.
@@ -354,7 +779,7 @@ var _ = Describe("Store", func() {
 
 		start := time.Unix(0, 0)
 		end := time.Unix(0, 9999)
-		envelopes := s.Get("a", start, end, nil, nil, 10, false)
+		envelopes := s.Get("a", start, end, store.ReadOptions{Limit: 10})
 		Expect(envelopes).To(HaveLen(5))
 
 		for _, e := range envelopes {
@@ -375,8 +800,39 @@ var _ = Describe("Store", func() {
 		}
 	})
 
+	It("prunes envelopes from the eviction class that exceeds its share the most", func() {
+		policy := store.NewEvictionPolicy(1, store.EvictionClass{Name: "system", SourceID: "quiet", Weight: 2})
+		s = store.NewStore(100, sp, sm, store.WithStorageEngine(engine), store.WithEvictionPolicy(policy))
+		for i := int64(0); i < 10; i++ {
+			s.Put(buildEnvelope(i, "quiet"), "quiet")
+		}
+		for i := int64(100); i < 130; i++ {
+			s.Put(buildEnvelope(i, "chatty"), "chatty")
+		}
+
+		s.WaitForTruncationToComplete()
+		sp.SetNumberToPrune(28)
+		s.WaitForTruncationToComplete()
+		sp.SetNumberToPrune(0)
+
+		// The remaining 12 envelopes are split 2:1 by weight.
+		start := time.Unix(0, 0)
+		end := time.Unix(0, 9999)
+		envelopes := s.Get("quiet", start, end, store.ReadOptions{Limit: 100})
+		Expect(envelopes).To(HaveLen(8))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(2)))
+
+		envelopes = s.Get("chatty", start, end, store.ReadOptions{Limit: 100})
+		Expect(envelopes).To(HaveLen(4))
+		Expect(envelopes[0].GetTimestamp()).To(Equal(int64(126)))
+
+		Expect(sm.GetMetricValue("log_cache_expired", nil)).To(Equal(28.0))
+		Expect(sm.GetMetricValue("log_cache_class_expired", map[string]string{"class": "system"})).To(Equal(2.0))
+		Expect(sm.GetMetricValue("log_cache_class_expired", map[string]string{"class": "default"})).To(Equal(26.0))
+	})
+
 	It("truncates envelopes for a specific source-id if its max size is reached", func() {
-		s = store.NewStore(2, sp, sm)
+		s = store.NewStore(2, sp, sm, store.WithStorageEngine(engine))
 		// e1 should not be truncated
 		e1 := buildTypedEnvelope(1, "b", &loggregator_v2.Log{})
 		// e2 should be truncated
@@ -391,12 +847,12 @@ var _ = Describe("Store", func() {
 
 		start := time.Unix(0, 0)
 		end := time.Unix(0, 9999)
-		envelopes := s.Get("a", start, end, nil, nil, 10, false)
+		envelopes := s.Get("a", start, end, store.ReadOptions{Limit: 10})
 		Expect(envelopes).To(HaveLen(2))
 		Expect(envelopes[0].Timestamp).To(Equal(int64(3)))
 		Expect(envelopes[1].Timestamp).To(Equal(int64(4)))
 
-		envelopes = s.Get("b", start, end, nil, nil, 10, false)
+		envelopes = s.Get("b", start, end, store.ReadOptions{Limit: 10})
 		Expect(envelopes).To(HaveLen(1))
 
 		Eventually(func() float64{
@@ -413,19 +869,32 @@ var _ = Describe("Store", func() {
 	// })
 
 	It("uses the given index", func() {
-		s = store.NewStore(2, sp, sm)
+		s = store.NewStore(2, sp, sm, store.WithStorageEngine(engine))
 		e := buildTypedEnvelope(0, "a", &loggregator_v2.Log{})
 		s.Put(e, "some-id")
 
 		start := time.Unix(0, 0)
 		end := time.Unix(0, 9999)
 
-		envelopes := s.Get("some-id", start, end, nil, nil, 10, false)
+		envelopes := s.Get("some-id", start, end, store.ReadOptions{Limit: 10})
 		Expect(envelopes).To(HaveLen(1))
 	})
 
+	It("returns the timestamp of the oldest envelope", func() {
+		s = store.NewStore(5, sp, sm, store.WithStorageEngine(engine))
+		_, ok := s.OldestTimestamp()
+		Expect(ok).To(BeFalse())
+
+		s.Put(buildTypedEnvelope(10, "a", &loggregator_v2.Log{}), "a")
+		s.Put(buildTypedEnvelope(5, "b", &loggregator_v2.Log{}), "b")
+
+		oldest, ok := s.OldestTimestamp()
+		Expect(ok).To(BeTrue())
+		Expect(oldest).To(Equal(int64(5)))
+	})
+
 	It("returns the indices in the store", func() {
-		s = store.NewStore(2, sp, sm)
+		s = store.NewStore(2, sp, sm, store.WithStorageEngine(engine))
 
 		// Will be pruned by pruner
 		s.Put(buildTypedEnvelope(1, "index-0", &loggregator_v2.Log{}), "index-0")
@@ -456,6 +925,9 @@ var _ = Describe("Store", func() {
 			Expired:         1,
 			OldestTimestamp: 6,
 			NewestTimestamp: 6,
+			LogCount:        1,
+			Bytes:           int64(proto.Size(buildTypedEnvelope(6, "index-1", &loggregator_v2.Log{}))),
+			Rate:            2.0 / 60,
 		}))
 
 		Expect(meta).To(HaveKeyWithValue("index-2", logcache_v1.MetaInfo{
@@ -463,11 +935,109 @@ var _ = Describe("Store", func() {
 			Expired:         1,
 			OldestTimestamp: 4,
 			NewestTimestamp: 5,
+			LogCount:        2,
+			Bytes:           2 * int64(proto.Size(buildTypedEnvelope(4, "index-2", &loggregator_v2.Log{}))),
+			Rate:            3.0 / 60,
 		}))
 	})
 
+	It("keeps the per-type counts and bytes through eviction", func() {
+		s = store.NewStore(4, sp, sm, store.WithStorageEngine(engine))
+		envelopes := []*loggregator_v2.Envelope{
+			buildLogEnvelope(1, "a", "some-long-payload-that-takes-up-space"),
+			buildTypedEnvelope(2, "a", &loggregator_v2.Counter{}),
+			buildTypedEnvelope(3, "a", &loggregator_v2.Gauge{}),
+			buildTypedEnvelope(4, "a", &loggregator_v2.Timer{}),
+			buildTypedEnvelope(5, "a", &loggregator_v2.Event{}),
+			buildTypedEnvelope(6, "a", &loggregator_v2.Counter{}),
+		}
+		for _, e := range envelopes {
+			s.Put(e, "a")
+		}
+
+		// The log and the first counter were evicted to stay at 4.
+		var bytes int64
+		for _, e := range envelopes[2:] {
+			bytes += int64(proto.Size(e))
+		}
+
+		meta := s.Meta()["a"]
+		Expect(meta.Count).To(Equal(int64(4)))
+		Expect(meta.LogCount).To(BeZero())
+		Expect(meta.CounterCount).To(Equal(int64(1)))
+		Expect(meta.GaugeCount).To(Equal(int64(1)))
+		Expect(meta.TimerCount).To(Equal(int64(1)))
+		Expect(meta.EventCount).To(Equal(int64(1)))
+		Expect(meta.Bytes).To(Equal(bytes))
+		Expect(meta.Rate).To(Equal(6.0 / 60))
+
+		s.WaitForTruncationToComplete()
+		sp.SetNumberToPrune(2)
+		s.WaitForTruncationToComplete()
+		sp.SetNumberToPrune(0)
+
+		meta = s.Meta()["a"]
+		Expect(meta.Count).To(Equal(int64(2)))
+		Expect(meta.GaugeCount).To(BeZero())
+		Expect(meta.TimerCount).To(BeZero())
+		Expect(meta.Bytes).To(Equal(int64(proto.Size(envelopes[4]) + proto.Size(envelopes[5]))))
+	})
+
+	It("purges the envelopes of a source ID within a time range", func() {
+		s.Put(buildTypedEnvelope(1, "a", &loggregator_v2.Log{}), "a")
+		s.Put(buildTypedEnvelope(2, "a", &loggregator_v2.Counter{Name: "some-name"}), "a")
+		s.Put(buildTypedEnvelope(3, "a", &loggregator_v2.Log{}), "a")
+		s.Put(buildTypedEnvelope(4, "a", &loggregator_v2.Gauge{}), "a")
+		s.Put(buildTypedEnvelope(2, "b", &loggregator_v2.Log{}), "b")
+
+		purged, err := s.Purge("a", time.Unix(0, 2), time.Unix(0, 4))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(purged).To(Equal(2))
+
+		envelopes := s.Get("a", time.Unix(0, 0), time.Unix(0, 10), store.ReadOptions{Limit: 10})
+		Expect(envelopes).To(HaveLen(2))
+		Expect(envelopes[0].Timestamp).To(Equal(int64(1)))
+		Expect(envelopes[1].Timestamp).To(Equal(int64(4)))
+
+		envelopes = s.Get("a", time.Unix(0, 0), time.Unix(0, 10), store.ReadOptions{NameFilter: regexp.MustCompile("some-name"), Limit: 10})
+		Expect(envelopes).To(BeEmpty())
+
+		meta := s.Meta()["a"]
+		Expect(meta.Count).To(Equal(int64(2)))
+		Expect(meta.Expired).To(BeZero())
+		Expect(meta.LogCount).To(Equal(int64(1)))
+		Expect(meta.CounterCount).To(BeZero())
+		Expect(meta.GaugeCount).To(Equal(int64(1)))
+		Expect(meta.OldestTimestamp).To(Equal(int64(1)))
+		Expect(meta.NewestTimestamp).To(Equal(int64(4)))
+
+		Expect(s.Meta()["b"].Count).To(Equal(int64(1)))
+		Expect(sm.GetMetricValue("log_cache_store_size", map[string]string{"unit": "entries"})).To(Equal(3.0))
+		Expect(sm.GetMetricValue("log_cache_purged", nil)).To(Equal(2.0))
+		Expect(sm.GetMetricValue("log_cache_expired", nil)).To(BeZero())
+	})
+
+	It("removes the source ID once every envelope is purged", func() {
+		s.Put(buildEnvelope(1, "a"), "a")
+		s.Put(buildEnvelope(2, "a"), "a")
+		s.Put(buildEnvelope(1, "b"), "b")
+
+		purged, err := s.Purge("a", time.Unix(0, 0), time.Unix(0, math.MaxInt64))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(purged).To(Equal(2))
+		Expect(s.Meta()).ToNot(HaveKey("a"))
+
+		purged, err = s.Purge("unknown", time.Unix(0, 0), time.Unix(0, math.MaxInt64))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(purged).To(BeZero())
+
+		s.Put(buildEnvelope(3, "a"), "a")
+		Expect(s.Meta()["a"].Count).To(Equal(int64(1)))
+		Expect(sm.GetMetricValue("log_cache_store_size", map[string]string{"unit": "entries"})).To(Equal(2.0))
+	})
+
 	It("survives the just added entry from being pruned", func() {
-		s = store.NewStore(2, sp, sm)
+		s = store.NewStore(2, sp, sm, store.WithStorageEngine(engine))
 
 		s.Put(buildTypedEnvelope(2, "index-0", &loggregator_v2.Log{}), "index-0")
 		s.Put(buildTypedEnvelope(3, "index-0", &loggregator_v2.Log{}), "index-0")
@@ -480,13 +1050,58 @@ var _ = Describe("Store", func() {
 		Expect(s.Meta()).ToNot(HaveKey("index-1"))
 	})
 
+	It("expires envelopes older than the retention policy allows", func() {
+		policy := store.NewRetentionPolicy(
+			10*time.Minute,
+			store.RetentionRule{SourceID: "tenant", MaxAge: 2 * time.Hour},
+		)
+		s = store.NewStore(10, sp, sm, store.WithStorageEngine(engine), store.WithRetentionPolicy(policy))
+
+		old := time.Now().Add(-time.Hour).UnixNano()
+		recent := time.Now().UnixNano()
+
+		s.Put(buildTypedEnvelope(old, "platform", &loggregator_v2.Log{}), "platform")
+		s.Put(buildTypedEnvelope(recent, "platform", &loggregator_v2.Log{}), "platform")
+		s.Put(buildTypedEnvelope(old, "tenant", &loggregator_v2.Log{}), "tenant")
+		s.Put(buildTypedEnvelope(old, "expired", &loggregator_v2.Log{}), "expired")
+
+		s.WaitForTruncationToComplete()
+
+		meta := s.Meta()
+		Expect(meta).ToNot(HaveKey("expired"))
+		Expect(meta).To(HaveKeyWithValue("platform", logcache_v1.MetaInfo{
+			Count:           1,
+			Expired:         1,
+			OldestTimestamp: recent,
+			NewestTimestamp: recent,
+			LogCount:        1,
+			Bytes:           int64(proto.Size(buildTypedEnvelope(recent, "platform", &loggregator_v2.Log{}))),
+			Rate:            2.0 / 60,
+		}))
+		Expect(meta["tenant"].Count).To(Equal(int64(1)))
+
+		Expect(sm.GetMetricValue("log_cache_expired", nil)).To(Equal(2.0))
+		Expect(sm.GetMetricValue("log_cache_store_size", map[string]string{"unit": "entries"})).To(Equal(2.0))
+		Expect(sm.GetMetricValue("log_cache_cache_period", map[string]string{"unit": "milliseconds"})).To(
+			BeNumerically("~", float64(time.Hour/time.Millisecond), 5000),
+		)
+	})
+
+	It("does not expire envelopes by age without a retention policy", func() {
+		s.Put(buildTypedEnvelope(time.Now().Add(-24*time.Hour).UnixNano(), "a", &loggregator_v2.Log{}), "a")
+
+		s.WaitForTruncationToComplete()
+
+		Expect(s.Meta()).To(HaveKey("a"))
+	})
+
 	// TODO: This is probably duplicated in the store_load_test, but it was
 	// really useful in driving out races. We'd like to leave this in place
 	// until we have a high level of confidence that we're catching races
 	It("demonstrates thread safety under heavy concurrent load", func() {
 		sp := newSpyPruner()
 		sp.SetNumberToPrune(10)
-		loadStore := store.NewStore(10000, sp, sm)
+		loadStore := store.NewStore(10000, sp, sm, store.WithStorageEngine(engine))
 		start := time.Now()
 
 		for i := 0; i < 10; i++ {
@@ -502,12 +1117,12 @@ var _ = Describe("Store", func() {
 		}
 
 		Consistently(func() int64 {
-			envelopes := loadStore.Get("9", start, time.Now(), nil, nil, 100000, false)
+			envelopes := loadStore.Get("9", start, time.Now(), store.ReadOptions{Limit: 100000})
 			time.Sleep(500 * time.Millisecond)
 			return int64(len(envelopes))
 		}).Should(BeNumerically("<=", 10000))
 	})
-})
+}
 
 func buildEnvelope(timestamp int64, sourceID string) *loggregator_v2.Envelope {
 	return &loggregator_v2.Envelope{
@@ -516,6 +1131,24 @@ func buildEnvelope(timestamp int64, sourceID string) *loggregator_v2.Envelope {
 	}
 }
 
+func mustParseTagMatcher(s string) store.TagMatcher {
+	m, err := store.ParseTagMatcher(s)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func buildLogEnvelope(timestamp int64, sourceID, payload string) *loggregator_v2.Envelope {
+	return &loggregator_v2.Envelope{
+		Timestamp: timestamp,
+		SourceId:  sourceID,
+		Message: &loggregator_v2.Envelope_Log{
+			Log: &loggregator_v2.Log{Payload: []byte(payload)},
+		},
+	}
+}
+
 func buildTypedEnvelope(timestamp int64, sourceID string, t interface{}) *loggregator_v2.Envelope {
 	e := &loggregator_v2.Envelope{
 		Timestamp: timestamp,
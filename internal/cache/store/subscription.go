@@ -0,0 +1,85 @@
+package store
+
+import (
+	"regexp"
+	"sync/atomic"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+)
+
+type subscription struct {
+	envelopeTypes []logcache_v1.EnvelopeType
+	nameFilter    *regexp.Regexp
+	f             func(e *loggregator_v2.Envelope)
+}
+
+// Subscribe invokes f with every envelope that is Put for the given source
+// ID from now on. Envelopes are filtered by type and name the same way Get
+// filters them. f is invoked synchronously by Put and therefore must not
+// block. Invoking the returned function ends the subscription.
+func (store *Store) Subscribe(
+	sourceID string,
+	envelopeTypes []logcache_v1.EnvelopeType,
+	nameFilter *regexp.Regexp,
+	f func(e *loggregator_v2.Envelope),
+) (unsubscribe func()) {
+	sub := &subscription{
+		envelopeTypes: envelopeTypes,
+		nameFilter:    nameFilter,
+		f:             f,
+	}
+
+	store.subscriptionsMu.Lock()
+	subs := store.subscriptions[sourceID]
+	store.subscriptions[sourceID] = append(subs[:len(subs):len(subs)], sub)
+	atomic.AddInt64(&store.subscriptionCount, 1)
+	store.subscriptionsMu.Unlock()
+
+	var once int32
+	return func() {
+		if !atomic.CompareAndSwapInt32(&once, 0, 1) {
+			return
+		}
+
+		store.subscriptionsMu.Lock()
+		defer store.subscriptionsMu.Unlock()
+
+		subs := store.subscriptions[sourceID]
+		for i, s := range subs {
+			if s != sub {
+				continue
+			}
+
+			remaining := make([]*subscription, 0, len(subs)-1)
+			remaining = append(remaining, subs[:i]...)
+			remaining = append(remaining, subs[i+1:]...)
+			if len(remaining) == 0 {
+				delete(store.subscriptions, sourceID)
+			} else {
+				store.subscriptions[sourceID] = remaining
+			}
+			break
+		}
+		atomic.AddInt64(&store.subscriptionCount, -1)
+	}
+}
+
+func (store *Store) publish(e *loggregator_v2.Envelope, sourceID string) {
+	if atomic.LoadInt64(&store.subscriptionCount) == 0 {
+		return
+	}
+
+	store.subscriptionsMu.RLock()
+	subs := store.subscriptions[sourceID]
+	store.subscriptionsMu.RUnlock()
+
+	for _, sub := range subs {
+		filtered := store.filterByName(e, sub.nameFilter)
+		if filtered == nil || !store.validEnvelopeType(filtered, sub.envelopeTypes) {
+			continue
+		}
+
+		sub.f(filtered)
+	}
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+)
+
+// MatchType determines how a TagMatcher compares the value of a tag.
+type MatchType int
+
+const (
+	MatchEqual MatchType = iota
+	MatchNotEqual
+	MatchRegexp
+	MatchNotRegexp
+)
+
+func (t MatchType) String() string {
+	switch t {
+	case MatchEqual:
+		return "="
+	case MatchNotEqual:
+		return "!="
+	case MatchRegexp:
+		return "=~"
+	case MatchNotRegexp:
+		return "!~"
+	default:
+		return "unknown"
+	}
+}
+
+// TagMatcher matches envelopes on the value of a single tag. A tag that is
+// not set on an envelope is treated as an empty value. Regular expressions
+// are anchored on both ends.
+type TagMatcher struct {
+	Name  string
+	Type  MatchType
+	Value string
+
+	re *regexp.Regexp
+}
+
+// NewTagMatcher returns a TagMatcher. It returns an error if the name is
+// empty or the value is not a valid regular expression for a regex match.
+func NewTagMatcher(t MatchType, name, value string) (TagMatcher, error) {
+	if name == "" {
+		return TagMatcher{}, fmt.Errorf("tag matcher requires a tag name")
+	}
+
+	m := TagMatcher{
+		Name:  name,
+		Type:  t,
+		Value: value,
+	}
+
+	switch t {
+	case MatchEqual, MatchNotEqual:
+	case MatchRegexp, MatchNotRegexp:
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return TagMatcher{}, err
+		}
+		m.re = re
+	default:
+		return TagMatcher{}, fmt.Errorf("unknown match type %d", t)
+	}
+
+	return m, nil
+}
+
+// ParseTagMatcher parses a matcher of the form <name><op><value> where op is
+// one of =, !=, =~ or !~, e.g. deployment=cf or job=~diego.*.
+func ParseTagMatcher(s string) (TagMatcher, error) {
+	for i := 0; i < len(s); i++ {
+		var t MatchType
+		var opLen int
+
+		switch {
+		case s[i] == '=' && i+1 < len(s) && s[i+1] == '~':
+			t, opLen = MatchRegexp, 2
+		case s[i] == '=':
+			t, opLen = MatchEqual, 1
+		case s[i] == '!' && i+1 < len(s) && s[i+1] == '=':
+			t, opLen = MatchNotEqual, 2
+		case s[i] == '!' && i+1 < len(s) && s[i+1] == '~':
+			t, opLen = MatchNotRegexp, 2
+		default:
+			continue
+		}
+
+		m, err := NewTagMatcher(t, s[:i], s[i+opLen:])
+		if err != nil {
+			return TagMatcher{}, fmt.Errorf("invalid tag matcher %q: %s", s, err)
+		}
+
+		return m, nil
+	}
+
+	return TagMatcher{}, fmt.Errorf("invalid tag matcher %q: missing operator", s)
+}
+
+// Matches returns true if the envelope's tag satisfies the matcher.
+func (m TagMatcher) Matches(e *loggregator_v2.Envelope) bool {
+	value := tagValue(e, m.Name)
+
+	switch m.Type {
+	case MatchEqual:
+		return value == m.Value
+	case MatchNotEqual:
+		return value != m.Value
+	case MatchRegexp:
+		return m.re.MatchString(value)
+	case MatchNotRegexp:
+		return !m.re.MatchString(value)
+	default:
+		return false
+	}
+}
+
+func (m TagMatcher) String() string {
+	return m.Name + m.Type.String() + m.Value
+}
+
+func tagValue(e *loggregator_v2.Envelope, name string) string {
+	if v, ok := e.GetTags()[name]; ok {
+		return v
+	}
+
+	return e.GetDeprecatedTags()[name].GetText()
+}
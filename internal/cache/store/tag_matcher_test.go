@@ -0,0 +1,68 @@
+package store_test
+
+import (
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/internal/cache/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TagMatcher", func() {
+	var e *loggregator_v2.Envelope
+
+	BeforeEach(func() {
+		e = &loggregator_v2.Envelope{
+			Tags: map[string]string{
+				"deployment": "cf",
+				"job":        "diego-cell",
+			},
+			DeprecatedTags: map[string]*loggregator_v2.Value{
+				"ip": {Data: &loggregator_v2.Value_Text{Text: "10.0.0.1"}},
+			},
+		}
+	})
+
+	DescribeTable("matching envelopes", func(matcher string, expected bool) {
+		m, err := store.ParseTagMatcher(matcher)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m.Matches(e)).To(Equal(expected))
+	},
+		Entry("equal", "deployment=cf", true),
+		Entry("equal mismatch", "deployment=other", false),
+		Entry("not equal", "deployment!=other", true),
+		Entry("not equal mismatch", "deployment!=cf", false),
+		Entry("regex", "job=~diego-.*", true),
+		Entry("regex is anchored", "job=~diego", false),
+		Entry("not regex", "job!~router.*", true),
+		Entry("not regex mismatch", "job!~diego-.*", false),
+		Entry("missing tag equals empty", "az=", true),
+		Entry("missing tag not equal", "az!=z1", true),
+		Entry("deprecated tags", "ip=10.0.0.1", true),
+		Entry("value containing an operator", "deployment!=a=b", true),
+	)
+
+	DescribeTable("parsing", func(matcher, name string, t store.MatchType, value string) {
+		m, err := store.ParseTagMatcher(matcher)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(m.Name).To(Equal(name))
+		Expect(m.Type).To(Equal(t))
+		Expect(m.Value).To(Equal(value))
+		Expect(m.String()).To(Equal(matcher))
+	},
+		Entry("=", "a=b", "a", store.MatchEqual, "b"),
+		Entry("!=", "a!=b", "a", store.MatchNotEqual, "b"),
+		Entry("=~", "a=~b.*", "a", store.MatchRegexp, "b.*"),
+		Entry("!~", "a!~b.*", "a", store.MatchNotRegexp, "b.*"),
+	)
+
+	DescribeTable("invalid matchers", func(matcher string) {
+		_, err := store.ParseTagMatcher(matcher)
+		Expect(err).To(HaveOccurred())
+	},
+		Entry("missing operator", "deployment"),
+		Entry("missing name", "=cf"),
+		Entry("invalid regex", "job=~("),
+	)
+})
@@ -0,0 +1,63 @@
+package store
+
+import (
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+)
+
+// typeIndex maps each envelope type to the timestamps it was seen at. It
+// allows reads of some envelope types to only visit the envelopes that can
+// match, e.g. to read the logs of a source ID that mostly holds gauges.
+//
+// Like the nameIndex, it is only ever a superset of the stored envelopes.
+type typeIndex struct {
+	timestamps map[logcache_v1.EnvelopeType][]int64
+}
+
+func newTypeIndex() *typeIndex {
+	return &typeIndex{
+		timestamps: make(map[logcache_v1.EnvelopeType][]int64),
+	}
+}
+
+// add records the type of the envelope.
+func (idx *typeIndex) add(e *loggregator_v2.Envelope) {
+	t := envelopeKind(e)
+	idx.timestamps[t] = insertTimestamp(idx.timestamps[t], e.GetTimestamp())
+}
+
+// prune drops every timestamp that is older than oldest.
+func (idx *typeIndex) prune(oldest int64) {
+	for t, timestamps := range idx.timestamps {
+		timestamps = pruneTimestamps(timestamps, oldest)
+		if len(timestamps) == 0 {
+			delete(idx.timestamps, t)
+			continue
+		}
+
+		idx.timestamps[t] = timestamps
+	}
+}
+
+// lookup returns the timestamps within [start..end) of every given type.
+// Each list is in ascending order and may share timestamps with the others.
+// It returns false if there are no types or they include ANY, as then every
+// envelope has to be visited anyway.
+func (idx *typeIndex) lookup(types []logcache_v1.EnvelopeType, start, end int64) ([][]int64, bool) {
+	if len(types) == 0 {
+		return nil, false
+	}
+
+	lists := make([][]int64, 0, len(types))
+	for _, t := range types {
+		if t == logcache_v1.EnvelopeType_ANY {
+			return nil, false
+		}
+
+		if timestamps := timestampsWithin(idx.timestamps[t], start, end); len(timestamps) > 0 {
+			lists = append(lists, timestamps)
+		}
+	}
+
+	return lists, true
+}
@@ -113,6 +113,10 @@ func (p *CFAuthProxy) Addr() string {
 func (p *CFAuthProxy) reverseProxy() *httputil.ReverseProxy {
 	proxy := httputil.NewSingleHostReverseProxy(p.gatewayURL)
 	proxy.Transport = NewTransportWithRootCA(p.proxyCACertPool)
+
+	// Flush periodically so that streamed responses (e.g. tail) are not
+	// held back.
+	proxy.FlushInterval = 100 * time.Millisecond
 	return proxy
 }
 
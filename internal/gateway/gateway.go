@@ -7,6 +7,7 @@ import (
 	"log"
 	"net"
 	"net/http"
+	"strings"
 
 	"github.com/grpc-ecosystem/grpc-gateway/runtime"
 	"github.com/shirou/gopsutil/host"
@@ -144,6 +145,15 @@ func (g *Gateway) listenAndServe() {
 		g.log.Fatalf("failed to register LogCache handler: %s", err)
 	}
 
+	err = logcache_v1.RegisterAdminHandlerClient(
+		context.Background(),
+		mux,
+		logcache_v1.NewAdminClient(conn),
+	)
+	if err != nil {
+		g.log.Fatalf("failed to register Admin handler: %s", err)
+	}
+
 	err = logcache_v1.RegisterPromQLQuerierHandlerClient(
 		context.Background(),
 		mux,
@@ -186,7 +196,7 @@ func (g *Gateway) httpErrorHandler(
 	r *http.Request,
 	err error,
 ) {
-	if r.URL.Path != "/api/v1/query" && r.URL.Path != "/api/v1/query_range" {
+	if !isPromQLPath(r.URL.Path) {
 		runtime.DefaultHTTPError(ctx, mux, marshaler, w, r, err)
 		return
 	}
@@ -217,3 +227,14 @@ func (g *Gateway) httpErrorHandler(
 		g.log.Printf("Failed to write response: %v", err)
 	}
 }
+
+// isPromQLPath reports whether the path is one of the Prometheus compatible
+// endpoints, whose errors have to match the Prometheus API.
+func isPromQLPath(path string) bool {
+	switch path {
+	case "/api/v1/query", "/api/v1/query_range", "/api/v1/labels", "/api/v1/series":
+		return true
+	}
+
+	return strings.HasPrefix(path, "/api/v1/label/") && strings.HasSuffix(path, "/values")
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"strings"
 
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
 	. "code.cloudfoundry.org/log-cache/internal/gateway"
 	rpc "code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
 	"google.golang.org/grpc"
@@ -71,6 +72,78 @@ var _ = Describe("Gateway", func() {
 		Entry("with dash", "some-source-id", "some-source-id"),
 	)
 
+	It("passes the payload filter through to the log cache", func() {
+		path := "api/v1/read/some-source-id?payload_filter=status%3D5..&payload_filter_regex=true&payload_filter_case_insensitive=true"
+		URL := fmt.Sprintf("%s/%s", gw.Addr(), path)
+		resp, err := makeTLSReq("https", URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		reqs := spyLogCache.GetReadRequests()
+		Expect(reqs).To(HaveLen(1))
+		Expect(reqs[0].PayloadFilter).To(Equal("status=5.."))
+		Expect(reqs[0].PayloadFilterRegex).To(BeTrue())
+		Expect(reqs[0].PayloadFilterCaseInsensitive).To(BeTrue())
+	})
+
+	It("passes the instance ID and tag matchers through to the log cache", func() {
+		path := "api/v1/read/some-source-id?instance_id=3&tag_matchers=deployment%3Dcf&tag_matchers=job%3D~diego.*"
+		URL := fmt.Sprintf("%s/%s", gw.Addr(), path)
+		resp, err := makeTLSReq("https", URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		reqs := spyLogCache.GetReadRequests()
+		Expect(reqs).To(HaveLen(1))
+		Expect(reqs[0].InstanceId).To(Equal("3"))
+		Expect(reqs[0].TagMatchers).To(Equal([]string{"deployment=cf", "job=~diego.*"}))
+	})
+
+	It("upgrades HTTPS DELETE requests for purges into gRPC requests", func() {
+		path := "api/v1/purge/some-source/id?start_time=99&end_time=101"
+		URL := fmt.Sprintf("%s/%s", gw.Addr(), path)
+		resp, err := makeTLSReqWithMethod(http.MethodDelete, "https", URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		reqs := spyLogCache.GetPurgeRequests()
+		Expect(reqs).To(HaveLen(1))
+		Expect(reqs[0].SourceId).To(Equal("some-source/id"))
+		Expect(reqs[0].StartTime).To(Equal(int64(99)))
+		Expect(reqs[0].EndTime).To(Equal(int64(101)))
+	})
+
+	It("streams tail responses from the log cache", func() {
+		spyLogCache.TailEnvelopes["some-source-id"] = []*loggregator_v2.Envelope{
+			{Timestamp: 1, SourceId: "some-source-id"},
+			{Timestamp: 2, SourceId: "some-source-id"},
+		}
+
+		path := "api/v1/tail/some-source-id?envelope_types=LOG&name_filter=cpu.*"
+		URL := fmt.Sprintf("%s/%s", gw.Addr(), path)
+		resp, err := makeTLSReq("https", URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		body, err := ioutil.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+
+		var chunks []string
+		for _, line := range strings.Split(string(body), "\n") {
+			if strings.TrimSpace(line) != "" {
+				chunks = append(chunks, line)
+			}
+		}
+		Expect(chunks).To(HaveLen(2))
+		Expect(chunks[0]).To(MatchJSON(`{"result":{"envelopes":{"batch":[{"timestamp":"1","source_id":"some-source-id","instance_id":"","deprecated_tags":{},"tags":{}}]}}}`))
+
+		reqs := spyLogCache.GetTailRequests()
+		Expect(reqs).To(HaveLen(1))
+		Expect(reqs[0].SourceId).To(Equal("some-source-id"))
+		Expect(reqs[0].EnvelopeTypes).To(ConsistOf(rpc.EnvelopeType_LOG))
+		Expect(reqs[0].NameFilter).To(Equal("cpu.*"))
+	})
+
 	It("adds newlines to the end of HTTPS responses", func() {
 		path := `api/v1/meta`
 		URL := fmt.Sprintf("%s/%s", gw.Addr(), path)
@@ -110,6 +183,49 @@ var _ = Describe("Gateway", func() {
 		Expect(reqs[0].Step).To(Equal("30s"))
 	})
 
+	It("upgrades HTTPS requests for labels via PromQLQuerier GETs into gRPC requests", func() {
+		path := `api/v1/labels?match[]=metric{source_id="some-id"}&start=1234.000`
+		URL := fmt.Sprintf("%s/%s", gw.Addr(), path)
+		resp, err := makeTLSReq("https", URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		reqs := spyLogCache.GetLabelsRequests()
+		Expect(reqs).To(HaveLen(1))
+		Expect(reqs[0].Match).To(ContainElement(`metric{source_id="some-id"}`))
+		Expect(reqs[0].Start).To(Equal("1234.000"))
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		Expect(body).To(MatchJSON(`{"status":"success","data":["__name__","source_id"]}`))
+	})
+
+	It("upgrades HTTPS requests for series via PromQLQuerier GETs into gRPC requests", func() {
+		path := `api/v1/series?match[]=metric{source_id="some-id"}&end=5678.000`
+		URL := fmt.Sprintf("%s/%s", gw.Addr(), path)
+		resp, err := makeTLSReq("https", URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		reqs := spyLogCache.GetSeriesRequests()
+		Expect(reqs).To(HaveLen(1))
+		Expect(reqs[0].Match).To(ContainElement(`metric{source_id="some-id"}`))
+		Expect(reqs[0].End).To(Equal("5678.000"))
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		Expect(body).To(MatchJSON(`{"status":"success","data":[{"__name__":"test"}]}`))
+	})
+
+	It("serves label values via PromQLQuerier GETs", func() {
+		path := `api/v1/label/__name__/values?match[]=metric{source_id="some-id"}`
+		URL := fmt.Sprintf("%s/%s", gw.Addr(), path)
+		resp, err := makeTLSReq("https", URL)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		Expect(body).To(MatchJSON(`{"status":"success","data":["test"]}`))
+	})
+
 	It("outputs json with zero-value points and correct Prometheus API fields", func() {
 		path := `api/v1/query?query=metric{source_id="some-id"}&time=1234`
 		URL := fmt.Sprintf("%s/%s", gw.Addr(), path)
@@ -175,11 +291,32 @@ var _ = Describe("Gateway", func() {
 				"error": "expected error"
 			}`))
 		})
+
+		It("adds necessary fields to match Prometheus API for discovery endpoints", func() {
+			path := `api/v1/label/__name__/values?match[]=metric{source_id="some-id"}`
+			spyLogCache.QueryError = errors.New("expected error")
+			URL := fmt.Sprintf("%s/%s", gw.Addr(), path)
+
+			resp, err := makeTLSReq("https", URL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusInternalServerError))
+
+			body, _ := ioutil.ReadAll(resp.Body)
+			Expect(body).To(MatchJSON(`{
+				"status": "error",
+				"errorType": "internal",
+				"error": "expected error"
+			}`))
+		})
 	})
 })
 
 func makeTLSReq(scheme, addr string) (*http.Response, error) {
-	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s://%s", scheme, addr), nil)
+	return makeTLSReqWithMethod(http.MethodGet, scheme, addr)
+}
+
+func makeTLSReqWithMethod(method, scheme, addr string) (*http.Response, error) {
+	req, err := http.NewRequest(method, fmt.Sprintf("%s://%s", scheme, addr), nil)
 	Expect(err).ToNot(HaveOccurred())
 
 	tr := &http.Transport{
@@ -0,0 +1,165 @@
+package promql_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/internal/promql"
+	"code.cloudfoundry.org/log-cache/internal/promql/data_reader"
+	"code.cloudfoundry.org/log-cache/pkg/client"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Concurrent reads", func() {
+	var (
+		reader *blockingDataReader
+		q      *promql.PromQL
+	)
+
+	sourceIDs := func(n int) []string {
+		var ids []string
+		for i := 0; i < n; i++ {
+			ids = append(ids, fmt.Sprintf("some-id-%d", i))
+		}
+		return ids
+	}
+
+	BeforeEach(func() {
+		reader = newBlockingDataReader()
+		q = promql.New(
+			reader,
+			testhelpers.NewMetricsRegistry(),
+			log.New(ioutil.Discard, "", 0),
+			5*time.Second,
+		)
+	})
+
+	It("reads from a bounded number of source IDs at a time", func() {
+		reader.delay = 10 * time.Millisecond
+
+		r, err := q.InstantQuery(context.Background(), &logcache_v1.PromQL_InstantQueryRequest{
+			Query: fmt.Sprintf(`metric{source_id=~"%s"}`, strings.Join(sourceIDs(30), "|")),
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(r.GetVector().GetSamples()).To(HaveLen(30))
+		Expect(reader.maxInFlight()).To(BeNumerically(">", 1))
+		Expect(reader.maxInFlight()).To(BeNumerically("<=", 10))
+	})
+
+	It("reads with the context of the query", func() {
+		reader.block = true
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := q.InstantQuery(ctx, &logcache_v1.PromQL_InstantQueryRequest{
+			Query: `metric{source_id=~"some-id-1|some-id-2"}`,
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+	})
+
+	It("returns an error that names the source ID that failed", func() {
+		reader.failures = map[string]error{"some-id-7": errors.New("some-error")}
+
+		_, err := q.InstantQuery(context.Background(), &logcache_v1.PromQL_InstantQueryRequest{
+			Query: fmt.Sprintf(`metric{source_id=~"%s"}`, strings.Join(sourceIDs(20), "|")),
+		})
+		Expect(err).To(MatchError(ContainSubstring("some-id-7")))
+		Expect(err).To(MatchError(ContainSubstring("some-error")))
+	})
+
+	It("returns the error of a walking data reader whose reads fail", func() {
+		q = promql.New(
+			data_reader.NewWalkingDataReader(func(ctx context.Context, sourceID string, start time.Time, opts ...client.ReadOption) ([]*loggregator_v2.Envelope, error) {
+				if sourceID == "some-id-1" {
+					return nil, errors.New("some-error")
+				}
+				return nil, nil
+			}),
+			testhelpers.NewMetricsRegistry(),
+			log.New(ioutil.Discard, "", 0),
+			10*time.Second,
+		)
+
+		_, err := q.InstantQuery(context.Background(), &logcache_v1.PromQL_InstantQueryRequest{
+			Query: `metric{source_id=~"some-id-0|some-id-1"}`,
+		})
+		Expect(err).To(MatchError(ContainSubstring("some-id-1")))
+		Expect(err).To(MatchError(ContainSubstring("some-error")))
+	})
+})
+
+type blockingDataReader struct {
+	delay    time.Duration
+	block    bool
+	failures map[string]error
+
+	mu       sync.Mutex
+	inFlight int
+	max      int
+}
+
+func newBlockingDataReader() *blockingDataReader {
+	return &blockingDataReader{}
+}
+
+func (r *blockingDataReader) Read(ctx context.Context, req *logcache_v1.ReadRequest) (*logcache_v1.ReadResponse, error) {
+	r.mu.Lock()
+	r.inFlight++
+	if r.inFlight > r.max {
+		r.max = r.inFlight
+	}
+	r.mu.Unlock()
+
+	defer func() {
+		r.mu.Lock()
+		r.inFlight--
+		r.mu.Unlock()
+	}()
+
+	if r.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	time.Sleep(r.delay)
+
+	if err, ok := r.failures[req.GetSourceId()]; ok {
+		return nil, err
+	}
+
+	return &logcache_v1.ReadResponse{
+		Envelopes: &loggregator_v2.EnvelopeBatch{
+			Batch: []*loggregator_v2.Envelope{{
+				SourceId:  req.GetSourceId(),
+				Timestamp: time.Now().Add(-time.Second).UnixNano(),
+				Message: &loggregator_v2.Envelope_Gauge{
+					Gauge: &loggregator_v2.Gauge{
+						Metrics: map[string]*loggregator_v2.GaugeValue{
+							"metric": {Value: 1},
+						},
+					},
+				},
+			}},
+		},
+	}, nil
+}
+
+func (r *blockingDataReader) maxInFlight() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.max
+}
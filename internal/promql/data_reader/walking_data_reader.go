@@ -19,21 +19,33 @@ func NewWalkingDataReader(reader client.Reader) *WalkingDataReader {
 	}
 }
 
+// Read walks the envelopes of the request. Failed reads are retried. If the
+// last read failed, its error is returned.
 func (r *WalkingDataReader) Read(
 	ctx context.Context,
 	in *logcache_v1.ReadRequest,
 ) (*logcache_v1.ReadResponse, error) {
 
-	var result []*loggregator_v2.Envelope
+	var (
+		result  []*loggregator_v2.Envelope
+		readErr error
+	)
+
+	reader := func(ctx context.Context, sourceID string, start time.Time, opts ...client.ReadOption) ([]*loggregator_v2.Envelope, error) {
+		es, err := r.r(ctx, sourceID, start, opts...)
+		readErr = err
+		return es, err
+	}
 
 	client.Walk(ctx, in.GetSourceId(), func(es []*loggregator_v2.Envelope) bool {
 		result = append(result, es...)
 		return true
-	}, r.r,
+	}, reader,
 		client.WithWalkStartTime(time.Unix(0, in.GetStartTime())),
 		client.WithWalkEndTime(time.Unix(0, in.GetEndTime())),
 		client.WithWalkLimit(int(in.GetLimit())),
 		client.WithWalkEnvelopeTypes(in.GetEnvelopeTypes()...),
+		client.WithWalkNameFilter(in.GetNameFilter()),
 		client.WithWalkBackoff(client.NewRetryBackoffOnErr(time.Second, 5)),
 	)
 
@@ -41,6 +53,10 @@ func (r *WalkingDataReader) Read(
 		return nil, err
 	}
 
+	if readErr != nil {
+		return nil, readErr
+	}
+
 	return &logcache_v1.ReadResponse{
 		Envelopes: &loggregator_v2.EnvelopeBatch{
 			Batch: result,
@@ -2,6 +2,7 @@ package data_reader_test
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
@@ -30,11 +31,33 @@ var _ = Describe("WalkingDataReader", func() {
 		_, err := r.Read(ctx, &logcache_v1.ReadRequest{})
 		Expect(err).To(HaveOccurred())
 	})
+
+	It("returns the error of the last read", func() {
+		spyLogCache.err = errors.New("some-error")
+
+		_, err := r.Read(context.Background(), &logcache_v1.ReadRequest{SourceId: "some-id"})
+		Expect(err).To(MatchError("some-error"))
+	})
+
+	It("returns the envelopes if a retried read succeeds", func() {
+		spyLogCache.errs = []error{errors.New("some-error")}
+		spyLogCache.results = []*loggregator_v2.Envelope{{Timestamp: 1}}
+
+		resp, err := r.Read(context.Background(), &logcache_v1.ReadRequest{
+			SourceId: "some-id",
+			EndTime:  2,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.GetEnvelopes().GetBatch()).To(HaveLen(1))
+	})
 })
 
 type spyLogCache struct {
 	results []*loggregator_v2.Envelope
 	err     error
+
+	// errs are returned by the first reads, before results.
+	errs []error
 }
 
 func newSpyLogCache() *spyLogCache {
@@ -47,5 +70,22 @@ func (s *spyLogCache) Read(
 	start time.Time,
 	opts ...client.ReadOption,
 ) ([]*loggregator_v2.Envelope, error) {
-	return s.results, s.err
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return nil, err
+	}
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	var es []*loggregator_v2.Envelope
+	for _, e := range s.results {
+		if e.GetTimestamp() >= start.UnixNano() {
+			es = append(es, e)
+		}
+	}
+
+	return es, nil
 }
@@ -0,0 +1,306 @@
+package promql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql"
+)
+
+// Labels returns the sorted label names of the series that match any of the
+// selectors of the request.
+func (q *PromQL) Labels(ctx context.Context, req *logcache_v1.PromQL_LabelsRequest) (*logcache_v1.PromQL_LabelsResult, error) {
+	lcq, cancel, err := q.discoveryQuerier(ctx, req.GetMatch(), req.GetStart(), req.GetEnd())
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	names, err := lcq.labelNames()
+	if err != nil {
+		return nil, err
+	}
+
+	return &logcache_v1.PromQL_LabelsResult{
+		Labels: names,
+	}, nil
+}
+
+// LabelValues returns the sorted values of the given label of the series
+// that match any of the selectors of the request.
+func (q *PromQL) LabelValues(ctx context.Context, req *logcache_v1.PromQL_LabelValuesRequest) (*logcache_v1.PromQL_LabelValuesResult, error) {
+	lcq, cancel, err := q.discoveryQuerier(ctx, req.GetMatch(), req.GetStart(), req.GetEnd())
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	values, err := lcq.LabelValues(req.GetName())
+	if err != nil {
+		return nil, err
+	}
+
+	return &logcache_v1.PromQL_LabelValuesResult{
+		Values: values,
+	}, nil
+}
+
+// Series returns the label sets of the series that match any of the
+// selectors of the request.
+func (q *PromQL) Series(ctx context.Context, req *logcache_v1.PromQL_SeriesRequest) (*logcache_v1.PromQL_SeriesResult, error) {
+	lcq, cancel, err := q.discoveryQuerier(ctx, req.GetMatch(), req.GetStart(), req.GetEnd())
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	series, err := lcq.series()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &logcache_v1.PromQL_SeriesResult{
+		Series: make([]*logcache_v1.PromQL_LabelSet, 0, len(series)),
+	}
+	for _, ls := range series {
+		result.Series = append(result.Series, &logcache_v1.PromQL_LabelSet{
+			Labels: ls.Map(),
+		})
+	}
+
+	return result, nil
+}
+
+// discoveryQuerier returns a querier for the series that match any of the
+// given selectors within the given time range. The end defaults to now and
+// the start to the beginning of the cache.
+func (q *PromQL) discoveryQuerier(ctx context.Context, match []string, start, end string) (*LogCacheQuerier, context.CancelFunc, error) {
+	var selectors [][]*labels.Matcher
+	for _, m := range match {
+		// The gateway passes an empty value along with each match[]
+		// parameter.
+		if m == "" {
+			continue
+		}
+
+		matchers, err := promql.ParseMetricSelector(m)
+		if err != nil {
+			return nil, nil, err
+		}
+		selectors = append(selectors, matchers)
+	}
+
+	if len(selectors) == 0 {
+		return nil, nil, errors.New("at least one match[] selector is required")
+	}
+
+	endTime := time.Now()
+	if end != "" {
+		var err error
+		endTime, err = ParseTime(end)
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't parse end: %s", err)
+		}
+	}
+
+	startTime := time.Unix(0, 0)
+	if start != "" {
+		var err error
+		startTime, err = ParseTime(start)
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't parse start: %s", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, q.queryTimeout)
+
+	return &LogCacheQuerier{
+		log:          q.log,
+		ctx:          ctx,
+		start:        startTime,
+		end:          endTime,
+		interval:     time.Second,
+		dataReader:   q.r,
+		errf:         func(error) {},
+		timerBuckets: q.timerBuckets,
+		sourceIDs:    newSourceIDResolver(ctx, q.meta, q.maxSourceIDs),
+		selectors:    selectors,
+	}, cancel, nil
+}
+
+// LabelValues returns the sorted values of the given label of the series
+// that match any of the selectors of the querier.
+func (l *LogCacheQuerier) LabelValues(name string) ([]string, error) {
+	series, err := l.series()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]struct{})
+	for _, ls := range series {
+		if v := ls.Get(name); v != "" {
+			values[v] = struct{}{}
+		}
+	}
+
+	return sortedKeys(values), nil
+}
+
+// labelNames returns the sorted label names of the series that match any
+// of the selectors of the querier.
+func (l *LogCacheQuerier) labelNames() ([]string, error) {
+	series, err := l.series()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]struct{})
+	for _, ls := range series {
+		for _, lbl := range ls {
+			names[lbl.Name] = struct{}{}
+		}
+	}
+
+	return sortedKeys(names), nil
+}
+
+// series returns the distinct label sets, including the metric name, of
+// the series that match any of the selectors of the querier.
+func (l *LogCacheQuerier) series() ([]labels.Labels, error) {
+	seen := make(map[string]labels.Labels)
+	for _, matchers := range l.selectors {
+		var sourceIDMatchers []*labels.Matcher
+		nameFilter := ""
+		for _, m := range matchers {
+			switch m.Name {
+			case "source_id":
+				sourceIDMatchers = append(sourceIDMatchers, m)
+			case "__name__":
+				if m.Type == labels.MatchEqual && m.Value != "" {
+					nameFilter = l.metricNameFilter(m.Value)
+				}
+			}
+		}
+
+		if len(sourceIDMatchers) == 0 {
+			return nil, errors.New("every match[] selector requires a 'source_id' label")
+		}
+
+		sourceIDs, err := l.sourceIDs.resolve(sourceIDMatchers)
+		if err != nil {
+			return nil, err
+		}
+
+		responses, err := l.readSourceIDs(sourceIDs, func(sourceID string) *logcache_v1.ReadRequest {
+			return &logcache_v1.ReadRequest{
+				SourceId:   sourceID,
+				StartTime:  l.start.UnixNano(),
+				EndTime:    l.end.UnixNano(),
+				NameFilter: nameFilter,
+				EnvelopeTypes: []logcache_v1.EnvelopeType{
+					logcache_v1.EnvelopeType_GAUGE,
+					logcache_v1.EnvelopeType_COUNTER,
+					logcache_v1.EnvelopeType_TIMER,
+				},
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resp := range responses {
+			for _, e := range resp.GetEnvelopes().GetBatch() {
+				for _, ls := range l.envelopeSeries(e) {
+					if !matchesAll(ls, matchers) {
+						continue
+					}
+					seen[ls.String()] = ls
+				}
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	series := make([]labels.Labels, 0, len(keys))
+	for _, k := range keys {
+		series = append(series, seen[k])
+	}
+
+	return series, nil
+}
+
+// metricNames returns the sanitized names of the metrics of the envelope.
+func metricNames(e *loggregator_v2.Envelope) []string {
+	switch m := e.Message.(type) {
+	case *loggregator_v2.Envelope_Counter:
+		return []string{SanitizeMetricName(m.Counter.GetName())}
+	case *loggregator_v2.Envelope_Timer:
+		return []string{SanitizeMetricName(m.Timer.GetName())}
+	case *loggregator_v2.Envelope_Gauge:
+		names := make([]string, 0, len(m.Gauge.GetMetrics()))
+		for name := range m.Gauge.GetMetrics() {
+			names = append(names, SanitizeMetricName(name))
+		}
+		return names
+	default:
+		return nil
+	}
+}
+
+// envelopeSeries returns the labels, including the metric name, of every
+// series the envelope is part of.
+func (l *LogCacheQuerier) envelopeSeries(e *loggregator_v2.Envelope) []labels.Labels {
+	tags := make(map[string]string, len(e.GetTags())+2)
+	for k, v := range e.GetTags() {
+		tags[k] = v
+	}
+
+	tags["source_id"] = e.GetSourceId()
+	if e.GetInstanceId() != "" {
+		tags["instance_id"] = e.GetInstanceId()
+	}
+
+	var series []labels.Labels
+	for _, name := range metricNames(e) {
+		series = append(series, labels.FromMap(withLabel(tags, "__name__", name)))
+	}
+
+	if timer := e.GetTimer(); timer != nil && len(l.timerBuckets) > 0 {
+		for _, m := range timerHistogramLabels(SanitizeMetricName(timer.GetName()), tags, l.timerBuckets) {
+			series = append(series, labels.FromMap(m))
+		}
+	}
+
+	return series
+}
+
+func matchesAll(ls labels.Labels, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(ls.Get(m.Name)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
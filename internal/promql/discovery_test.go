@@ -0,0 +1,179 @@
+package promql_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/internal/promql"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Discovery", func() {
+	var (
+		spyDataReader *spyDataReader
+		q             *promql.PromQL
+	)
+
+	BeforeEach(func() {
+		spyDataReader = newSpyDataReader()
+		q = promql.New(
+			spyDataReader,
+			testhelpers.NewMetricsRegistry(),
+			log.New(ioutil.Discard, "", 0),
+			5*time.Second,
+		)
+
+		spyDataReader.setRead([][]*loggregator_v2.Envelope{{
+			{
+				SourceId:   "some-id",
+				InstanceId: "0",
+				Timestamp:  1,
+				Tags:       map[string]string{"deployment": "cf"},
+				Message: &loggregator_v2.Envelope_Counter{
+					Counter: &loggregator_v2.Counter{Name: "requests", Total: 1},
+				},
+			},
+			{
+				SourceId:   "some-id",
+				InstanceId: "1",
+				Timestamp:  2,
+				Tags:       map[string]string{"deployment": "cf"},
+				Message: &loggregator_v2.Envelope_Counter{
+					Counter: &loggregator_v2.Counter{Name: "requests", Total: 2},
+				},
+			},
+			{
+				SourceId:  "some-id",
+				Timestamp: 3,
+				Tags:      map[string]string{"job": "router"},
+				Message: &loggregator_v2.Envelope_Gauge{
+					Gauge: &loggregator_v2.Gauge{
+						Metrics: map[string]*loggregator_v2.GaugeValue{
+							"cpu":    {Value: 1},
+							"memory": {Value: 2},
+						},
+					},
+				},
+			},
+		}}, []error{nil})
+	})
+
+	Describe("Labels", func() {
+		It("returns the sorted label names of the matching series", func() {
+			r, err := q.Labels(context.Background(), &logcache_v1.PromQL_LabelsRequest{
+				Match: []string{`{source_id="some-id"}`},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.GetLabels()).To(Equal([]string{
+				"__name__", "deployment", "instance_id", "job", "source_id",
+			}))
+		})
+
+		It("only includes the labels of series that match the selector", func() {
+			r, err := q.Labels(context.Background(), &logcache_v1.PromQL_LabelsRequest{
+				Match: []string{`{source_id="some-id", deployment="cf"}`},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.GetLabels()).To(Equal([]string{
+				"__name__", "deployment", "instance_id", "source_id",
+			}))
+		})
+
+		It("reads from the given time range", func() {
+			_, err := q.Labels(context.Background(), &logcache_v1.PromQL_LabelsRequest{
+				Match: []string{`{source_id="some-id"}`},
+				Start: "1",
+				End:   "2",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(spyDataReader.ReadSourceIDs()).To(ConsistOf("some-id"))
+			Expect(spyDataReader.readStarts).To(ConsistOf(time.Unix(1, 0)))
+			Expect(spyDataReader.readEnds).To(ConsistOf(time.Unix(2, 0)))
+		})
+	})
+
+	Describe("LabelValues", func() {
+		It("returns the sorted metric names", func() {
+			r, err := q.LabelValues(context.Background(), &logcache_v1.PromQL_LabelValuesRequest{
+				Name:  "__name__",
+				Match: []string{`{source_id="some-id"}`},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.GetValues()).To(Equal([]string{"cpu", "memory", "requests"}))
+		})
+
+		It("leaves out series without the label", func() {
+			r, err := q.LabelValues(context.Background(), &logcache_v1.PromQL_LabelValuesRequest{
+				Name:  "instance_id",
+				Match: []string{`{source_id="some-id"}`},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.GetValues()).To(Equal([]string{"0", "1"}))
+		})
+	})
+
+	Describe("Series", func() {
+		It("returns the distinct label sets of the matching series", func() {
+			r, err := q.Series(context.Background(), &logcache_v1.PromQL_SeriesRequest{
+				Match: []string{"", `requests{source_id="some-id"}`},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.GetSeries()).To(Equal([]*logcache_v1.PromQL_LabelSet{
+				{Labels: map[string]string{
+					"__name__":    "requests",
+					"deployment":  "cf",
+					"instance_id": "0",
+					"source_id":   "some-id",
+				}},
+				{Labels: map[string]string{
+					"__name__":    "requests",
+					"deployment":  "cf",
+					"instance_id": "1",
+					"source_id":   "some-id",
+				}},
+			}))
+
+			Expect(spyDataReader.ReadNameFilters()).To(HaveLen(1))
+			Expect(spyDataReader.ReadNameFilters()[0]).ToNot(BeEmpty())
+		})
+
+		It("returns an error if a selector does not have a source_id", func() {
+			_, err := q.Series(context.Background(), &logcache_v1.PromQL_SeriesRequest{
+				Match: []string{`requests`},
+			})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error without a selector", func() {
+			_, err := q.Series(context.Background(), &logcache_v1.PromQL_SeriesRequest{
+				Match: []string{""},
+			})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error for an invalid selector", func() {
+			_, err := q.Series(context.Background(), &logcache_v1.PromQL_SeriesRequest{
+				Match: []string{`requests{source_id=`},
+			})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error if reading fails", func() {
+			spyDataReader.setRead([][]*loggregator_v2.Envelope{nil}, []error{errors.New("some-error")})
+
+			_, err := q.Series(context.Background(), &logcache_v1.PromQL_SeriesRequest{
+				Match: []string{`requests{source_id="some-id"}`},
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
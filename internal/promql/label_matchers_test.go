@@ -0,0 +1,138 @@
+package promql_test
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/internal/promql"
+	"code.cloudfoundry.org/log-cache/internal/testing"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	upstream "github.com/prometheus/prometheus/promql"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+// The label matchers of a selector are checked against upstream Prometheus
+// by running the same selector against the same series in its own test
+// storage.
+var _ = Describe("Label matchers", func() {
+	const series = `
+load 1m
+  metric{source_id="some-id", instance_id="0", deployment="cf"} 1
+  metric{source_id="some-id", instance_id="1", deployment="cf-redis"} 2
+  metric{source_id="some-id", instance_id="2"} 3
+  metric{source_id="some-id", deployment="cf", job="router"} 4
+`
+
+	var (
+		spyDataReader *spyDataReader
+		q             *promql.PromQL
+		upstreamTest  *upstream.Test
+	)
+
+	BeforeEach(func() {
+		spyDataReader = newSpyDataReader()
+		q = promql.New(
+			spyDataReader,
+			testhelpers.NewMetricsRegistry(),
+			log.New(ioutil.Discard, "", 0),
+			5*time.Second,
+		)
+
+		var err error
+		upstreamTest, err = upstream.NewTest(GinkgoT(), series)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(upstreamTest.Run()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		upstreamTest.Close()
+	})
+
+	buildEnvelope := func(ts time.Time, instanceID string, tags map[string]string, total uint64) *loggregator_v2.Envelope {
+		return &loggregator_v2.Envelope{
+			SourceId:   "some-id",
+			InstanceId: instanceID,
+			Timestamp:  ts.UnixNano(),
+			Tags:       tags,
+			Message: &loggregator_v2.Envelope_Counter{
+				Counter: &loggregator_v2.Counter{Name: "metric", Total: total},
+			},
+		}
+	}
+
+	upstreamResult := func(query string) []map[string]string {
+		qry, err := upstreamTest.QueryEngine().NewInstantQuery(upstreamTest.Queryable(), query, time.Unix(0, 0))
+		Expect(err).ToNot(HaveOccurred())
+
+		res := qry.Exec(upstreamTest.Context())
+		Expect(res.Err).ToNot(HaveOccurred())
+
+		vector, err := res.Vector()
+		Expect(err).ToNot(HaveOccurred())
+
+		result := []map[string]string{}
+		for _, s := range vector {
+			m := s.Metric.Map()
+			delete(m, "__name__")
+			result = append(result, m)
+		}
+
+		return result
+	}
+
+	logCacheResult := func(query string) []map[string]string {
+		now := time.Now()
+		spyDataReader.setRead([][]*loggregator_v2.Envelope{{
+			buildEnvelope(now, "0", map[string]string{"deployment": "cf"}, 1),
+			buildEnvelope(now, "1", map[string]string{"deployment": "cf-redis"}, 2),
+			buildEnvelope(now, "2", nil, 3),
+			buildEnvelope(now, "", map[string]string{"deployment": "cf", "job": "router"}, 4),
+		}}, []error{nil})
+
+		r, err := q.InstantQuery(context.Background(), &logcache_v1.PromQL_InstantQueryRequest{
+			Time:  testing.FormatTimeWithDecimalMillis(now),
+			Query: query,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		result := []map[string]string{}
+		for _, s := range r.GetVector().GetSamples() {
+			result = append(result, s.GetMetric())
+		}
+
+		return result
+	}
+
+	DescribeTable("selects the same series as Prometheus",
+		func(matchers string, expectedCount int) {
+			query := `metric{source_id="some-id", ` + matchers + `}`
+
+			expected := upstreamResult(query)
+			Expect(expected).To(HaveLen(expectedCount))
+			Expect(logCacheResult(query)).To(ConsistOf(expected))
+		},
+		Entry("equal", `instance_id="0"`, 1),
+		Entry("not equal", `instance_id!="0"`, 3),
+		Entry("regex", `instance_id=~"0|1"`, 2),
+		Entry("negative regex", `instance_id!~"0|1"`, 2),
+		Entry("anchored regex", `deployment=~"cf"`, 2),
+		Entry("prefix regex", `deployment=~"cf-.*"`, 1),
+		Entry("not equal on an absent label", `deployment!="cf"`, 2),
+		Entry("equal to empty on an absent label", `deployment=""`, 1),
+		Entry("not equal to empty", `deployment!=""`, 3),
+		Entry("regex matching empty", `deployment=~".*"`, 4),
+		Entry("regex not matching empty", `deployment=~".+"`, 3),
+		Entry("negative regex matching empty", `deployment!~".+"`, 1),
+		Entry("multiple matchers", `deployment="cf", job!~"rou.*"`, 1),
+		Entry("equal to empty on a label no series has", `missing=""`, 4),
+		Entry("not equal on a label no series has", `missing!="x"`, 4),
+		Entry("equal on a label no series has", `missing="x"`, 0),
+	)
+})
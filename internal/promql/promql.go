@@ -1,6 +1,7 @@
 package promql
 
 import (
+	"bytes"
 	"code.cloudfoundry.org/go-loggregator/metrics"
 	"context"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
@@ -21,6 +23,12 @@ type PromQL struct {
 	r            DataReader
 	log          *log.Logger
 	queryTimeout time.Duration
+	timerBuckets []float64
+	meta         MetaReader
+	maxSourceIDs int
+	cachePeriod  CachePeriod
+	cacheSize    int
+	results      *resultsCache
 
 	failureCounter    metrics.Counter
 	instantQueryTimer metrics.Gauge
@@ -44,6 +52,7 @@ func New(
 	m Metrics,
 	log *log.Logger,
 	queryTimeout time.Duration,
+	opts ...PromQLOption,
 ) *PromQL {
 	q := &PromQL{
 		r:                 r,
@@ -55,16 +64,87 @@ func New(
 		result:            1,
 	}
 
+	for _, o := range opts {
+		o(q)
+	}
+
+	if q.cachePeriod != nil {
+		q.results = newResultsCache(q.cachePeriod, q.cacheSize, m)
+	}
+
 	return q
 }
 
+// PromQLOption configures a PromQL.
+type PromQLOption func(*PromQL)
+
+// WithTimerHistogramBuckets exposes timers as Prometheus histograms with the
+// given bucket upper bounds in seconds. A timer named http is then also
+// selected as http_bucket, with an le label per bucket and +Inf, as well as
+// http_sum and http_count. The series are built from the timers within the
+// range of a query. Defaults to exposing timers only as their durations.
+func WithTimerHistogramBuckets(buckets []float64) PromQLOption {
+	return func(q *PromQL) {
+		q.timerBuckets = buckets
+	}
+}
+
+// WithMetaReader resolves source_id matchers that do not select a fixed set
+// of source IDs, e.g. source_id=~"app-.*" or source_id!="doppler", against
+// the source IDs the MetaReader returns. Defaults to rejecting such
+// matchers.
+func WithMetaReader(m MetaReader) PromQLOption {
+	return func(q *PromQL) {
+		q.meta = m
+	}
+}
+
+// WithMaxSourceIDs limits the number of known source IDs a single selector
+// may match through a regular expression or negative matcher. Queries with
+// a selector that matches more source IDs fail. Selectors of a fixed set of
+// source IDs, e.g. source_id=~"a|b", are not limited. Defaults to no limit.
+func WithMaxSourceIDs(max int) PromQLOption {
+	return func(q *PromQL) {
+		q.maxSourceIDs = max
+	}
+}
+
+// WithResultsCache caches the results of range queries per step, so that a
+// range query that is repeated with a later end only evaluates the steps that
+// were not cached yet. Cached steps are dropped once they are older than the
+// oldest envelope of the CachePeriod or once PurgeResultsCache is called for
+// a source ID they read from. Envelopes that arrive more than a minute late
+// do not change cached steps. At most maxEntries queries are cached, the
+// least recently used ones are evicted first. Zero removes the limit. A
+// request can bypass the cache with nocache. Defaults to evaluating every
+// step of every range query.
+func WithResultsCache(p CachePeriod, maxEntries int) PromQLOption {
+	return func(q *PromQL) {
+		q.cachePeriod = p
+		q.cacheSize = maxEntries
+	}
+}
+
+// PurgeResultsCache drops the cached results of range queries that read from
+// the source ID. It does nothing without a results cache.
+func (q *PromQL) PurgeResultsCache(sourceID string) {
+	if q.results == nil {
+		return
+	}
+
+	q.results.purge(sourceID)
+}
+
 func (q *PromQL) InstantQuery(ctx context.Context, req *logcache_v1.PromQL_InstantQueryRequest) (*logcache_v1.PromQL_InstantQueryResult, error) {
 	var closureErr error
 	interval := time.Second
 	lcq := &logCacheQueryable{
-		log:        q.log,
-		interval:   interval,
-		dataReader: q.r,
+		log:          q.log,
+		interval:     interval,
+		dataReader:   q.r,
+		timerBuckets: q.timerBuckets,
+		meta:         q.meta,
+		maxSourceIDs: q.maxSourceIDs,
 
 		// Prometheus does not hand us back the error the way you might
 		// expect.  Therefore, we have to propagate the error back up
@@ -179,20 +259,6 @@ func (q *PromQL) toInstantQueryResult(r *promql.Result) (*logcache_v1.PromQL_Ins
 }
 
 func (q *PromQL) RangeQuery(ctx context.Context, req *logcache_v1.PromQL_RangeQueryRequest) (*logcache_v1.PromQL_RangeQueryResult, error) {
-	var closureErr error
-	interval := time.Second
-	lcq := &logCacheQueryable{
-		log:        q.log,
-		interval:   interval,
-		dataReader: q.r,
-
-		// Prometheus does not hand us back the error the way you might
-		// expect.  Therefore, we have to propagate the error back up
-		// manually.
-		errf: func(e error) { closureErr = e },
-	}
-	queryable := promql.NewEngine(nil, nil, 10, q.queryTimeout)
-
 	step, err := ParseStep(req.Step)
 	if err != nil {
 		return nil, fmt.Errorf("couldn't parse step: %s", err)
@@ -209,83 +275,140 @@ func (q *PromQL) RangeQuery(ctx context.Context, req *logcache_v1.PromQL_RangeQu
 		return nil, fmt.Errorf("couldn't parse end: %s", err)
 	}
 
-	qq, err := queryable.NewRangeQuery(lcq, req.Query, startTime, endTime, step)
+	var m promql.Matrix
+	if q.results == nil || req.GetNocache() {
+		m, _, err = q.evalRangeQuery(ctx, req.Query, startTime, endTime, step)
+	} else {
+		m, err = q.results.rangeQuery(ctx, req.Query, startTime, endTime, step, q.evalRangeQuery)
+	}
 	if err != nil {
 		return nil, err
 	}
 
+	return q.toRangeQueryResult(m), nil
+}
+
+// evalRangeQuery evaluates the range query. It returns the source IDs that
+// were read.
+func (q *PromQL) evalRangeQuery(ctx context.Context, query string, start, end time.Time, step time.Duration) (promql.Matrix, []string, error) {
+	var (
+		closureErr error
+		mu         sync.Mutex
+		sourceIDs  []string
+	)
+	interval := time.Second
+	lcq := &logCacheQueryable{
+		log:          q.log,
+		interval:     interval,
+		dataReader:   q.r,
+		timerBuckets: q.timerBuckets,
+		meta:         q.meta,
+		maxSourceIDs: q.maxSourceIDs,
+
+		// Prometheus does not hand us back the error the way you might
+		// expect.  Therefore, we have to propagate the error back up
+		// manually.
+		errf: func(e error) { closureErr = e },
+		reads: func(ids []string) {
+			mu.Lock()
+			defer mu.Unlock()
+			sourceIDs = append(sourceIDs, ids...)
+		},
+	}
+	queryable := promql.NewEngine(nil, nil, 10, q.queryTimeout)
+
+	qq, err := queryable.NewRangeQuery(lcq, query, start, end, step)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	queryStartTime := time.Now()
 	r := qq.Exec(ctx)
 	q.rangeQueryTimer.Set(float64(time.Since(queryStartTime) / time.Millisecond))
 
 	if closureErr != nil {
 		q.failureCounter.Add(1)
-		return nil, closureErr
+		return nil, nil, closureErr
 	}
 
-	return q.toRangeQueryResult(r)
-}
-
-func (q *PromQL) toRangeQueryResult(r *promql.Result) (*logcache_v1.PromQL_RangeQueryResult, error) {
 	if r.Err != nil {
-		return nil, r.Err
+		return nil, nil, r.Err
 	}
 
-	switch r.Value.Type() {
-	case promql.ValueTypeMatrix:
-		var series []*logcache_v1.PromQL_Series
-		for _, s := range r.Value.(promql.Matrix) {
-			metric := make(map[string]string)
-			for _, m := range s.Metric {
-				metric[m.Name] = m.Value
-			}
-			var points []*logcache_v1.PromQL_Point
-			for _, p := range s.Points {
-				points = append(points, &logcache_v1.PromQL_Point{
-					Time:  formatPromqlTime(p.T),
-					Value: p.V,
-				})
-			}
+	m, err := r.Matrix()
+	if err != nil {
+		return nil, nil, err
+	}
 
-			series = append(series, &logcache_v1.PromQL_Series{
-				Metric: metric,
-				Points: points,
+	mu.Lock()
+	defer mu.Unlock()
+
+	return m, sourceIDs, nil
+}
+
+func (q *PromQL) toRangeQueryResult(m promql.Matrix) *logcache_v1.PromQL_RangeQueryResult {
+	var series []*logcache_v1.PromQL_Series
+	for _, s := range m {
+		metric := make(map[string]string)
+		for _, m := range s.Metric {
+			metric[m.Name] = m.Value
+		}
+		var points []*logcache_v1.PromQL_Point
+		for _, p := range s.Points {
+			points = append(points, &logcache_v1.PromQL_Point{
+				Time:  formatPromqlTime(p.T),
+				Value: p.V,
 			})
 		}
 
-		return &logcache_v1.PromQL_RangeQueryResult{
-			Result: &logcache_v1.PromQL_RangeQueryResult_Matrix{
-				Matrix: &logcache_v1.PromQL_Matrix{
-					Series: series,
-				},
-			},
-		}, nil
+		series = append(series, &logcache_v1.PromQL_Series{
+			Metric: metric,
+			Points: points,
+		})
+	}
 
-	default:
-		q.log.Panicf("PromQL: unknown type: %s", r.Value.Type())
-		return nil, nil
+	return &logcache_v1.PromQL_RangeQueryResult{
+		Result: &logcache_v1.PromQL_RangeQueryResult_Matrix{
+			Matrix: &logcache_v1.PromQL_Matrix{
+				Series: series,
+			},
+		},
 	}
 }
 
 type logCacheQueryable struct {
-	log        *log.Logger
-	interval   time.Duration
-	dataReader DataReader
-	errf       func(error)
+	log          *log.Logger
+	interval     time.Duration
+	dataReader   DataReader
+	timerBuckets []float64
+	meta         MetaReader
+	maxSourceIDs int
+	errf         func(error)
+
+	// reads is called with the source IDs before they are read. It is
+	// optional.
+	reads func(sourceIDs []string)
 }
 
 func (l *logCacheQueryable) Querier(ctx context.Context, mint int64, maxt int64) (storage.Querier, error) {
 	return &LogCacheQuerier{
-		log:        l.log,
-		ctx:        ctx,
-		start:      time.Unix(0, mint*int64(time.Millisecond)),
-		end:        time.Unix(0, maxt*int64(time.Millisecond)),
-		interval:   l.interval,
-		dataReader: l.dataReader,
-		errf:       l.errf,
+		log:          l.log,
+		ctx:          ctx,
+		start:        time.Unix(0, mint*int64(time.Millisecond)),
+		end:          time.Unix(0, maxt*int64(time.Millisecond)),
+		interval:     l.interval,
+		dataReader:   l.dataReader,
+		timerBuckets: l.timerBuckets,
+		sourceIDs:    newSourceIDResolver(ctx, l.meta, l.maxSourceIDs),
+		errf:         l.errf,
+		reads:        l.reads,
 	}, nil
 }
 
+// maxConcurrentReads is the maximum number of source IDs a querier reads
+// from at a time for a single selector.
+const maxConcurrentReads = 10
+
 type LogCacheQuerier struct {
 	log        *log.Logger
 	ctx        context.Context
@@ -294,57 +417,103 @@ type LogCacheQuerier struct {
 	interval   time.Duration
 	dataReader DataReader
 	errf       func(error)
+
+	// reads is called with the source IDs before they are read. It is
+	// optional.
+	reads func(sourceIDs []string)
+
+	// timerBuckets enable selecting timers as histogram series.
+	timerBuckets []float64
+
+	// sourceIDs resolves the source_id matchers of selectors.
+	sourceIDs *sourceIDResolver
+
+	// selectors scope LabelValues. They are only set for label and series
+	// discovery.
+	selectors [][]*labels.Matcher
 }
 
 func (l *LogCacheQuerier) Select(params *storage.SelectParams, ll ...*labels.Matcher) (storage.SeriesSet, error) {
 	var (
-		metric string
-		ls     []labels.Label
+		metric           string
+		nameFilter       string
+		matchers         []*labels.Matcher
+		sourceIDMatchers []*labels.Matcher
+		histograms       *timerHistograms
 	)
-	sourceIDs := make(map[string]struct{})
-	for _, l := range ll {
-		if l.Name == "__name__" {
-			metric = l.Value
+	for _, m := range ll {
+		if m.Name == "__name__" {
+			metric = m.Value
+			if m.Type == labels.MatchEqual && metric != "" {
+				nameFilter = l.metricNameFilter(metric)
+				histograms = newTimerHistograms(metric, l.timerBuckets)
+			}
 			continue
 		}
-		if l.Name == "source_id" {
-			addSourceIDsFromLabelMatcher(sourceIDs, l)
+		if m.Name == "source_id" {
+			sourceIDMatchers = append(sourceIDMatchers, m)
 			continue
 		}
-		ls = append(ls, labels.Label{
-			Name:  l.Name,
-			Value: l.Value,
-		})
+		matchers = append(matchers, m)
 	}
 
-	if len(sourceIDs) == 0 {
+	if len(sourceIDMatchers) == 0 {
 		err := fmt.Errorf("Metric '%s' does not have a 'source_id' label.", metric)
 		l.errf(err)
 		return nil, err
 	}
 
-	builder := newSeriesBuilder()
+	sourceIDs, err := l.sourceIDs.resolve(sourceIDMatchers)
+	if err != nil {
+		l.errf(err)
+		return nil, err
+	}
 
-	for sourceID := range sourceIDs {
-		ctx, _ := context.WithTimeout(context.Background(), 5*time.Second)
-		envelopeBatch, err := l.dataReader.Read(ctx, &logcache_v1.ReadRequest{
-			SourceId:  sourceID,
-			StartTime: l.start.Add(-time.Second).UnixNano(),
-			EndTime:   l.end.UnixNano(),
+	responses, err := l.readSourceIDs(sourceIDs, func(sourceID string) *logcache_v1.ReadRequest {
+		return &logcache_v1.ReadRequest{
+			SourceId:   sourceID,
+			StartTime:  l.start.Add(-time.Second).UnixNano(),
+			EndTime:    l.end.UnixNano(),
+			NameFilter: nameFilter,
 			EnvelopeTypes: []logcache_v1.EnvelopeType{
 				logcache_v1.EnvelopeType_GAUGE,
 				logcache_v1.EnvelopeType_COUNTER,
 				logcache_v1.EnvelopeType_TIMER,
 			},
-		})
-
-		if err != nil {
-			l.errf(err)
-			return nil, err
 		}
+	})
+	if err != nil {
+		l.errf(err)
+		return nil, err
+	}
+
+	builder := newSeriesBuilder()
 
+	for _, envelopeBatch := range responses {
 		for _, e := range envelopeBatch.GetEnvelopes().GetBatch() {
-			if !l.hasLabels(e.GetTags(), ls) {
+			tags := e.GetTags()
+			if tags == nil {
+				tags = make(map[string]string)
+			}
+
+			tags["source_id"] = e.SourceId
+			if e.InstanceId != "" {
+				tags["instance_id"] = e.InstanceId
+			}
+
+			// Every timer counts towards its histogram, even if the
+			// matchers only select some of its series.
+			if timer := e.GetTimer(); histograms != nil && timer != nil && SanitizeMetricName(timer.GetName()) == histograms.name {
+				t := time.Unix(0, e.GetTimestamp()).Truncate(l.interval).UnixNano() / int64(time.Millisecond)
+				histograms.observe(tags, time.Duration(timer.GetStop()-timer.GetStart()), func(tags map[string]string, v float64) {
+					if l.hasLabels(tags, matchers) {
+						builder.set(tags, point{t: t, v: v})
+					}
+				})
+				continue
+			}
+
+			if !l.hasLabels(tags, matchers) {
 				continue
 			}
 
@@ -377,16 +546,6 @@ func (l *LogCacheQuerier) Select(params *storage.SelectParams, ll ...*labels.Mat
 
 			e.Timestamp = time.Unix(0, e.GetTimestamp()).Truncate(l.interval).UnixNano()
 
-			tags := e.GetTags()
-			if tags == nil {
-				tags = make(map[string]string)
-			}
-
-			tags["source_id"] = e.SourceId
-			if e.InstanceId != "" {
-				tags["instance_id"] = e.InstanceId
-			}
-
 			builder.add(tags, point{
 				t: e.GetTimestamp() / int64(time.Millisecond),
 				v: f,
@@ -397,6 +556,71 @@ func (l *LogCacheQuerier) Select(params *storage.SelectParams, ll ...*labels.Mat
 	return builder.buildSeriesSet(), nil
 }
 
+// readSourceIDs reads from the given source IDs concurrently, with at most
+// maxConcurrentReads reads at a time. The responses are in the order of the
+// source IDs. If a read fails, the remaining reads are cancelled and the
+// error names the source ID that failed.
+func (l *LogCacheQuerier) readSourceIDs(sourceIDs []string, request func(sourceID string) *logcache_v1.ReadRequest) ([]*logcache_v1.ReadResponse, error) {
+	if l.reads != nil {
+		l.reads(sourceIDs)
+	}
+
+	ctx, cancel := context.WithCancel(l.ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		failed    sync.Once
+		readErr   error
+		responses = make([]*logcache_v1.ReadResponse, len(sourceIDs))
+		indices   = make(chan int)
+	)
+
+	workers := maxConcurrentReads
+	if len(sourceIDs) < workers {
+		workers = len(sourceIDs)
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indices {
+				resp, err := l.dataReader.Read(ctx, request(sourceIDs[i]))
+				if err != nil {
+					failed.Do(func() {
+						readErr = fmt.Errorf("failed to read source ID %s: %s", sourceIDs[i], err)
+						cancel()
+					})
+					continue
+				}
+
+				responses[i] = resp
+			}
+		}()
+	}
+
+	for i := range sourceIDs {
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(indices)
+	wg.Wait()
+
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	if err := l.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return responses, nil
+}
+
 func checkMapForSanitizedMetricName(gauge *loggregator_v2.Gauge, metric string) *loggregator_v2.GaugeValue {
 	metricsMap := gauge.GetMetrics()
 	for k, v := range metricsMap {
@@ -416,6 +640,40 @@ func SanitizeMetricName(name string) string {
 	return re.ReplaceAllString(name, "_")
 }
 
+// sanitizedMetricNameFilter returns a regular expression that matches every
+// name that SanitizeMetricName converts to the given metric. It lets the
+// store skip envelopes of other metrics before they are sent back. An
+// underscore may have been any character that is not valid at its position.
+func sanitizedMetricNameFilter(metric string) string {
+	var buf bytes.Buffer
+	buf.WriteString("^")
+	for i, r := range metric {
+		switch {
+		case r == '_' && i == 0:
+			buf.WriteString("[^A-Za-z]")
+		case r == '_':
+			buf.WriteString("[^A-Za-z0-9]")
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	buf.WriteString("$")
+
+	return buf.String()
+}
+
+// metricNameFilter returns the name filter of the reads of the given metric.
+// If the metric is a timer histogram series, it also matches the timers the
+// series is built from.
+func (l *LogCacheQuerier) metricNameFilter(metric string) string {
+	filter := sanitizedMetricNameFilter(metric)
+	if h := newTimerHistograms(metric, l.timerBuckets); h != nil {
+		filter += "|" + sanitizedMetricNameFilter(h.name)
+	}
+
+	return filter
+}
+
 func convertToLabels(tags map[string]string) []labels.Label {
 	ls := make([]labels.Label, 0, len(tags))
 	for n, v := range tags {
@@ -427,9 +685,12 @@ func convertToLabels(tags map[string]string) []labels.Label {
 	return ls
 }
 
-func (l *LogCacheQuerier) hasLabels(tags map[string]string, ls []labels.Label) bool {
-	for _, l := range ls {
-		if v, ok := tags[l.Name]; !ok || v != l.Value {
+// hasLabels reports whether the tags satisfy every matcher. Like in
+// Prometheus, a tag that is not set has the empty value, so negative
+// matchers match envelopes without the tag.
+func (l *LogCacheQuerier) hasLabels(tags map[string]string, matchers []*labels.Matcher) bool {
+	for _, m := range matchers {
+		if !m.Matches(tags[m.Name]) {
 			return false
 		}
 	}
@@ -437,10 +698,6 @@ func (l *LogCacheQuerier) hasLabels(tags map[string]string, ls []labels.Label) b
 	return true
 }
 
-func (l *LogCacheQuerier) LabelValues(name string) ([]string, error) {
-	panic("not implemented")
-}
-
 func (l *LogCacheQuerier) Close() error {
 	return nil
 }
@@ -538,7 +795,7 @@ type seriesSetBuilder struct {
 }
 
 func (b *seriesSetBuilder) add(tags map[string]string, s point) {
-	seriesID := b.getSeriesID(tags)
+	seriesID := seriesID(tags)
 	d, ok := b.data[seriesID]
 
 	if !ok {
@@ -554,7 +811,21 @@ func (b *seriesSetBuilder) add(tags map[string]string, s point) {
 	b.data[seriesID] = d
 }
 
-func (b *seriesSetBuilder) getSeriesID(tags map[string]string) string {
+// set adds the point to the series like add, but replaces the last point of
+// the series if it has the same timestamp. Cumulative series use it to only
+// keep their latest total per timestamp.
+func (b *seriesSetBuilder) set(tags map[string]string, s point) {
+	id := seriesID(tags)
+	d, ok := b.data[id]
+	if ok && len(d.points) > 0 && d.points[len(d.points)-1].t == s.t {
+		d.points[len(d.points)-1] = s
+		return
+	}
+
+	b.add(tags, s)
+}
+
+func seriesID(tags map[string]string) string {
 	keys := make([]string, 0, len(tags))
 	for k := range tags {
 		keys = append(keys, k)
@@ -642,15 +913,17 @@ func (s *sourceIDVisitor) addSourceIDsFromMatchers(labelMatchers []*labels.Match
 	}
 }
 
+// addSourceIDsFromLabelMatcher adds the source IDs of a matcher that
+// selects a fixed set of source IDs. Other source_id matchers have to be
+// resolved against the known source IDs.
 func addSourceIDsFromLabelMatcher(sourceIDs map[string]struct{}, labelMatcher *labels.Matcher) {
-	switch labelMatcher.Type {
-	case labels.MatchRegexp:
-		matchedSourceIDs := strings.Split(labelMatcher.Value, "|")
-		for _, matchedSourceID := range matchedSourceIDs {
-			sourceIDs[matchedSourceID] = struct{}{}
-		}
-	case labels.MatchEqual:
-		sourceIDs[labelMatcher.Value] = struct{}{}
+	matchedSourceIDs, ok := literalSourceIDs(labelMatcher)
+	if !ok {
+		return
+	}
+
+	for _, matchedSourceID := range matchedSourceIDs {
+		sourceIDs[matchedSourceID] = struct{}{}
 	}
 }
 
@@ -713,31 +986,38 @@ func (s *sourceIdReplacementVisitor) replaceSourceIdsInEqualMatcher(labelMatcher
 	expansions, ok := s.sourceIdSets[labelMatcher.Value]
 
 	if ok {
-		if len(expansions) > 1 {
-			labelMatcher.Type = labels.MatchRegexp
-		} else {
-			labelMatcher.Type = labels.MatchEqual
-		}
-		labelMatcher.Value = strings.Join(expansions, "|")
+		setSourceIds(labelMatcher, expansions)
 	}
 }
 
 func (s *sourceIdReplacementVisitor) replaceSourceIdsInRegexpMatcher(labelMatcher *labels.Matcher) {
 	var expansions []string
 
-	startingSourceIds := strings.Split(labelMatcher.Value, "|")
+	// Regular expressions that are not a set of source IDs have to be
+	// resolved with ResolveSourceIdMatchers first.
+	startingSourceIds, ok := literalAlternatives(labelMatcher.Value)
+	if !ok {
+		return
+	}
 
 	for _, sourceId := range startingSourceIds {
 		sourceIdExpansions := s.sourceIdSets[sourceId]
 		expansions = append(expansions, sourceIdExpansions...)
 	}
 
-	if len(expansions) > 1 {
+	setSourceIds(labelMatcher, expansions)
+}
+
+// setSourceIds makes the matcher match exactly the given source IDs.
+func setSourceIds(labelMatcher *labels.Matcher, sourceIds []string) {
+	if len(sourceIds) > 1 {
 		labelMatcher.Type = labels.MatchRegexp
-	} else {
-		labelMatcher.Type = labels.MatchEqual
+		labelMatcher.Value = sourceIDsRegex(sourceIds)
+		return
 	}
-	labelMatcher.Value = strings.Join(expansions, "|")
+
+	labelMatcher.Type = labels.MatchEqual
+	labelMatcher.Value = strings.Join(sourceIds, "")
 }
 
 func formatPromqlTime(timeInMillis int64) string {
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"log"
+	"regexp"
 	"sync"
 	"time"
 
@@ -728,6 +729,25 @@ var _ = Describe("PromQL", func() {
 			Expect(r.GetVector().GetSamples()[0].Point.Value).To(Equal(99.0))
 		})
 
+		It("reads with a name filter for every name the metric is sanitized from", func() {
+			_, err := q.InstantQuery(
+				context.Background(),
+				&logcache_v1.PromQL_InstantQueryRequest{Query: `_some_metric{source_id="some-id-1"}`},
+			)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(spyDataReader.ReadNameFilters()).To(HaveLen(1))
+			nameFilter := regexp.MustCompile(spyDataReader.ReadNameFilters()[0])
+			for _, name := range []string{"_some_metric", "1some.metric", "-some-metric", "/some/metric"} {
+				Expect(nameFilter.MatchString(name)).To(BeTrue(), name)
+				Expect(promql.SanitizeMetricName(name)).To(Equal("_some_metric"))
+			}
+
+			for _, name := range []string{"_some_metrics", "a_some_metric", "_some1metric", "_somemetric"} {
+				Expect(nameFilter.MatchString(name)).To(BeFalse(), name)
+			}
+		})
+
 		It("captures the query time as a metric", func() {
 			_, err := q.InstantQuery(
 				context.Background(),
@@ -1304,6 +1324,7 @@ type spyDataReader struct {
 	readStarts    []time.Time
 	readEnds      []time.Time
 	readTypes     [][]logcache_v1.EnvelopeType
+	readFilters   []string
 
 	readResults [][]*loggregator_v2.Envelope
 	readErrs    []error
@@ -1327,6 +1348,7 @@ func (s *spyDataReader) Read(
 	s.readStarts = append(s.readStarts, time.Unix(0, req.StartTime))
 	s.readEnds = append(s.readEnds, time.Unix(0, req.EndTime))
 	s.readTypes = append(s.readTypes, req.EnvelopeTypes)
+	s.readFilters = append(s.readFilters, req.NameFilter)
 
 	if len(s.readResults) != len(s.readErrs) {
 		panic("readResults and readErrs are out of sync")
@@ -1349,6 +1371,16 @@ func (s *spyDataReader) Read(
 	}, err
 }
 
+func (s *spyDataReader) ReadNameFilters() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]string, len(s.readFilters))
+	copy(result, s.readFilters)
+
+	return result
+}
+
 func (s *spyDataReader) ReadSourceIDs() []string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -0,0 +1,362 @@
+package promql
+
+import (
+	"container/list"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/metrics"
+	"github.com/prometheus/prometheus/promql"
+)
+
+// resultsCacheFreshness is how old the steps of a range query have to be for
+// their results to be cached. Envelopes for more recent steps may still
+// arrive.
+const resultsCacheFreshness = time.Minute
+
+// CachePeriod returns the timestamp in nanoseconds of the oldest envelope
+// in the cache. It returns false if the cache is empty.
+type CachePeriod interface {
+	OldestTimestamp() (int64, bool)
+}
+
+// rangeEvaluator evaluates a range query. It returns the source IDs that
+// were read.
+type rangeEvaluator func(ctx context.Context, query string, start, end time.Time, step time.Duration) (promql.Matrix, []string, error)
+
+// resultsCache caches the results of range queries per step. Queries with
+// the same expression and step whose start times are a whole number of steps
+// apart share their results, so that a refreshed query only evaluates the
+// steps that were not cached yet. At most maxEntries queries are cached,
+// the least recently used ones are evicted first.
+type resultsCache struct {
+	period     CachePeriod
+	maxEntries int
+	hits       metrics.Counter
+	misses     metrics.Counter
+
+	mu      sync.Mutex
+	lru     *list.List
+	extents map[resultsCacheKey]*list.Element
+
+	// generation is incremented on every purge. purged holds the generation
+	// of the last purge of each source ID while queries are evaluated, so
+	// that results read before a purge are not cached after it.
+	generation uint64
+	purged     map[string]uint64
+	inFlight   int
+}
+
+type resultsCacheKey struct {
+	query string
+	step  int64
+
+	// offset is the start time modulo the step. Only the steps of queries
+	// with the same offset line up.
+	offset int64
+}
+
+// extent holds the results of the steps from start to end, both inclusive.
+// The times are in milliseconds. An extent is not modified once it is
+// cached.
+type extent struct {
+	start  int64
+	end    int64
+	matrix promql.Matrix
+
+	// sourceIDs are the source IDs that were read to evaluate the steps.
+	sourceIDs map[string]struct{}
+}
+
+// resultsCacheEntry is the value of the elements of the LRU list.
+type resultsCacheEntry struct {
+	key    resultsCacheKey
+	extent *extent
+}
+
+func newResultsCache(period CachePeriod, maxEntries int, m Metrics) *resultsCache {
+	return &resultsCache{
+		period:     period,
+		maxEntries: maxEntries,
+		hits:       m.NewCounter("log_cache_promql_results_cache_hits"),
+		misses:     m.NewCounter("log_cache_promql_results_cache_misses"),
+		lru:        list.New(),
+		extents:    make(map[resultsCacheKey]*list.Element),
+		purged:     make(map[string]uint64),
+	}
+}
+
+// rangeQuery returns the results of the range query. The results of cached
+// steps are reused and only the steps after them are evaluated.
+func (c *resultsCache) rangeQuery(ctx context.Context, query string, start, end time.Time, step time.Duration, eval rangeEvaluator) (promql.Matrix, error) {
+	expr, err := promql.ParseExpr(query)
+	if err != nil || step < time.Millisecond || end.Before(start) {
+		m, _, err := eval(ctx, query, start, end, step)
+		return m, err
+	}
+
+	startMs := timeToMs(start)
+	stepMs := int64(step / time.Millisecond)
+	lastStep := startMs + (timeToMs(end)-startMs)/stepMs*stepMs
+	key := resultsCacheKey{
+		query:  expr.String(),
+		step:   stepMs,
+		offset: mod(startMs, stepMs),
+	}
+
+	gen := c.begin()
+	defer c.end()
+
+	e := c.extent(key)
+	if e == nil || e.start > startMs || e.end < startMs {
+		c.misses.Add(1)
+
+		m, sourceIDs, err := eval(ctx, query, start, end, step)
+		if err != nil {
+			return nil, err
+		}
+		c.store(key, startMs, lastStep, m, toSet(nil, sourceIDs), gen)
+
+		return m, nil
+	}
+	c.hits.Add(1)
+
+	if e.end >= lastStep {
+		return between(e.matrix, startMs, lastStep), nil
+	}
+
+	tail, sourceIDs, err := eval(ctx, query, msToTime(e.end+stepMs), end, step)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, e.start, lastStep, merge(e.matrix, tail), toSet(e.sourceIDs, sourceIDs), gen)
+
+	return merge(between(e.matrix, startMs, e.end), tail), nil
+}
+
+// extent returns the cached extent of the key with the steps that fell out
+// of the cache period removed.
+func (c *resultsCache) extent(key resultsCacheKey) *extent {
+	oldest, ok := c.oldest()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, found := c.extents[key]
+	if !found {
+		return nil
+	}
+
+	entry := elem.Value.(*resultsCacheEntry)
+	e := entry.extent
+	if !ok || e.end < oldest {
+		c.remove(elem)
+		return nil
+	}
+
+	if e.start < oldest {
+		start := e.start + (oldest-e.start+key.step-1)/key.step*key.step
+		e = &extent{
+			start:     start,
+			end:       e.end,
+			matrix:    between(e.matrix, start, e.end),
+			sourceIDs: e.sourceIDs,
+		}
+		entry.extent = e
+	}
+	c.lru.MoveToFront(elem)
+
+	return e
+}
+
+// begin returns the generation that a query starts evaluating in. Each call
+// has to be followed by a call to end.
+func (c *resultsCache) begin() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight++
+	return c.generation
+}
+
+// end marks the evaluation of a query as done. Once no query is evaluated
+// anymore, the purged source IDs are forgotten.
+func (c *resultsCache) end() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inFlight--
+	if c.inFlight == 0 && len(c.purged) > 0 {
+		c.purged = make(map[string]uint64)
+	}
+}
+
+// store caches the results of the steps from start to end that are old
+// enough, unless one of the source IDs was purged after the generation the
+// results were evaluated in. It removes the extents that fell out of the
+// cache period and evicts the least recently used extents beyond
+// maxEntries.
+func (c *resultsCache) store(key resultsCacheKey, start, end int64, m promql.Matrix, sourceIDs map[string]struct{}, gen uint64) {
+	freshStep := timeToMs(time.Now().Add(-resultsCacheFreshness))
+	if freshStep < start {
+		return
+	}
+
+	if freshStep < end {
+		end = freshStep - (freshStep-start)%key.step
+	}
+
+	e := &extent{
+		start:     start,
+		end:       end,
+		matrix:    between(m, start, end),
+		sourceIDs: sourceIDs,
+	}
+
+	oldest, ok := c.oldest()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for id := range sourceIDs {
+		if c.purged[id] > gen {
+			return
+		}
+	}
+
+	for _, elem := range c.extents {
+		if !ok || elem.Value.(*resultsCacheEntry).extent.end < oldest {
+			c.remove(elem)
+		}
+	}
+
+	if elem, found := c.extents[key]; found {
+		entry := elem.Value.(*resultsCacheEntry)
+		if entry.extent.start > e.start || entry.extent.end < e.end {
+			entry.extent = e
+		}
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.extents[key] = c.lru.PushFront(&resultsCacheEntry{key: key, extent: e})
+
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// purge removes the extents that read from the source ID. Queries that are
+// evaluated meanwhile do not cache their results of it.
+func (c *resultsCache) purge(sourceID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	if c.inFlight > 0 {
+		c.purged[sourceID] = c.generation
+	}
+
+	for _, elem := range c.extents {
+		if _, ok := elem.Value.(*resultsCacheEntry).extent.sourceIDs[sourceID]; ok {
+			c.remove(elem)
+		}
+	}
+}
+
+// remove removes the element from the LRU list and its extent from the
+// cache. The caller has to hold the lock.
+func (c *resultsCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.extents, elem.Value.(*resultsCacheEntry).key)
+}
+
+// oldest returns the time in milliseconds of the oldest envelope in the
+// cache.
+func (c *resultsCache) oldest() (int64, bool) {
+	ts, ok := c.period.OldestTimestamp()
+	if !ok {
+		return 0, false
+	}
+
+	return ts / int64(time.Millisecond), true
+}
+
+// between returns the points of every series from start to end, both
+// inclusive. Series without such points are left out.
+func between(m promql.Matrix, start, end int64) promql.Matrix {
+	var result promql.Matrix
+	for _, s := range m {
+		i := sort.Search(len(s.Points), func(i int) bool { return s.Points[i].T >= start })
+		j := sort.Search(len(s.Points), func(i int) bool { return s.Points[i].T > end })
+		if i >= j {
+			continue
+		}
+
+		result = append(result, promql.Series{
+			Metric: s.Metric,
+			Points: s.Points[i:j:j],
+		})
+	}
+
+	return result
+}
+
+// merge returns the series of both matrices with the points of b appended
+// to the points of a. The points of b have to come after those of a.
+func merge(a, b promql.Matrix) promql.Matrix {
+	index := make(map[string]int, len(a))
+	result := make(promql.Matrix, 0, len(a)+len(b))
+	for _, s := range a {
+		index[s.Metric.String()] = len(result)
+		result = append(result, promql.Series{
+			Metric: s.Metric,
+			Points: s.Points[:len(s.Points):len(s.Points)],
+		})
+	}
+
+	for _, s := range b {
+		i, ok := index[s.Metric.String()]
+		if !ok {
+			result = append(result, s)
+			continue
+		}
+
+		result[i].Points = append(result[i].Points, s.Points...)
+	}
+	sort.Sort(result)
+
+	return result
+}
+
+// toSet returns a new set with the source IDs of the set and the slice.
+func toSet(set map[string]struct{}, sourceIDs []string) map[string]struct{} {
+	result := make(map[string]struct{}, len(set)+len(sourceIDs))
+	for id := range set {
+		result[id] = struct{}{}
+	}
+	for _, id := range sourceIDs {
+		result[id] = struct{}{}
+	}
+
+	return result
+}
+
+func timeToMs(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func msToTime(ms int64) time.Time {
+	return time.Unix(0, ms*int64(time.Millisecond))
+}
+
+// mod returns the non-negative remainder of a divided by b.
+func mod(a, b int64) int64 {
+	r := a % b
+	if r < 0 {
+		return r + b
+	}
+
+	return r
+}
@@ -0,0 +1,281 @@
+package promql_test
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/internal/promql"
+	"code.cloudfoundry.org/log-cache/internal/testing"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Results cache", func() {
+	var (
+		spyDataReader  *spyDataReader
+		spyMetrics     *testhelpers.SpyMetricsRegistry
+		spyCachePeriod *spyCachePeriod
+		q              *promql.PromQL
+		t0             time.Time
+		metricSelector = `metric{source_id="some-id"}`
+		hits, misses   func() float64
+		rangeQuery     func(query string, start, end time.Time, nocache bool) *logcache_v1.PromQL_RangeQueryResult
+	)
+
+	BeforeEach(func() {
+		t0 = time.Now().Add(-time.Hour).Truncate(time.Minute)
+
+		var batch []*loggregator_v2.Envelope
+		for i := 0; i < 30; i++ {
+			batch = append(batch, &loggregator_v2.Envelope{
+				SourceId:  "some-id",
+				Timestamp: t0.Add(time.Duration(i) * 10 * time.Second).UnixNano(),
+				Message: &loggregator_v2.Envelope_Gauge{
+					Gauge: &loggregator_v2.Gauge{
+						Metrics: map[string]*loggregator_v2.GaugeValue{
+							"metric": {Value: float64(i)},
+						},
+					},
+				},
+			})
+		}
+
+		var (
+			results [][]*loggregator_v2.Envelope
+			errs    []error
+		)
+		for i := 0; i < 10; i++ {
+			results = append(results, batch)
+			errs = append(errs, nil)
+		}
+
+		spyDataReader = newSpyDataReader()
+		spyDataReader.setRead(results, errs)
+		spyMetrics = testhelpers.NewMetricsRegistry()
+		spyCachePeriod = newSpyCachePeriod(t0.Add(-time.Hour))
+
+		q = promql.New(
+			spyDataReader,
+			spyMetrics,
+			log.New(ioutil.Discard, "", 0),
+			5*time.Second,
+			promql.WithResultsCache(spyCachePeriod, 2),
+		)
+
+		hits = func() float64 {
+			return spyMetrics.GetMetricValue("log_cache_promql_results_cache_hits", nil)
+		}
+		misses = func() float64 {
+			return spyMetrics.GetMetricValue("log_cache_promql_results_cache_misses", nil)
+		}
+		rangeQuery = func(query string, start, end time.Time, nocache bool) *logcache_v1.PromQL_RangeQueryResult {
+			r, err := q.RangeQuery(context.Background(), &logcache_v1.PromQL_RangeQueryRequest{
+				Query:   query,
+				Start:   testing.FormatTimeWithDecimalMillis(start),
+				End:     testing.FormatTimeWithDecimalMillis(end),
+				Step:    "30s",
+				Nocache: nocache,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			return r
+		}
+	})
+
+	reads := func() int {
+		return len(spyDataReader.ReadSourceIDs())
+	}
+
+	It("only evaluates the steps that were not cached", func() {
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+		Expect(misses()).To(Equal(1.0))
+
+		r := rangeQuery(metricSelector, t0.Add(30*time.Second), t0.Add(4*time.Minute), false)
+		Expect(hits()).To(Equal(1.0))
+		Expect(reads()).To(Equal(2))
+		Expect(spyDataReader.readStarts[1]).To(BeTemporally("~", t0.Add(150*time.Second).Add(-5*time.Minute), time.Second))
+
+		expected := rangeQuery(metricSelector, t0.Add(30*time.Second), t0.Add(4*time.Minute), true)
+		Expect(r).To(Equal(expected))
+		Expect(r.GetMatrix().GetSeries()[0].GetPoints()).To(HaveLen(8))
+	})
+
+	It("does not evaluate steps that are cached", func() {
+		rangeQuery(metricSelector, t0, t0.Add(4*time.Minute), false)
+
+		r := rangeQuery(metricSelector, t0.Add(time.Minute), t0.Add(2*time.Minute), false)
+		Expect(hits()).To(Equal(1.0))
+		Expect(reads()).To(Equal(1))
+
+		points := r.GetMatrix().GetSeries()[0].GetPoints()
+		Expect(points).To(HaveLen(3))
+		Expect(points[0].GetValue()).To(Equal(6.0))
+		Expect(points[2].GetValue()).To(Equal(12.0))
+	})
+
+	It("shares the steps of queries that only differ in formatting", func() {
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+		rangeQuery(`metric{ source_id = "some-id" }`, t0, t0.Add(2*time.Minute), false)
+
+		Expect(hits()).To(Equal(1.0))
+		Expect(reads()).To(Equal(1))
+	})
+
+	It("does not share the steps of queries with steps that do not line up", func() {
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+		rangeQuery(metricSelector, t0.Add(10*time.Second), t0.Add(2*time.Minute), false)
+
+		Expect(misses()).To(Equal(2.0))
+		Expect(reads()).To(Equal(2))
+	})
+
+	It("does not cache the steps of the last minute", func() {
+		now := time.Now()
+		rangeQuery(metricSelector, now.Add(-3*time.Minute), now, false)
+		rangeQuery(metricSelector, now.Add(-3*time.Minute), now, false)
+
+		Expect(hits()).To(Equal(1.0))
+		Expect(reads()).To(Equal(2))
+		Expect(spyDataReader.readStarts[1]).To(BeTemporally(">", spyDataReader.readStarts[0]))
+	})
+
+	It("drops cached steps that fell out of the cache period", func() {
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+		spyCachePeriod.setOldest(t0.Add(time.Minute))
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+
+		Expect(misses()).To(Equal(2.0))
+		Expect(reads()).To(Equal(2))
+	})
+
+	It("drops every cached step if the cache is empty", func() {
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+		spyCachePeriod.setEmpty()
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+
+		Expect(misses()).To(Equal(2.0))
+	})
+
+	It("evicts the least recently used query beyond the maximum entries", func() {
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+		rangeQuery(metricSelector, t0.Add(10*time.Second), t0.Add(2*time.Minute), false)
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+		rangeQuery(metricSelector, t0.Add(20*time.Second), t0.Add(2*time.Minute), false)
+		Expect(hits()).To(Equal(1.0))
+		Expect(misses()).To(Equal(3.0))
+
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+		Expect(hits()).To(Equal(2.0))
+
+		rangeQuery(metricSelector, t0.Add(10*time.Second), t0.Add(2*time.Minute), false)
+		Expect(misses()).To(Equal(4.0))
+	})
+
+	It("drops the cached steps of queries that read from a purged source ID", func() {
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+		q.PurgeResultsCache("other-id")
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+		Expect(hits()).To(Equal(1.0))
+
+		q.PurgeResultsCache("some-id")
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+		Expect(misses()).To(Equal(2.0))
+		Expect(reads()).To(Equal(2))
+	})
+
+	It("does not cache results that were evaluated while the source ID was purged", func() {
+		reader := newGatedDataReader()
+		q = promql.New(
+			reader,
+			spyMetrics,
+			log.New(ioutil.Discard, "", 0),
+			5*time.Second,
+			promql.WithResultsCache(spyCachePeriod, 2),
+		)
+
+		done := make(chan struct{})
+		go func() {
+			defer GinkgoRecover()
+			defer close(done)
+			rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+		}()
+
+		Eventually(reader.reading).Should(Receive())
+		q.PurgeResultsCache("some-id")
+		close(reader.release)
+		Eventually(done).Should(BeClosed())
+
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), false)
+		Expect(hits()).To(BeZero())
+		Expect(misses()).To(Equal(2.0))
+	})
+
+	It("bypasses the cache with nocache", func() {
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), true)
+		rangeQuery(metricSelector, t0, t0.Add(2*time.Minute), true)
+
+		Expect(reads()).To(Equal(2))
+		Expect(hits()).To(BeZero())
+		Expect(misses()).To(BeZero())
+	})
+})
+
+// gatedDataReader signals each read and blocks it until it is released.
+type gatedDataReader struct {
+	reading chan struct{}
+	release chan struct{}
+}
+
+func newGatedDataReader() *gatedDataReader {
+	return &gatedDataReader{
+		reading: make(chan struct{}, 10),
+		release: make(chan struct{}),
+	}
+}
+
+func (r *gatedDataReader) Read(ctx context.Context, req *logcache_v1.ReadRequest) (*logcache_v1.ReadResponse, error) {
+	r.reading <- struct{}{}
+	<-r.release
+
+	return &logcache_v1.ReadResponse{}, nil
+}
+
+type spyCachePeriod struct {
+	mu     sync.Mutex
+	oldest int64
+	empty  bool
+}
+
+func newSpyCachePeriod(oldest time.Time) *spyCachePeriod {
+	return &spyCachePeriod{
+		oldest: oldest.UnixNano(),
+	}
+}
+
+func (s *spyCachePeriod) OldestTimestamp() (int64, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.oldest, !s.empty
+}
+
+func (s *spyCachePeriod) setOldest(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.oldest = t.UnixNano()
+}
+
+func (s *spyCachePeriod) setEmpty() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.empty = true
+}
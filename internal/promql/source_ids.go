@@ -0,0 +1,276 @@
+package promql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/promql"
+)
+
+// MetaReader returns the meta information of the source IDs of the cache.
+type MetaReader interface {
+	Meta(ctx context.Context, in *logcache_v1.MetaRequest) (*logcache_v1.MetaResponse, error)
+}
+
+// ErrNoMatchingSourceIds is returned by ResolveSourceIdMatchers if the
+// source_id matchers of a selector do not match any of the known source IDs.
+var ErrNoMatchingSourceIds = errors.New("source_id matchers do not match any source IDs")
+
+// sourceIDResolver resolves the source_id matchers of the selectors of a
+// single query. The known source IDs are only read once per query and only
+// if a selector does not select a fixed set of source IDs.
+type sourceIDResolver struct {
+	ctx  context.Context
+	meta MetaReader
+	max  int
+
+	once  sync.Once
+	known []string
+	err   error
+}
+
+func newSourceIDResolver(ctx context.Context, meta MetaReader, max int) *sourceIDResolver {
+	return &sourceIDResolver{
+		ctx:  ctx,
+		meta: meta,
+		max:  max,
+	}
+}
+
+// resolve returns the sorted source IDs that satisfy every given source_id
+// matcher. It returns an error if the matchers do not select a fixed set
+// of source IDs and match more than the maximum of the known source IDs.
+func (r *sourceIDResolver) resolve(matchers []*labels.Matcher) ([]string, error) {
+	sourceIDs, fixed, err := selectSourceIDs(matchers, r.knownSourceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if !fixed && r.max > 0 && len(sourceIDs) > r.max {
+		return nil, fmt.Errorf("source_id matchers select %d source IDs, more than the limit of %d", len(sourceIDs), r.max)
+	}
+
+	return sourceIDs, nil
+}
+
+func (r *sourceIDResolver) knownSourceIDs() ([]string, error) {
+	r.once.Do(func() {
+		if r.meta == nil {
+			r.err = errors.New("source_id matchers must select a fixed set of source IDs")
+			return
+		}
+
+		resp, err := r.meta.Meta(r.ctx, &logcache_v1.MetaRequest{})
+		if err != nil {
+			r.err = fmt.Errorf("failed to read source IDs: %s", err)
+			return
+		}
+
+		for sourceID := range resp.GetMeta() {
+			r.known = append(r.known, sourceID)
+		}
+	})
+
+	return r.known, r.err
+}
+
+// selectSourceIDs returns the sorted source IDs that satisfy every given
+// source_id matcher. The candidates are the source IDs of a matcher that
+// selects a fixed set of them, or else the known source IDs. It returns
+// true if the candidates were a fixed set.
+func selectSourceIDs(matchers []*labels.Matcher, known func() ([]string, error)) ([]string, bool, error) {
+	var candidates []string
+	fixed := false
+	for _, m := range matchers {
+		if sourceIDs, ok := literalSourceIDs(m); ok {
+			candidates = sourceIDs
+			fixed = true
+			break
+		}
+	}
+
+	if !fixed {
+		var err error
+		candidates, err = known()
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	var sourceIDs []string
+	for _, sourceID := range candidates {
+		if seen[sourceID] || !matchesAll(labels.Labels{{Name: "source_id", Value: sourceID}}, matchers) {
+			continue
+		}
+
+		seen[sourceID] = true
+		sourceIDs = append(sourceIDs, sourceID)
+	}
+	sort.Strings(sourceIDs)
+
+	return sourceIDs, fixed, nil
+}
+
+// literalSourceIDs returns the source IDs of a matcher that selects a fixed
+// set of source IDs, i.e. source_id="a" or source_id=~"a|b".
+func literalSourceIDs(m *labels.Matcher) ([]string, bool) {
+	switch m.Type {
+	case labels.MatchEqual:
+		return []string{m.Value}, true
+	case labels.MatchRegexp:
+		return literalAlternatives(m.Value)
+	default:
+		return nil, false
+	}
+}
+
+// literalAlternatives returns the unescaped alternatives of a regular
+// expression that only alternates literal strings, e.g. a|b\.c.
+func literalAlternatives(re string) ([]string, bool) {
+	const special = `\.+*?()|[]{}^$`
+
+	var (
+		alternatives []string
+		buf          bytes.Buffer
+		escaped      bool
+	)
+	for _, r := range re {
+		switch {
+		case escaped:
+			if !strings.ContainsRune(special, r) {
+				return nil, false
+			}
+			buf.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '|':
+			alternatives = append(alternatives, buf.String())
+			buf.Reset()
+		case strings.ContainsRune(special, r):
+			return nil, false
+		default:
+			buf.WriteRune(r)
+		}
+	}
+
+	if escaped {
+		return nil, false
+	}
+
+	return append(alternatives, buf.String()), true
+}
+
+// sourceIDsRegex returns a regular expression that matches exactly the
+// given source IDs.
+func sourceIDsRegex(sourceIDs []string) string {
+	quoted := make([]string, 0, len(sourceIDs))
+	for _, sourceID := range sourceIDs {
+		quoted = append(quoted, regexp.QuoteMeta(sourceID))
+	}
+
+	return strings.Join(quoted, "|")
+}
+
+// ResolveSourceIdMatchers replaces the source_id matchers of every selector
+// of the query that does not select a fixed set of source IDs, e.g.
+// source_id=~"app-.*" or source_id!="doppler", with a matcher of the known
+// source IDs they match. The known source IDs are only requested if a
+// selector needs them. It returns ErrNoMatchingSourceIds if a selector does
+// not match any of them.
+func ResolveSourceIdMatchers(query string, known func() ([]string, error)) (string, error) {
+	expr, err := promql.ParseExpr(query)
+	if err != nil {
+		return "", err
+	}
+
+	visitor := &sourceIdResolutionVisitor{known: known}
+	err = promql.Walk(visitor, expr, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if !visitor.resolved {
+		return query, nil
+	}
+
+	return expr.String(), nil
+}
+
+type sourceIdResolutionVisitor struct {
+	known    func() ([]string, error)
+	resolved bool
+}
+
+func (s *sourceIdResolutionVisitor) Visit(node promql.Node, _ []promql.Node) (promql.Visitor, error) {
+	if node == nil {
+		return nil, nil
+	}
+
+	var err error
+	switch selector := node.(type) {
+	case *promql.VectorSelector:
+		selector.LabelMatchers, err = s.resolve(selector.LabelMatchers)
+	case *promql.MatrixSelector:
+		selector.LabelMatchers, err = s.resolve(selector.LabelMatchers)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (s *sourceIdResolutionVisitor) resolve(labelMatchers []*labels.Matcher) ([]*labels.Matcher, error) {
+	var (
+		others           []*labels.Matcher
+		sourceIdMatchers []*labels.Matcher
+		needsResolution  bool
+	)
+	for _, m := range labelMatchers {
+		if m.Name != "source_id" {
+			others = append(others, m)
+			continue
+		}
+
+		sourceIdMatchers = append(sourceIdMatchers, m)
+		if _, ok := literalSourceIDs(m); !ok {
+			needsResolution = true
+		}
+	}
+
+	if !needsResolution {
+		return labelMatchers, nil
+	}
+
+	sourceIds, _, err := selectSourceIDs(sourceIdMatchers, s.known)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *labels.Matcher
+	switch len(sourceIds) {
+	case 0:
+		return nil, ErrNoMatchingSourceIds
+	case 1:
+		m, err = labels.NewMatcher(labels.MatchEqual, "source_id", sourceIds[0])
+	default:
+		m, err = labels.NewMatcher(labels.MatchRegexp, "source_id", sourceIDsRegex(sourceIds))
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.resolved = true
+
+	return append(others, m), nil
+}
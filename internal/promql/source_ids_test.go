@@ -0,0 +1,198 @@
+package promql_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	"code.cloudfoundry.org/log-cache/internal/promql"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Source ID matchers", func() {
+	var (
+		spyDataReader *spyDataReader
+		spyMetaReader *spyMetaReader
+		q             *promql.PromQL
+	)
+
+	BeforeEach(func() {
+		spyDataReader = newSpyDataReader()
+		spyMetaReader = newSpyMetaReader("app-1", "app-2", "doppler", "app.3")
+		q = promql.New(
+			spyDataReader,
+			testhelpers.NewMetricsRegistry(),
+			log.New(ioutil.Discard, "", 0),
+			5*time.Second,
+			promql.WithMetaReader(spyMetaReader),
+			promql.WithMaxSourceIDs(3),
+		)
+	})
+
+	instantQuery := func(query string) error {
+		_, err := q.InstantQuery(context.Background(), &logcache_v1.PromQL_InstantQueryRequest{
+			Query: query,
+		})
+		return err
+	}
+
+	DescribeTable("reads the known source IDs the matchers select", func(query string, expected []string) {
+		Expect(instantQuery(query)).To(Succeed())
+		Expect(spyDataReader.ReadSourceIDs()).To(ConsistOf(expected))
+	},
+		Entry("regex", `metric{source_id=~"app-.*"}`, []string{"app-1", "app-2"}),
+		Entry("negative regex", `metric{source_id!~"app-.*"}`, []string{"doppler", "app.3"}),
+		Entry("not equal", `metric{source_id!="doppler", source_id=~"app-.*"}`, []string{"app-1", "app-2"}),
+		Entry("anchored", `metric{source_id=~"ap."}`, []string{}),
+	)
+
+	It("does not read the known source IDs for a fixed set of source IDs", func() {
+		Expect(instantQuery(`metric{source_id=~"a|b\\.c"}`)).To(Succeed())
+
+		Expect(spyDataReader.ReadSourceIDs()).To(ConsistOf("a", "b.c"))
+		Expect(spyMetaReader.called()).To(BeZero())
+	})
+
+	It("only reads the known source IDs once per query", func() {
+		Expect(instantQuery(`metric{source_id=~"app-1.*"} + metric{source_id=~"app-.*"}`)).To(Succeed())
+
+		Expect(spyMetaReader.called()).To(Equal(1))
+	})
+
+	It("returns an error if the matchers select too many source IDs", func() {
+		err := instantQuery(`metric{source_id=~".+"}`)
+
+		Expect(err).To(MatchError(ContainSubstring("more than the limit of 3")))
+		Expect(spyDataReader.ReadSourceIDs()).To(BeEmpty())
+	})
+
+	It("does not limit a fixed set of source IDs", func() {
+		Expect(instantQuery(`metric{source_id=~"a|b|c|d|e"}`)).To(Succeed())
+
+		Expect(spyDataReader.ReadSourceIDs()).To(ConsistOf("a", "b", "c", "d", "e"))
+	})
+
+	It("returns an error if reading the known source IDs fails", func() {
+		spyMetaReader.err = errors.New("some-error")
+
+		Expect(instantQuery(`metric{source_id=~"app-.*"}`)).ToNot(Succeed())
+	})
+
+	It("returns an error for a regex without a meta reader", func() {
+		q = promql.New(
+			spyDataReader,
+			testhelpers.NewMetricsRegistry(),
+			log.New(ioutil.Discard, "", 0),
+			5*time.Second,
+		)
+
+		Expect(instantQuery(`metric{source_id=~"app-.*"}`)).ToNot(Succeed())
+	})
+
+	It("resolves the source IDs of discovery selectors", func() {
+		_, err := q.Series(context.Background(), &logcache_v1.PromQL_SeriesRequest{
+			Match: []string{`{source_id=~"app-.*"}`},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(spyDataReader.ReadSourceIDs()).To(ConsistOf("app-1", "app-2"))
+	})
+
+	Context("ResolveSourceIdMatchers", func() {
+		known := func() ([]string, error) {
+			return []string{"app-1", "app-2", "doppler"}, nil
+		}
+
+		It("replaces the source_id matchers with the source IDs they select", func() {
+			query, err := promql.ResolveSourceIdMatchers(`metric{source_id=~"app-.*", job="a"} + metric{source_id!="app-1", source_id=~"app-.*"}`, known)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(query).To(Equal(`metric{job="a",source_id=~"app-1|app-2"} + metric{source_id="app-2"}`))
+		})
+
+		It("returns queries that select a fixed set of source IDs unmodified", func() {
+			query := `metric{source_id="a"} + metric{source_id=~"b|c"}`
+			resolved, err := promql.ResolveSourceIdMatchers(query, func() ([]string, error) {
+				panic("known source IDs are not needed")
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resolved).To(Equal(query))
+		})
+
+		It("quotes source IDs with special characters", func() {
+			query, err := promql.ResolveSourceIdMatchers(`metric{source_id=~"app.*"}`, func() ([]string, error) {
+				return []string{"app.1", "app+2"}, nil
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(query).To(Equal(`metric{source_id=~"app\\+2|app\\.1"}`))
+		})
+
+		It("returns ErrNoMatchingSourceIds if a selector does not match any source IDs", func() {
+			_, err := promql.ResolveSourceIdMatchers(`metric{source_id=~"other-.*"}`, known)
+
+			Expect(err).To(Equal(promql.ErrNoMatchingSourceIds))
+		})
+
+		It("returns the error of reading the known source IDs", func() {
+			_, err := promql.ResolveSourceIdMatchers(`metric{source_id=~"app-.*"}`, func() ([]string, error) {
+				return nil, errors.New("some-error")
+			})
+
+			Expect(err).To(MatchError("some-error"))
+		})
+
+		It("returns an error for an invalid query", func() {
+			_, err := promql.ResolveSourceIdMatchers(`invalid.query`, known)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+type spyMetaReader struct {
+	mu        sync.Mutex
+	sourceIDs []string
+	err       error
+	calls     int
+}
+
+func newSpyMetaReader(sourceIDs ...string) *spyMetaReader {
+	return &spyMetaReader{
+		sourceIDs: sourceIDs,
+	}
+}
+
+func (s *spyMetaReader) Meta(ctx context.Context, in *logcache_v1.MetaRequest) (*logcache_v1.MetaResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	meta := make(map[string]*logcache_v1.MetaInfo)
+	for _, sourceID := range s.sourceIDs {
+		meta[sourceID] = &logcache_v1.MetaInfo{}
+	}
+
+	return &logcache_v1.MetaResponse{
+		Meta: meta,
+	}, nil
+}
+
+func (s *spyMetaReader) called() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls
+}
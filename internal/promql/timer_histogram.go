@@ -0,0 +1,149 @@
+package promql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The suffixes of the series a timer is exposed as when timer histograms
+// are enabled. A timer named http is exposed as http_bucket, http_sum and
+// http_count, like a Prometheus histogram.
+const (
+	bucketSuffix = "_bucket"
+	sumSuffix    = "_sum"
+	countSuffix  = "_count"
+)
+
+// ParseTimerHistogramBuckets parses the upper bounds of timer histogram
+// buckets in seconds, e.g. 0.005 or 2.5. The bounds have to be ascending.
+// The +Inf bucket is always added and must not be given.
+func ParseTimerHistogramBuckets(values []string) ([]float64, error) {
+	buckets := make([]float64, 0, len(values))
+	for _, v := range values {
+		b, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket %q: %s", v, err)
+		}
+
+		if math.IsInf(b, 0) || math.IsNaN(b) {
+			return nil, fmt.Errorf("invalid bucket %q: must be finite", v)
+		}
+
+		if len(buckets) > 0 && b <= buckets[len(buckets)-1] {
+			return nil, fmt.Errorf("invalid bucket %q: buckets must be ascending", v)
+		}
+
+		buckets = append(buckets, b)
+	}
+
+	return buckets, nil
+}
+
+// timerHistograms builds the histogram series of one of the suffixes from
+// the timers of every series. The series are cumulative: each timer adds
+// its duration in seconds to the series of its tags, and the series get a
+// point with the totals so far at the time of the timer.
+type timerHistograms struct {
+	// name is the name of the timers the histograms are built from.
+	name    string
+	suffix  string
+	buckets []float64
+	series  map[string]*timerHistogram
+}
+
+type timerHistogram struct {
+	bucketCounts []uint64
+	count        uint64
+	sum          float64
+}
+
+// newTimerHistograms returns the histograms for the given metric. It returns
+// nil if there are no buckets or the metric does not have the suffix of a
+// histogram series.
+func newTimerHistograms(metric string, buckets []float64) *timerHistograms {
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	for _, suffix := range []string{bucketSuffix, sumSuffix, countSuffix} {
+		if len(metric) > len(suffix) && strings.HasSuffix(metric, suffix) {
+			return &timerHistograms{
+				name:    strings.TrimSuffix(metric, suffix),
+				suffix:  suffix,
+				buckets: buckets,
+				series:  make(map[string]*timerHistogram),
+			}
+		}
+	}
+
+	return nil
+}
+
+// observe adds the duration of a timer with the given tags. It calls emit
+// with the labels and value of each histogram series after the timer was
+// added.
+func (h *timerHistograms) observe(tags map[string]string, duration time.Duration, emit func(map[string]string, float64)) {
+	id := seriesID(tags)
+	hist, ok := h.series[id]
+	if !ok {
+		hist = &timerHistogram{
+			bucketCounts: make([]uint64, len(h.buckets)),
+		}
+		h.series[id] = hist
+	}
+
+	seconds := duration.Seconds()
+	for i, b := range h.buckets {
+		if seconds <= b {
+			hist.bucketCounts[i]++
+		}
+	}
+	hist.count++
+	hist.sum += seconds
+
+	switch h.suffix {
+	case bucketSuffix:
+		for i, b := range h.buckets {
+			emit(withLabel(tags, "le", formatBucket(b)), float64(hist.bucketCounts[i]))
+		}
+		emit(withLabel(tags, "le", "+Inf"), float64(hist.count))
+	case sumSuffix:
+		emit(tags, hist.sum)
+	case countSuffix:
+		emit(tags, float64(hist.count))
+	}
+}
+
+// timerHistogramLabels returns the label sets of the histogram series a
+// timer with the given tags is exposed as. The metric name of each label set
+// is set as well.
+func timerHistogramLabels(name string, tags map[string]string, buckets []float64) []map[string]string {
+	var result []map[string]string
+	for _, b := range buckets {
+		result = append(result, withLabel(withLabel(tags, "le", formatBucket(b)), "__name__", name+bucketSuffix))
+	}
+	result = append(result, withLabel(withLabel(tags, "le", "+Inf"), "__name__", name+bucketSuffix))
+	result = append(result, withLabel(tags, "__name__", name+sumSuffix))
+	result = append(result, withLabel(tags, "__name__", name+countSuffix))
+
+	return result
+}
+
+// formatBucket formats the upper bound of a bucket the way the Prometheus
+// client libraries do, e.g. 0.005 or 10.
+func formatBucket(b float64) string {
+	return strconv.FormatFloat(b, 'f', -1, 64)
+}
+
+func withLabel(tags map[string]string, name, value string) map[string]string {
+	m := make(map[string]string, len(tags)+1)
+	for k, v := range tags {
+		m[k] = v
+	}
+	m[name] = value
+
+	return m
+}
@@ -0,0 +1,231 @@
+package promql_test
+
+import (
+	"context"
+	"io/ioutil"
+	"log"
+	"regexp"
+	"time"
+
+	"code.cloudfoundry.org/go-loggregator/metrics/testhelpers"
+	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/internal/promql"
+	"code.cloudfoundry.org/log-cache/internal/testing"
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	upstream "github.com/prometheus/prometheus/promql"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Timer histograms", func() {
+	var (
+		spyDataReader *spyDataReader
+		q             *promql.PromQL
+	)
+
+	buildTimer := func(ts time.Time, instanceID string, duration time.Duration) *loggregator_v2.Envelope {
+		return &loggregator_v2.Envelope{
+			SourceId:   "some-id",
+			InstanceId: instanceID,
+			Timestamp:  ts.UnixNano(),
+			Message: &loggregator_v2.Envelope_Timer{
+				Timer: &loggregator_v2.Timer{
+					Name:  "http",
+					Start: 0,
+					Stop:  int64(duration),
+				},
+			},
+		}
+	}
+
+	instantQuery := func(query string, t time.Time) map[string]float64 {
+		r, err := q.InstantQuery(context.Background(), &logcache_v1.PromQL_InstantQueryRequest{
+			Time:  testing.FormatTimeWithDecimalMillis(t),
+			Query: query,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		result := make(map[string]float64)
+		for _, s := range r.GetVector().GetSamples() {
+			result[s.GetMetric()["le"]] = s.GetPoint().GetValue()
+		}
+
+		return result
+	}
+
+	BeforeEach(func() {
+		spyDataReader = newSpyDataReader()
+		q = promql.New(
+			spyDataReader,
+			testhelpers.NewMetricsRegistry(),
+			log.New(ioutil.Discard, "", 0),
+			5*time.Second,
+			promql.WithTimerHistogramBuckets([]float64{0.1, 0.5, 1}),
+		)
+	})
+
+	Context("with the timers of a single series", func() {
+		var now time.Time
+
+		BeforeEach(func() {
+			now = time.Now().Truncate(time.Second)
+			spyDataReader.setRead([][]*loggregator_v2.Envelope{{
+				buildTimer(now.Add(-4*time.Second), "0", 50*time.Millisecond),
+				buildTimer(now.Add(-3*time.Second), "0", 200*time.Millisecond),
+				buildTimer(now.Add(-2*time.Second), "0", 300*time.Millisecond),
+				buildTimer(now.Add(-1*time.Second), "0", 700*time.Millisecond),
+			}}, []error{nil})
+		})
+
+		It("exposes the cumulative count of each bucket", func() {
+			Expect(instantQuery(`http_bucket{source_id="some-id"}`, now)).To(Equal(map[string]float64{
+				"0.1":  1,
+				"0.5":  3,
+				"1":    4,
+				"+Inf": 4,
+			}))
+		})
+
+		It("selects buckets by their le label", func() {
+			Expect(instantQuery(`http_bucket{source_id="some-id", le="0.5"}`, now)).To(Equal(map[string]float64{
+				"0.5": 3,
+			}))
+		})
+
+		It("exposes the number of timers", func() {
+			Expect(instantQuery(`http_count{source_id="some-id"}`, now)).To(Equal(map[string]float64{
+				"": 4,
+			}))
+		})
+
+		It("exposes the sum of the durations in seconds", func() {
+			result := instantQuery(`http_sum{source_id="some-id"}`, now)
+			Expect(result).To(HaveLen(1))
+			Expect(result[""]).To(BeNumerically("~", 1.25, 1e-9))
+		})
+
+		It("exposes the totals at the time of each timer", func() {
+			Expect(instantQuery(`http_count{source_id="some-id"}`, now.Add(-3*time.Second))).To(Equal(map[string]float64{
+				"": 2,
+			}))
+		})
+
+		It("computes quantiles with histogram_quantile", func() {
+			result := instantQuery(`histogram_quantile(0.5, http_bucket{source_id="some-id"})`, now)
+			Expect(result).To(HaveLen(1))
+			Expect(result[""]).To(BeNumerically("~", 0.3, 1e-9))
+		})
+
+		It("reads both the histogram series and the timers", func() {
+			instantQuery(`http_bucket{source_id="some-id"}`, now)
+
+			filters := spyDataReader.ReadNameFilters()
+			Expect(filters).To(HaveLen(1))
+			nameFilter := regexp.MustCompile(filters[0])
+			Expect(nameFilter.MatchString("http")).To(BeTrue())
+			Expect(nameFilter.MatchString("http_bucket")).To(BeTrue())
+			Expect(nameFilter.MatchString("http_count")).To(BeFalse())
+		})
+
+		It("does not expose histograms without buckets", func() {
+			q = promql.New(
+				spyDataReader,
+				testhelpers.NewMetricsRegistry(),
+				log.New(ioutil.Discard, "", 0),
+				5*time.Second,
+			)
+
+			Expect(instantQuery(`http_bucket{source_id="some-id"}`, now)).To(BeEmpty())
+		})
+
+		It("lists the histogram series", func() {
+			r, err := q.LabelValues(context.Background(), &logcache_v1.PromQL_LabelValuesRequest{
+				Name:  "__name__",
+				Match: []string{`{source_id="some-id"}`},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(r.GetValues()).To(Equal([]string{"http", "http_bucket", "http_count", "http_sum"}))
+		})
+	})
+
+	It("keeps a histogram per series", func() {
+		now := time.Now().Truncate(time.Second)
+		spyDataReader.setRead([][]*loggregator_v2.Envelope{{
+			buildTimer(now.Add(-2*time.Second), "0", 50*time.Millisecond),
+			buildTimer(now.Add(-2*time.Second), "1", 700*time.Millisecond),
+			buildTimer(now.Add(-1*time.Second), "0", 50*time.Millisecond),
+		}}, []error{nil})
+
+		r, err := q.InstantQuery(context.Background(), &logcache_v1.PromQL_InstantQueryRequest{
+			Time:  testing.FormatTimeWithDecimalMillis(now),
+			Query: `http_count{source_id="some-id"}`,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		counts := make(map[string]float64)
+		for _, s := range r.GetVector().GetSamples() {
+			counts[s.GetMetric()["instance_id"]] = s.GetPoint().GetValue()
+		}
+		Expect(counts).To(Equal(map[string]float64{"0": 2, "1": 1}))
+	})
+
+	// The rate of the buckets is checked against upstream Prometheus by
+	// loading the expected bucket series into its own test storage.
+	It("computes quantiles of rates like Prometheus", func() {
+		upstreamTest, err := upstream.NewTest(GinkgoT(), `
+load 15s
+  http_bucket{source_id="some-id", instance_id="0", le="0.1"}  1 1 2 2 3
+  http_bucket{source_id="some-id", instance_id="0", le="0.5"}  1 2 3 4 5
+  http_bucket{source_id="some-id", instance_id="0", le="1"}    1 2 3 5 7
+  http_bucket{source_id="some-id", instance_id="0", le="+Inf"} 1 2 3 5 8
+`)
+		Expect(err).ToNot(HaveOccurred())
+		defer upstreamTest.Close()
+		Expect(upstreamTest.Run()).To(Succeed())
+
+		const query = `histogram_quantile(0.9, rate(http_bucket{source_id="some-id"}[1m]))`
+		t := time.Unix(60, 0)
+
+		qry, err := upstreamTest.QueryEngine().NewInstantQuery(upstreamTest.Queryable(), query, t)
+		Expect(err).ToNot(HaveOccurred())
+		res := qry.Exec(upstreamTest.Context())
+		Expect(res.Err).ToNot(HaveOccurred())
+		expected, err := res.Vector()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(expected).To(HaveLen(1))
+
+		spyDataReader.setRead([][]*loggregator_v2.Envelope{{
+			buildTimer(time.Unix(0, 0), "0", 50*time.Millisecond),
+			buildTimer(time.Unix(15, 0), "0", 200*time.Millisecond),
+			buildTimer(time.Unix(30, 0), "0", 10*time.Millisecond),
+			buildTimer(time.Unix(45, 0), "0", 800*time.Millisecond),
+			buildTimer(time.Unix(45, 0), "0", 300*time.Millisecond),
+			buildTimer(time.Unix(60, 0), "0", 2*time.Second),
+			buildTimer(time.Unix(60, 0), "0", 60*time.Millisecond),
+			buildTimer(time.Unix(60, 0), "0", 900*time.Millisecond),
+		}}, []error{nil})
+
+		result := instantQuery(query, t)
+		Expect(result).To(HaveLen(1))
+		Expect(result[""]).To(BeNumerically("~", expected[0].V, 1e-9))
+	})
+
+	DescribeTable("parses bucket upper bounds", func(values []string, expected []float64, valid bool) {
+		buckets, err := promql.ParseTimerHistogramBuckets(values)
+		if !valid {
+			Expect(err).To(HaveOccurred())
+			return
+		}
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buckets).To(Equal(expected))
+	},
+		Entry("ascending", []string{"0.005", " 0.5", "10"}, []float64{0.005, 0.5, 10}, true),
+		Entry("not a number", []string{"fast"}, nil, false),
+		Entry("not ascending", []string{"1", "0.5"}, nil, false),
+		Entry("duplicate", []string{"1", "1"}, nil, false),
+		Entry("infinite", []string{"+Inf"}, nil, false),
+	)
+})
@@ -0,0 +1,93 @@
+package routing
+
+import (
+	"context"
+	"log"
+
+	rpc "code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// AdminReverseProxy is a reverse proxy for Admin requests.
+type AdminReverseProxy struct {
+	clients  []rpc.AdminClient
+	l        Lookup
+	localIdx int
+	log      *log.Logger
+}
+
+// NewAdminReverseProxy returns a new AdminReverseProxy. LocalIdx is required
+// to know where to find the local node for local only requests.
+func NewAdminReverseProxy(
+	l Lookup,
+	clients []rpc.AdminClient,
+	localIdx int,
+	log *log.Logger,
+) *AdminReverseProxy {
+	return &AdminReverseProxy{
+		l:        l,
+		clients:  clients,
+		localIdx: localIdx,
+		log:      log,
+	}
+}
+
+// Purge deletes the envelopes of the source ID from every node that the
+// routing table assigns it to. It fails if any of the nodes fails, in which
+// case the purge can safely be retried. The remaining nodes are sent the
+// purge as well, so that they drop cached query results of the source ID.
+// Their failures are only logged.
+func (a *AdminReverseProxy) Purge(ctx context.Context, in *rpc.PurgeRequest) (*rpc.PurgeResponse, error) {
+	if in.LocalOnly {
+		return a.clients[a.localIdx].Purge(ctx, in)
+	}
+
+	idx := a.l(in.GetSourceId())
+	if len(idx) == 0 {
+		return nil, grpc.Errorf(codes.Unavailable, "failed to find route for request. please try again")
+	}
+
+	// Each node should only purge their local envelopes.
+	req := &rpc.PurgeRequest{
+		SourceId:  in.SourceId,
+		StartTime: in.StartTime,
+		EndTime:   in.EndTime,
+		LocalOnly: true,
+	}
+
+	resp := &rpc.PurgeResponse{}
+	for _, i := range idx {
+		r, err := a.clients[i].Purge(ctx, req)
+		if err != nil {
+			a.log.Printf("failed to purge %s from node %d: %s", in.GetSourceId(), i, err)
+			return nil, err
+		}
+
+		resp.Purged += r.Purged
+	}
+
+	for i := range a.clients {
+		if contains(idx, i) {
+			continue
+		}
+
+		if _, err := a.clients[i].Purge(ctx, req); err != nil {
+			a.log.Printf("failed to purge %s from node %d: %s", in.GetSourceId(), i, err)
+		}
+	}
+
+	a.log.Printf("purged %d envelopes of %s", resp.Purged, in.GetSourceId())
+
+	return resp, nil
+}
+
+func contains(idx []int, i int) bool {
+	for _, j := range idx {
+		if j == i {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,129 @@
+package routing_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+
+	"code.cloudfoundry.org/log-cache/internal/routing"
+	rpc "code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AdminReverseProxy", func() {
+	var (
+		spyLookup             *spyLookup
+		spyAdminLocalClient   *spyAdminClient
+		spyAdminRemoteClient1 *spyAdminClient
+		spyAdminRemoteClient2 *spyAdminClient
+		p                     *routing.AdminReverseProxy
+	)
+
+	BeforeEach(func() {
+		spyLookup = newSpyLookup()
+		spyAdminLocalClient = newSpyAdminClient()
+		spyAdminRemoteClient1 = newSpyAdminClient()
+		spyAdminRemoteClient2 = newSpyAdminClient()
+		p = routing.NewAdminReverseProxy(spyLookup.Lookup, []rpc.AdminClient{
+			spyAdminLocalClient,
+			spyAdminRemoteClient1,
+			spyAdminRemoteClient2,
+		}, 0, log.New(ioutil.Discard, "", 0))
+	})
+
+	It("purges the source ID from every replica", func() {
+		spyLookup.results["a"] = []int{1, 2}
+		spyAdminRemoteClient1.purged = 3
+		spyAdminRemoteClient2.purged = 2
+
+		resp, err := p.Purge(context.Background(), &rpc.PurgeRequest{
+			SourceId:  "a",
+			StartTime: 1,
+			EndTime:   2,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Purged).To(Equal(int64(5)))
+
+		expected := &rpc.PurgeRequest{
+			SourceId:  "a",
+			StartTime: 1,
+			EndTime:   2,
+			LocalOnly: true,
+		}
+		Expect(spyAdminRemoteClient1.reqs).To(ConsistOf(expected))
+		Expect(spyAdminRemoteClient2.reqs).To(ConsistOf(expected))
+	})
+
+	It("sends the purge to the nodes that do not hold the source ID", func() {
+		spyLookup.results["a"] = []int{1, 2}
+		spyAdminLocalClient.purged = 7
+
+		resp, err := p.Purge(context.Background(), &rpc.PurgeRequest{SourceId: "a"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Purged).To(BeZero())
+
+		Expect(spyAdminLocalClient.reqs).To(ConsistOf(&rpc.PurgeRequest{
+			SourceId:  "a",
+			LocalOnly: true,
+		}))
+	})
+
+	It("ignores failures of the nodes that do not hold the source ID", func() {
+		spyLookup.results["a"] = []int{1, 2}
+		spyAdminLocalClient.err = errors.New("some-error")
+
+		_, err := p.Purge(context.Background(), &rpc.PurgeRequest{SourceId: "a"})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("purges only the local node for local only requests", func() {
+		spyLookup.results["a"] = []int{1, 2}
+
+		_, err := p.Purge(context.Background(), &rpc.PurgeRequest{
+			SourceId:  "a",
+			LocalOnly: true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(spyAdminLocalClient.reqs).To(HaveLen(1))
+		Expect(spyAdminRemoteClient1.reqs).To(BeEmpty())
+		Expect(spyAdminRemoteClient2.reqs).To(BeEmpty())
+	})
+
+	It("returns an error if any of the replicas fails", func() {
+		spyLookup.results["a"] = []int{1, 2}
+		spyAdminRemoteClient2.err = errors.New("some-error")
+
+		_, err := p.Purge(context.Background(), &rpc.PurgeRequest{SourceId: "a"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an Unavailable error without a route", func() {
+		_, err := p.Purge(context.Background(), &rpc.PurgeRequest{SourceId: "a"})
+		Expect(grpc.Code(err)).To(Equal(codes.Unavailable))
+	})
+})
+
+type spyAdminClient struct {
+	reqs   []*rpc.PurgeRequest
+	purged int64
+	err    error
+}
+
+func newSpyAdminClient() *spyAdminClient {
+	return &spyAdminClient{}
+}
+
+func (s *spyAdminClient) Purge(ctx context.Context, in *rpc.PurgeRequest, opts ...grpc.CallOption) (*rpc.PurgeResponse, error) {
+	s.reqs = append(s.reqs, in)
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &rpc.PurgeResponse{Purged: s.purged}, nil
+}
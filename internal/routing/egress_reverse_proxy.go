@@ -3,6 +3,7 @@ package routing
 import (
 	"context"
 	"errors"
+	"io"
 	"log"
 	"math/rand"
 	"sync/atomic"
@@ -10,6 +11,7 @@ import (
 	"unsafe"
 
 	rpc "code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	"github.com/golang/protobuf/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 )
@@ -64,6 +66,73 @@ func (e *EgressReverseProxy) Read(ctx context.Context, in *rpc.ReadRequest) (*rp
 	return e.clients[idx[rand.Intn(len(idx))]].Read(ctx, in)
 }
 
+// ReadAggregate will either read the aggregate from the local node or
+// remote nodes.
+func (e *EgressReverseProxy) ReadAggregate(ctx context.Context, in *rpc.ReadAggregateRequest) (*rpc.ReadAggregateResponse, error) {
+	idx := e.l(in.GetSourceId())
+	if len(idx) == 0 {
+		return nil, grpc.Errorf(codes.Unavailable, "failed to find route for request. please try again")
+	}
+	for _, i := range idx {
+		if i == e.localIdx {
+			return e.clients[e.localIdx].ReadAggregate(ctx, in)
+		}
+	}
+	return e.clients[idx[rand.Intn(len(idx))]].ReadAggregate(ctx, in)
+}
+
+// ReadHistogram will either read the histogram from the local node or
+// remote nodes.
+func (e *EgressReverseProxy) ReadHistogram(ctx context.Context, in *rpc.ReadHistogramRequest) (*rpc.ReadHistogramResponse, error) {
+	idx := e.l(in.GetSourceId())
+	if len(idx) == 0 {
+		return nil, grpc.Errorf(codes.Unavailable, "failed to find route for request. please try again")
+	}
+	for _, i := range idx {
+		if i == e.localIdx {
+			return e.clients[e.localIdx].ReadHistogram(ctx, in)
+		}
+	}
+	return e.clients[idx[rand.Intn(len(idx))]].ReadHistogram(ctx, in)
+}
+
+// Tail streams new envelopes for a source ID from either the local node or
+// a remote node that holds the source ID.
+func (e *EgressReverseProxy) Tail(in *rpc.TailRequest, srv rpc.Egress_TailServer) error {
+	idx := e.l(in.GetSourceId())
+	if len(idx) == 0 {
+		return grpc.Errorf(codes.Unavailable, "failed to find route for request. please try again")
+	}
+
+	c := e.clients[idx[rand.Intn(len(idx))]]
+	for _, i := range idx {
+		if i == e.localIdx {
+			c = e.clients[e.localIdx]
+			break
+		}
+	}
+
+	stream, err := c.Tail(srv.Context(), in)
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := srv.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
 // Meta will gather meta from the local store and remote nodes.
 func (e *EgressReverseProxy) Meta(ctx context.Context, in *rpc.MetaRequest) (*rpc.MetaResponse, error) {
 	if in.LocalOnly {
@@ -74,7 +143,7 @@ func (e *EgressReverseProxy) Meta(ctx context.Context, in *rpc.MetaRequest) (*rp
 
 func (e *EgressReverseProxy) localMeta(ctx context.Context, in *rpc.MetaRequest) (*rpc.MetaResponse, error) {
 	cache := (*metaCache)(atomic.LoadPointer(&e.localMetaCache))
-	if !cache.expired() {
+	if !cache.expired() && cache.matches(in) {
 		return cache.metaResp, nil
 	}
 
@@ -86,6 +155,7 @@ func (e *EgressReverseProxy) localMeta(ctx context.Context, in *rpc.MetaRequest)
 	atomic.StorePointer(&e.localMetaCache, unsafe.Pointer(&metaCache{
 		duration:  e.metaCacheDuration,
 		timestamp: time.Now(),
+		metaReq:   proto.Clone(in).(*rpc.MetaRequest),
 		metaResp:  metaInfo,
 	}))
 
@@ -94,19 +164,30 @@ func (e *EgressReverseProxy) localMeta(ctx context.Context, in *rpc.MetaRequest)
 
 func (e *EgressReverseProxy) remoteMeta(ctx context.Context, in *rpc.MetaRequest) (*rpc.MetaResponse, error) {
 	cache := (*metaCache)(atomic.LoadPointer(&e.remoteMetaCache))
-	if !cache.expired() {
+	if !cache.expired() && cache.matches(in) {
 		return cache.metaResp, nil
 	}
 
-	// Each remote should only fetch their local meta data.
-	req := &rpc.MetaRequest{
-		LocalOnly: true,
+	query, err := NewMetaQuery(in)
+	if err != nil {
+		return nil, err
 	}
 
-	result := &rpc.MetaResponse{
-		Meta: make(map[string]*rpc.MetaInfo),
+	// Each remote should only fetch their local meta data. The remotes
+	// filter, sort and limit their own source IDs so that only the
+	// candidates for the requested page are merged.
+	req := &rpc.MetaRequest{
+		LocalOnly:      true,
+		SourceIdPrefix: in.SourceIdPrefix,
+		SourceIdRegex:  in.SourceIdRegex,
+		SortBy:         in.SortBy,
+		Descending:     in.Descending,
+		Limit:          in.Limit,
+		PageToken:      in.PageToken,
 	}
 
+	merged := make(map[string]*rpc.MetaInfo)
+
 	var errs []error
 	for _, c := range e.clients {
 		resp, err := c.Meta(ctx, req)
@@ -118,7 +199,7 @@ func (e *EgressReverseProxy) remoteMeta(ctx context.Context, in *rpc.MetaRequest
 		}
 
 		for sourceID, mi := range resp.Meta {
-			result.Meta[sourceID] = mi
+			merged[sourceID] = mi
 		}
 	}
 
@@ -126,9 +207,12 @@ func (e *EgressReverseProxy) remoteMeta(ctx context.Context, in *rpc.MetaRequest
 		return nil, errors.New("failed to read meta data from remote node")
 	}
 
+	result := query.Apply(merged)
+
 	atomic.StorePointer(&e.remoteMetaCache, unsafe.Pointer(&metaCache{
 		duration:  e.metaCacheDuration,
 		timestamp: time.Now(),
+		metaReq:   proto.Clone(in).(*rpc.MetaRequest),
 		metaResp:  result,
 	}))
 
@@ -148,6 +232,7 @@ func WithMetaCacheDuration(d time.Duration) EgressReverseProxyOption {
 type metaCache struct {
 	duration  time.Duration
 	timestamp time.Time
+	metaReq   *rpc.MetaRequest
 	metaResp  *rpc.MetaResponse
 }
 
@@ -158,3 +243,8 @@ func (c *metaCache) expired() bool {
 
 	return time.Now().After(c.timestamp.Add(c.duration))
 }
+
+// matches reports whether the cached response was for the given request.
+func (c *metaCache) matches(req *rpc.MetaRequest) bool {
+	return proto.Equal(c.metaReq, req)
+}
@@ -3,6 +3,7 @@ package routing_test
 import (
 	"context"
 	"errors"
+	"io"
 	"io/ioutil"
 	"log"
 	"time"
@@ -136,6 +137,117 @@ var _ = Describe("EgressReverseProxy", func() {
 		Expect(spyEgressLocalClient.ctxs[0].Done()).To(BeClosed())
 	})
 
+	Describe("Tail", func() {
+		It("streams responses from the client that holds the source ID", func() {
+			spyLookup.results["a"] = []int{1}
+			expected := []*rpc.TailResponse{
+				{Envelopes: &loggregator_v2.EnvelopeBatch{Batch: []*loggregator_v2.Envelope{{Timestamp: 1}}}},
+				{Envelopes: &loggregator_v2.EnvelopeBatch{Batch: []*loggregator_v2.Envelope{{Timestamp: 2}}}},
+			}
+			spyEgressRemoteClient1.tailResps = expected
+
+			srv := newSpyTailServer(context.Background())
+			err := p.Tail(&rpc.TailRequest{SourceId: "a"}, srv)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(srv.sent).To(Equal(expected))
+			Expect(spyEgressRemoteClient1.tailReqs).To(ConsistOf(&rpc.TailRequest{SourceId: "a"}))
+			Expect(spyEgressLocalClient.tailReqs).To(BeEmpty())
+		})
+
+		It("prefers the local client", func() {
+			spyLookup.results["a"] = []int{1, 0, 2}
+
+			srv := newSpyTailServer(context.Background())
+			err := p.Tail(&rpc.TailRequest{SourceId: "a"}, srv)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(spyEgressLocalClient.tailReqs).To(HaveLen(1))
+		})
+
+		It("uses the context of the stream", func() {
+			spyLookup.results["a"] = []int{0}
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := p.Tail(&rpc.TailRequest{SourceId: "a"}, newSpyTailServer(ctx))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(spyEgressLocalClient.ctxs[0].Done()).To(BeClosed())
+		})
+
+		It("returns an Unavailable error for an unroutable request", func() {
+			err := p.Tail(&rpc.TailRequest{SourceId: "c"}, newSpyTailServer(context.Background()))
+			Expect(grpc.Code(err)).To(Equal(codes.Unavailable))
+		})
+
+		It("returns an error if the stream fails", func() {
+			spyLookup.results["a"] = []int{0}
+			spyEgressLocalClient.tailErr = errors.New("some-error")
+
+			err := p.Tail(&rpc.TailRequest{SourceId: "a"}, newSpyTailServer(context.Background()))
+			Expect(err).To(MatchError("some-error"))
+		})
+	})
+
+	Describe("ReadAggregate", func() {
+		It("reads the aggregate from a remote node that holds the source ID", func() {
+			spyLookup.results["a"] = []int{1}
+			spyEgressRemoteClient1.aggregateResp = &rpc.ReadAggregateResponse{
+				Points: []*rpc.ReadAggregateResponse_Point{{Timestamp: 1, Value: 2}},
+			}
+
+			req := &rpc.ReadAggregateRequest{SourceId: "a", Name: "cpu", Step: 10}
+			resp, err := p.ReadAggregate(context.Background(), req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(resp.Points).To(HaveLen(1))
+			Expect(spyEgressRemoteClient1.aggregateReqs).To(ConsistOf(req))
+			Expect(spyEgressLocalClient.aggregateReqs).To(BeEmpty())
+		})
+
+		It("prefers the local client", func() {
+			spyLookup.results["a"] = []int{1, 0, 2}
+
+			_, err := p.ReadAggregate(context.Background(), &rpc.ReadAggregateRequest{SourceId: "a"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(spyEgressLocalClient.aggregateReqs).To(HaveLen(1))
+		})
+
+		It("returns an Unavailable error for an unroutable request", func() {
+			_, err := p.ReadAggregate(context.Background(), &rpc.ReadAggregateRequest{SourceId: "c"})
+			Expect(grpc.Code(err)).To(Equal(codes.Unavailable))
+		})
+	})
+
+	Describe("ReadHistogram", func() {
+		It("reads the histogram from a remote node that holds the source ID", func() {
+			spyLookup.results["a"] = []int{1}
+
+			req := &rpc.ReadHistogramRequest{SourceId: "a", Step: 10}
+			_, err := p.ReadHistogram(context.Background(), req)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(spyEgressRemoteClient1.histogramReqs).To(ConsistOf(req))
+			Expect(spyEgressLocalClient.histogramReqs).To(BeEmpty())
+		})
+
+		It("prefers the local client", func() {
+			spyLookup.results["a"] = []int{1, 0, 2}
+
+			_, err := p.ReadHistogram(context.Background(), &rpc.ReadHistogramRequest{SourceId: "a"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(spyEgressLocalClient.histogramReqs).To(HaveLen(1))
+		})
+
+		It("returns an Unavailable error for an unroutable request", func() {
+			_, err := p.ReadHistogram(context.Background(), &rpc.ReadHistogramRequest{SourceId: "c"})
+			Expect(grpc.Code(err)).To(Equal(codes.Unavailable))
+		})
+	})
+
 	It("returns an error if the clients returns an error", func() {
 		spyEgressLocalClient.err = errors.New("some-error")
 
@@ -236,6 +348,54 @@ var _ = Describe("EgressReverseProxy", func() {
 		Expect(spyEgressRemoteClient1.metaRequests).To(ConsistOf(&rpc.MetaRequest{LocalOnly: true}))
 	})
 
+	It("merges the pages that each node selects", func() {
+		spyEgressLocalClient.metaResults = map[string]*rpc.MetaInfo{
+			"source-1": {Count: 9},
+			"source-2": {Count: 2},
+		}
+		spyEgressRemoteClient1.metaResults = map[string]*rpc.MetaInfo{
+			"source-3": {Count: 5},
+		}
+
+		req := &rpc.MetaRequest{
+			SourceIdRegex: "^source-",
+			SortBy:        rpc.MetaSortBy_COUNT,
+			Descending:    true,
+			Limit:         2,
+		}
+		resp, err := p.Meta(context.Background(), req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Meta).To(HaveLen(2))
+		Expect(resp.Meta).To(HaveKey("source-1"))
+		Expect(resp.Meta).To(HaveKey("source-3"))
+		Expect(resp.NextPageToken).ToNot(BeEmpty())
+
+		remoteReq := &rpc.MetaRequest{
+			LocalOnly:     true,
+			SourceIdRegex: "^source-",
+			SortBy:        rpc.MetaSortBy_COUNT,
+			Descending:    true,
+			Limit:         2,
+		}
+		Expect(spyEgressLocalClient.metaRequests).To(ConsistOf(remoteReq))
+		Expect(spyEgressRemoteClient1.metaRequests).To(ConsistOf(remoteReq))
+
+		req.PageToken = resp.NextPageToken
+		resp, err = p.Meta(context.Background(), req)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Meta).To(HaveLen(1))
+		Expect(resp.Meta).To(HaveKey("source-2"))
+		Expect(resp.NextPageToken).To(BeEmpty())
+	})
+
+	It("returns an error for an invalid page token", func() {
+		_, err := p.Meta(context.Background(), &rpc.MetaRequest{
+			PageToken: "invalid",
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(spyEgressRemoteClient1.metaCalls).To(BeZero())
+	})
+
 	It("gets sourceIds from the cache rather than the meta store", func() {
 		spyEgressLocalClient.metaResults = map[string]*rpc.MetaInfo{}
 		spyEgressRemoteClient1.metaResults = map[string]*rpc.MetaInfo{}
@@ -250,6 +410,19 @@ var _ = Describe("EgressReverseProxy", func() {
 		Expect(spyEgressRemoteClient1.metaCalls).To(Equal(1))
 	})
 
+	It("does not use the cache for a different request", func() {
+		spyEgressLocalClient.metaResults = map[string]*rpc.MetaInfo{}
+		spyEgressRemoteClient1.metaResults = map[string]*rpc.MetaInfo{}
+
+		_, err := p.Meta(context.Background(), &rpc.MetaRequest{})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = p.Meta(context.Background(), &rpc.MetaRequest{SourceIdPrefix: "app-"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(spyEgressRemoteClient1.metaCalls).To(Equal(2))
+	})
+
 	It("gets sourceIds from the cache rather than the meta store with local only", func() {
 		spyEgressLocalClient.metaResults = map[string]*rpc.MetaInfo{
 			"source-1": {},
@@ -322,11 +495,21 @@ type spyEgressClient struct {
 	metaRequests []*rpc.MetaRequest
 	metaResults  map[string]*rpc.MetaInfo
 	metaErr      error
+
+	tailReqs  []*rpc.TailRequest
+	tailResps []*rpc.TailResponse
+	tailErr   error
+
+	aggregateReqs []*rpc.ReadAggregateRequest
+	aggregateResp *rpc.ReadAggregateResponse
+
+	histogramReqs []*rpc.ReadHistogramRequest
 }
 
 func newSpyEgressClient() *spyEgressClient {
 	return &spyEgressClient{
-		readResp: &rpc.ReadResponse{},
+		readResp:      &rpc.ReadResponse{},
+		aggregateResp: &rpc.ReadAggregateResponse{},
 	}
 }
 
@@ -336,6 +519,18 @@ func (s *spyEgressClient) Read(ctx context.Context, in *rpc.ReadRequest, opts ..
 	return s.readResp, s.err
 }
 
+func (s *spyEgressClient) ReadAggregate(ctx context.Context, in *rpc.ReadAggregateRequest, opts ...grpc.CallOption) (*rpc.ReadAggregateResponse, error) {
+	s.ctxs = append(s.ctxs, ctx)
+	s.aggregateReqs = append(s.aggregateReqs, in)
+	return s.aggregateResp, s.err
+}
+
+func (s *spyEgressClient) ReadHistogram(ctx context.Context, in *rpc.ReadHistogramRequest, opts ...grpc.CallOption) (*rpc.ReadHistogramResponse, error) {
+	s.ctxs = append(s.ctxs, ctx)
+	s.histogramReqs = append(s.histogramReqs, in)
+	return &rpc.ReadHistogramResponse{}, s.err
+}
+
 func (s *spyEgressClient) Meta(ctx context.Context, r *rpc.MetaRequest, opts ...grpc.CallOption) (*rpc.MetaResponse, error) {
 	s.metaCalls += 1
 	s.ctxs = append(s.ctxs, ctx)
@@ -353,3 +548,54 @@ func (s *spyEgressClient) Meta(ctx context.Context, r *rpc.MetaRequest, opts ...
 		Meta: metaInfo,
 	}, nil
 }
+
+func (s *spyEgressClient) Tail(ctx context.Context, in *rpc.TailRequest, opts ...grpc.CallOption) (rpc.Egress_TailClient, error) {
+	s.ctxs = append(s.ctxs, ctx)
+	s.tailReqs = append(s.tailReqs, in)
+	return &spyTailClient{
+		resps: s.tailResps,
+		err:   s.tailErr,
+	}, nil
+}
+
+type spyTailClient struct {
+	grpc.ClientStream
+
+	resps []*rpc.TailResponse
+	err   error
+}
+
+func (s *spyTailClient) Recv() (*rpc.TailResponse, error) {
+	if len(s.resps) == 0 {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+
+	resp := s.resps[0]
+	s.resps = s.resps[1:]
+	return resp, nil
+}
+
+type spyTailServer struct {
+	grpc.ServerStream
+
+	ctx  context.Context
+	sent []*rpc.TailResponse
+}
+
+func newSpyTailServer(ctx context.Context) *spyTailServer {
+	return &spyTailServer{
+		ctx: ctx,
+	}
+}
+
+func (s *spyTailServer) Send(resp *rpc.TailResponse) error {
+	s.sent = append(s.sent, resp)
+	return nil
+}
+
+func (s *spyTailServer) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,59 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	"google.golang.org/grpc"
+)
+
+// LocalStoreAdmin accesses a store via gRPC calls. It handles converting
+// the requests into a form that the store understands for managing its
+// data.
+type LocalStoreAdmin struct {
+	s StorePurger
+}
+
+// StorePurger deletes envelopes from a store.
+type StorePurger interface {
+	// Purge deletes the envelopes of the source ID within [start..end) and
+	// returns how many were deleted.
+	Purge(sourceID string, start, end time.Time) (int, error)
+}
+
+// NewLocalStoreAdmin creates and returns a new LocalStoreAdmin.
+func NewLocalStoreAdmin(s StorePurger) *LocalStoreAdmin {
+	return &LocalStoreAdmin{
+		s: s,
+	}
+}
+
+// Purge deletes envelopes from the store.
+func (a *LocalStoreAdmin) Purge(ctx context.Context, req *logcache_v1.PurgeRequest, opts ...grpc.CallOption) (*logcache_v1.PurgeResponse, error) {
+	if req.GetSourceId() == "" {
+		return nil, fmt.Errorf("SourceId is required")
+	}
+
+	if req.EndTime != 0 && req.StartTime > req.EndTime {
+		return nil, fmt.Errorf("StartTime (%d) must be before EndTime (%d)", req.StartTime, req.EndTime)
+	}
+
+	// Without an end time, envelopes with timestamps in the future are
+	// purged as well.
+	end := req.EndTime
+	if end == 0 {
+		end = math.MaxInt64
+	}
+
+	purged, err := a.s.Purge(req.GetSourceId(), time.Unix(0, req.StartTime), time.Unix(0, end))
+	if err != nil {
+		return nil, err
+	}
+
+	return &logcache_v1.PurgeResponse{
+		Purged: int64(purged),
+	}, nil
+}
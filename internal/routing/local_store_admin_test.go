@@ -0,0 +1,93 @@
+package routing_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"code.cloudfoundry.org/log-cache/internal/routing"
+	rpc "code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LocalStoreAdmin", func() {
+	var (
+		spyStorePurger *spyStorePurger
+		a              *routing.LocalStoreAdmin
+	)
+
+	BeforeEach(func() {
+		spyStorePurger = newSpyStorePurger()
+		a = routing.NewLocalStoreAdmin(spyStorePurger)
+	})
+
+	It("purges the time range from the store", func() {
+		spyStorePurger.purged = 7
+
+		resp, err := a.Purge(context.Background(), &rpc.PurgeRequest{
+			SourceId:  "some-source",
+			StartTime: 99,
+			EndTime:   101,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.Purged).To(Equal(int64(7)))
+
+		Expect(spyStorePurger.sourceID).To(Equal("some-source"))
+		Expect(spyStorePurger.start.UnixNano()).To(Equal(int64(99)))
+		Expect(spyStorePurger.end.UnixNano()).To(Equal(int64(101)))
+	})
+
+	It("purges every envelope without an end time", func() {
+		_, err := a.Purge(context.Background(), &rpc.PurgeRequest{
+			SourceId: "some-source",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(spyStorePurger.start.UnixNano()).To(BeZero())
+		Expect(spyStorePurger.end.UnixNano()).To(Equal(int64(math.MaxInt64)))
+	})
+
+	It("returns an error for invalid requests", func() {
+		_, err := a.Purge(context.Background(), &rpc.PurgeRequest{})
+		Expect(err).To(HaveOccurred())
+
+		_, err = a.Purge(context.Background(), &rpc.PurgeRequest{
+			SourceId:  "some-source",
+			StartTime: 2,
+			EndTime:   1,
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(spyStorePurger.sourceID).To(BeEmpty())
+	})
+
+	It("returns an error if the store fails", func() {
+		spyStorePurger.err = errors.New("some-error")
+
+		_, err := a.Purge(context.Background(), &rpc.PurgeRequest{
+			SourceId: "some-source",
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+type spyStorePurger struct {
+	sourceID string
+	start    time.Time
+	end      time.Time
+	purged   int
+	err      error
+}
+
+func newSpyStorePurger() *spyStorePurger {
+	return &spyStorePurger{}
+}
+
+func (s *spyStorePurger) Purge(sourceID string, start, end time.Time) (int, error) {
+	s.sourceID = sourceID
+	s.start = start
+	s.end = end
+	return s.purged, s.err
+}
@@ -1,14 +1,17 @@
 package routing
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"time"
 
 	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/internal/cache/store"
 	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
 // LocalStoreReader accesses a store via gRPC calls. It handles converting the
@@ -20,19 +23,48 @@ type LocalStoreReader struct {
 // StoreReader proxies to the log cache for getting envelopes or Log Cache
 // Metadata.
 type StoreReader interface {
-	// Gets envelopes from a local or remote Log Cache.
-	Get(
+	// Gets envelopes from a local or remote Log Cache after the given
+	// cursor, along with the cursor after the last envelope.
+	GetPage(
 		sourceID string,
 		start time.Time,
 		end time.Time,
-		envelopeTypes []logcache_v1.EnvelopeType,
-		nameFilter *regexp.Regexp,
-		limit int,
-		descending bool,
-	) []*loggregator_v2.Envelope
+		opts store.ReadOptions,
+		after *store.Cursor,
+	) ([]*loggregator_v2.Envelope, *store.Cursor)
+
+	// Aggregate downsamples the values of a metric into buckets of the
+	// given step.
+	Aggregate(
+		sourceID string,
+		start time.Time,
+		end time.Time,
+		name string,
+		step time.Duration,
+		aggregation logcache_v1.ReadAggregateRequest_Aggregation,
+	) []*logcache_v1.ReadAggregateResponse_Point
+
+	// Histogram counts the envelopes in buckets of the given step, split
+	// by envelope type and the given grouping.
+	Histogram(
+		sourceID string,
+		start time.Time,
+		end time.Time,
+		step time.Duration,
+		groupBy logcache_v1.ReadHistogramRequest_GroupBy,
+	) []*logcache_v1.ReadHistogramResponse_Bucket
 
 	// Meta gets the metadata from Log Cache instances in the cluster.
 	Meta() map[string]logcache_v1.MetaInfo
+
+	// Subscribe passes new envelopes for the source ID to f until the
+	// returned function is invoked.
+	Subscribe(
+		sourceID string,
+		envelopeTypes []logcache_v1.EnvelopeType,
+		nameFilter *regexp.Regexp,
+		f func(e *loggregator_v2.Envelope),
+	) (unsubscribe func())
 }
 
 // NewLocalStoreReader creates and returns a new LocalStoreReader.
@@ -73,20 +105,60 @@ func (r *LocalStoreReader) Read(ctx context.Context, req *logcache_v1.ReadReques
 		}
 	}
 
+	var payloadFilter *regexp.Regexp
+	if req.PayloadFilter != "" {
+		expr := req.PayloadFilter
+		if !req.PayloadFilterRegex {
+			expr = regexp.QuoteMeta(expr)
+		}
+
+		if req.PayloadFilterCaseInsensitive {
+			expr = "(?i)" + expr
+		}
+
+		payloadFilter, err = regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("Payload filter must be a valid regular expression: %s", err)
+		}
+	}
+
+	var tagMatchers []store.TagMatcher
+	for _, tm := range req.GetTagMatchers() {
+		m, err := store.ParseTagMatcher(tm)
+		if err != nil {
+			return nil, err
+		}
+		tagMatchers = append(tagMatchers, m)
+	}
+
+	var after *store.Cursor
+	if req.PageToken != "" {
+		after, err = decodeReadPageToken(req.PageToken, req.Descending)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var envelopeTypes []logcache_v1.EnvelopeType
 	for _, e := range req.GetEnvelopeTypes() {
 		if e != logcache_v1.EnvelopeType_ANY {
 			envelopeTypes = append(envelopeTypes, e)
 		}
 	}
-	envs := r.s.Get(
+	envs, next := r.s.GetPage(
 		req.SourceId,
 		time.Unix(0, req.StartTime),
 		time.Unix(0, req.EndTime),
-		envelopeTypes,
-		nameFilter,
-		int(req.Limit),
-		req.Descending,
+		store.ReadOptions{
+			EnvelopeTypes: envelopeTypes,
+			NameFilter:    nameFilter,
+			PayloadFilter: payloadFilter,
+			InstanceID:    req.InstanceId,
+			TagMatchers:   tagMatchers,
+			Limit:         int(req.Limit),
+			Descending:    req.Descending,
+		},
+		after,
 	)
 	resp := &logcache_v1.ReadResponse{
 		Envelopes: &loggregator_v2.EnvelopeBatch{
@@ -94,10 +166,77 @@ func (r *LocalStoreReader) Read(ctx context.Context, req *logcache_v1.ReadReques
 		},
 	}
 
+	if next != nil {
+		resp.NextPageToken = encodeReadPageToken(req.Descending, next)
+	}
+
 	return resp, nil
 }
 
+// ReadAggregate returns the downsampled values of a metric from the store.
+func (r *LocalStoreReader) ReadAggregate(ctx context.Context, req *logcache_v1.ReadAggregateRequest, opts ...grpc.CallOption) (*logcache_v1.ReadAggregateResponse, error) {
+	if req.EndTime != 0 && req.StartTime > req.EndTime {
+		return nil, fmt.Errorf("StartTime (%d) must be before EndTime (%d)", req.StartTime, req.EndTime)
+	}
+
+	if req.Step <= 0 {
+		return nil, fmt.Errorf("Step (%d) must be greater than zero", req.Step)
+	}
+
+	if req.Name == "" {
+		return nil, errors.New("Name is required")
+	}
+
+	if req.EndTime == 0 {
+		req.EndTime = time.Now().UnixNano()
+	}
+
+	points := r.s.Aggregate(
+		req.SourceId,
+		time.Unix(0, req.StartTime),
+		time.Unix(0, req.EndTime),
+		req.Name,
+		time.Duration(req.Step),
+		req.Aggregation,
+	)
+
+	return &logcache_v1.ReadAggregateResponse{
+		Points: points,
+	}, nil
+}
+
+func (r *LocalStoreReader) ReadHistogram(ctx context.Context, req *logcache_v1.ReadHistogramRequest, opts ...grpc.CallOption) (*logcache_v1.ReadHistogramResponse, error) {
+	if req.EndTime != 0 && req.StartTime > req.EndTime {
+		return nil, fmt.Errorf("StartTime (%d) must be before EndTime (%d)", req.StartTime, req.EndTime)
+	}
+
+	if req.Step <= 0 {
+		return nil, fmt.Errorf("Step (%d) must be greater than zero", req.Step)
+	}
+
+	if req.EndTime == 0 {
+		req.EndTime = time.Now().UnixNano()
+	}
+
+	buckets := r.s.Histogram(
+		req.SourceId,
+		time.Unix(0, req.StartTime),
+		time.Unix(0, req.EndTime),
+		time.Duration(req.Step),
+		req.GroupBy,
+	)
+
+	return &logcache_v1.ReadHistogramResponse{
+		Buckets: buckets,
+	}, nil
+}
+
 func (r *LocalStoreReader) Meta(ctx context.Context, req *logcache_v1.MetaRequest, opts ...grpc.CallOption) (*logcache_v1.MetaResponse, error) {
+	query, err := NewMetaQuery(req)
+	if err != nil {
+		return nil, err
+	}
+
 	sourceIds := r.s.Meta()
 
 	metaInfo := make(map[string]*logcache_v1.MetaInfo)
@@ -108,7 +247,112 @@ func (r *LocalStoreReader) Meta(ctx context.Context, req *logcache_v1.MetaReques
 		metaInfo[sourceId] = &m
 	}
 
-	return &logcache_v1.MetaResponse{
-		Meta: metaInfo,
+	return query.Apply(metaInfo), nil
+}
+
+// Tail subscribes to the store and returns a stream of the envelopes that
+// are written to it for the requested source ID. The stream ends once the
+// context is done. Envelopes are dropped if the stream is not read fast
+// enough.
+func (r *LocalStoreReader) Tail(ctx context.Context, req *logcache_v1.TailRequest, opts ...grpc.CallOption) (logcache_v1.Egress_TailClient, error) {
+	var nameFilter *regexp.Regexp
+	if req.NameFilter != "" {
+		var err error
+		nameFilter, err = regexp.Compile(req.NameFilter)
+		if err != nil {
+			return nil, fmt.Errorf("Name filter must be a valid regular expression: %s", err)
+		}
+	}
+
+	var envelopeTypes []logcache_v1.EnvelopeType
+	for _, e := range req.GetEnvelopeTypes() {
+		if e != logcache_v1.EnvelopeType_ANY {
+			envelopeTypes = append(envelopeTypes, e)
+		}
+	}
+
+	envelopes := make(chan *loggregator_v2.Envelope, tailBufferSize)
+	unsubscribe := r.s.Subscribe(req.SourceId, envelopeTypes, nameFilter, func(e *loggregator_v2.Envelope) {
+		select {
+		case envelopes <- e:
+		default:
+		}
+	})
+
+	go func() {
+		<-ctx.Done()
+		unsubscribe()
+	}()
+
+	return &localTailClient{
+		ctx:       ctx,
+		envelopes: envelopes,
 	}, nil
 }
+
+const (
+	tailBufferSize   = 1000
+	tailMaxBatchSize = 100
+)
+
+// localTailClient implements logcache_v1.Egress_TailClient for
+// subscriptions to the local store.
+type localTailClient struct {
+	ctx       context.Context
+	envelopes chan *loggregator_v2.Envelope
+}
+
+// Recv blocks until at least one envelope is available and returns every
+// envelope that is available, up to tailMaxBatchSize.
+func (c *localTailClient) Recv() (*logcache_v1.TailResponse, error) {
+	var batch []*loggregator_v2.Envelope
+	select {
+	case e := <-c.envelopes:
+		batch = append(batch, e)
+	case <-c.ctx.Done():
+		return nil, c.ctx.Err()
+	}
+
+	for len(batch) < tailMaxBatchSize {
+		select {
+		case e := <-c.envelopes:
+			batch = append(batch, e)
+		default:
+			return c.response(batch), nil
+		}
+	}
+
+	return c.response(batch), nil
+}
+
+func (c *localTailClient) response(batch []*loggregator_v2.Envelope) *logcache_v1.TailResponse {
+	return &logcache_v1.TailResponse{
+		Envelopes: &loggregator_v2.EnvelopeBatch{
+			Batch: batch,
+		},
+	}
+}
+
+func (c *localTailClient) Context() context.Context {
+	return c.ctx
+}
+
+func (c *localTailClient) Header() (metadata.MD, error) {
+	return nil, nil
+}
+
+func (c *localTailClient) Trailer() metadata.MD {
+	return nil
+}
+
+func (c *localTailClient) CloseSend() error {
+	return nil
+}
+
+func (c *localTailClient) SendMsg(m interface{}) error {
+	return errors.New("localTailClient does not support SendMsg")
+}
+
+func (c *localTailClient) RecvMsg(m interface{}) error {
+	return errors.New("localTailClient does not support RecvMsg, use Recv")
+}
@@ -2,9 +2,11 @@ package routing_test
 
 import (
 	"regexp"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/go-loggregator/rpc/loggregator_v2"
+	"code.cloudfoundry.org/log-cache/internal/cache/store"
 	"code.cloudfoundry.org/log-cache/internal/routing"
 	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
 	"golang.org/x/net/context"
@@ -51,6 +53,132 @@ var _ = Describe("LocalStoreReader", func() {
 		Expect(spyStoreReader.descending).To(BeTrue())
 	})
 
+	It("resumes a read from the page token", func() {
+		spyStoreReader.getEnvelopes = []*loggregator_v2.Envelope{
+			{Timestamp: 1},
+			{Timestamp: 2},
+		}
+		spyStoreReader.nextCursor = &store.Cursor{Timestamp: 2, Seq: 3}
+
+		resp, err := r.Read(context.Background(), &logcache_v1.ReadRequest{
+			SourceId: "some-source",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spyStoreReader.after).To(BeNil())
+		Expect(resp.NextPageToken).ToNot(BeEmpty())
+
+		_, err = r.Read(context.Background(), &logcache_v1.ReadRequest{
+			SourceId:  "some-source",
+			PageToken: resp.NextPageToken,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spyStoreReader.after).To(Equal(&store.Cursor{Timestamp: 2, Seq: 3}))
+
+		_, err = r.Read(context.Background(), &logcache_v1.ReadRequest{
+			SourceId:   "some-source",
+			PageToken:  resp.NextPageToken,
+			Descending: true,
+		})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("does not return a page token after the last page", func() {
+		resp, err := r.Read(context.Background(), &logcache_v1.ReadRequest{
+			SourceId: "some-source",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.NextPageToken).To(BeEmpty())
+	})
+
+	It("returns an error for an invalid page token", func() {
+		for _, token := range []string{"!", "aW52YWxpZA", "ZmFsc2U6eDox"} {
+			_, err := r.Read(context.Background(), &logcache_v1.ReadRequest{
+				SourceId:  "some-source",
+				PageToken: token,
+			})
+			Expect(err).To(HaveOccurred())
+		}
+	})
+
+	It("reads an aggregate from the store", func() {
+		spyStoreReader.aggregatePoints = []*logcache_v1.ReadAggregateResponse_Point{
+			{Timestamp: 90, Value: 1},
+		}
+
+		resp, err := r.ReadAggregate(context.Background(), &logcache_v1.ReadAggregateRequest{
+			SourceId:    "some-source",
+			StartTime:   99,
+			EndTime:     100,
+			Name:        "cpu",
+			Step:        10,
+			Aggregation: logcache_v1.ReadAggregateRequest_MAX,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(resp.Points).To(Equal(spyStoreReader.aggregatePoints))
+		Expect(spyStoreReader.sourceID).To(Equal("some-source"))
+		Expect(spyStoreReader.start.UnixNano()).To(Equal(int64(99)))
+		Expect(spyStoreReader.end.UnixNano()).To(Equal(int64(100)))
+		Expect(spyStoreReader.aggregateName).To(Equal("cpu"))
+		Expect(spyStoreReader.aggregateStep).To(Equal(10 * time.Nanosecond))
+		Expect(spyStoreReader.aggregateAggregation).To(Equal(logcache_v1.ReadAggregateRequest_MAX))
+	})
+
+	It("defaults the aggregate end time to now", func() {
+		_, err := r.ReadAggregate(context.Background(), &logcache_v1.ReadAggregateRequest{
+			SourceId: "some-source",
+			Name:     "cpu",
+			Step:     10,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spyStoreReader.end.UnixNano()).To(BeNumerically("~", time.Now().UnixNano(), time.Second))
+	})
+
+	It("returns an error for an invalid aggregate request", func() {
+		for _, req := range []*logcache_v1.ReadAggregateRequest{
+			{SourceId: "some-source", Step: 10},
+			{SourceId: "some-source", Name: "cpu"},
+			{SourceId: "some-source", Name: "cpu", Step: -1},
+			{SourceId: "some-source", Name: "cpu", Step: 10, StartTime: 2, EndTime: 1},
+		} {
+			_, err := r.ReadAggregate(context.Background(), req)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+
+	It("reads a histogram from the store", func() {
+		spyStoreReader.histogramBuckets = []*logcache_v1.ReadHistogramResponse_Bucket{
+			{Timestamp: 90},
+		}
+
+		resp, err := r.ReadHistogram(context.Background(), &logcache_v1.ReadHistogramRequest{
+			SourceId:  "some-source",
+			StartTime: 99,
+			EndTime:   100,
+			Step:      10,
+			GroupBy:   logcache_v1.ReadHistogramRequest_LOG_TYPE,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(resp.Buckets).To(Equal(spyStoreReader.histogramBuckets))
+		Expect(spyStoreReader.sourceID).To(Equal("some-source"))
+		Expect(spyStoreReader.start.UnixNano()).To(Equal(int64(99)))
+		Expect(spyStoreReader.end.UnixNano()).To(Equal(int64(100)))
+		Expect(spyStoreReader.histogramStep).To(Equal(10 * time.Nanosecond))
+		Expect(spyStoreReader.histogramGroupBy).To(Equal(logcache_v1.ReadHistogramRequest_LOG_TYPE))
+	})
+
+	It("returns an error for an invalid histogram request", func() {
+		for _, req := range []*logcache_v1.ReadHistogramRequest{
+			{SourceId: "some-source"},
+			{SourceId: "some-source", Step: -1},
+			{SourceId: "some-source", Step: 10, StartTime: 2, EndTime: 1},
+		} {
+			_, err := r.ReadHistogram(context.Background(), req)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+
 	It("does not set the envelope type for an ANY", func() {
 		_, err := r.Read(context.Background(), &logcache_v1.ReadRequest{
 			SourceId:      "some-source",
@@ -100,6 +228,68 @@ var _ = Describe("LocalStoreReader", func() {
 		Expect(spyStoreReader.nameFilter.String()).To(Equal(".*foo.*"))
 	})
 
+	It("passes a literal payload filter through to the store", func() {
+		_, err := r.Read(context.Background(), &logcache_v1.ReadRequest{
+			SourceId:      "some-source",
+			PayloadFilter: "GET /v2/apps",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spyStoreReader.payloadFilter.MatchString("GET /v2/apps/1")).To(BeTrue())
+		Expect(spyStoreReader.payloadFilter.MatchString("GET /v2/appsX")).To(BeTrue())
+		Expect(spyStoreReader.payloadFilter.MatchString("GET /v2/Apps")).To(BeFalse())
+		Expect(spyStoreReader.payloadFilter.MatchString("GET .v2.apps")).To(BeFalse())
+	})
+
+	It("passes a case-insensitive regex payload filter through to the store", func() {
+		_, err := r.Read(context.Background(), &logcache_v1.ReadRequest{
+			SourceId:                     "some-source",
+			PayloadFilter:                "status=5[0-9]{2}",
+			PayloadFilterRegex:           true,
+			PayloadFilterCaseInsensitive: true,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spyStoreReader.payloadFilter.MatchString("STATUS=503")).To(BeTrue())
+		Expect(spyStoreReader.payloadFilter.MatchString("status=404")).To(BeFalse())
+	})
+
+	It("does not pass a payload filter to the store if none is given", func() {
+		_, err := r.Read(context.Background(), &logcache_v1.ReadRequest{
+			SourceId: "some-source",
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spyStoreReader.payloadFilter).To(BeNil())
+	})
+
+	It("passes the instance ID and tag matchers through to the store", func() {
+		_, err := r.Read(context.Background(), &logcache_v1.ReadRequest{
+			SourceId:    "some-source",
+			InstanceId:  "3",
+			TagMatchers: []string{"deployment=cf", "job!~diego.*"},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(spyStoreReader.instanceID).To(Equal("3"))
+		Expect(spyStoreReader.tagMatchers).To(HaveLen(2))
+		Expect(spyStoreReader.tagMatchers[0].String()).To(Equal("deployment=cf"))
+		Expect(spyStoreReader.tagMatchers[1].String()).To(Equal("job!~diego.*"))
+	})
+
+	It("returns an error if a tag matcher is invalid", func() {
+		_, err := r.Read(context.Background(), &logcache_v1.ReadRequest{
+			SourceId:    "some-source",
+			TagMatchers: []string{"deployment"},
+		})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error if the payload filter is an invalid regex", func() {
+		_, err := r.Read(context.Background(), &logcache_v1.ReadRequest{
+			SourceId:           "some-source",
+			PayloadFilter:      "(",
+			PayloadFilterRegex: true,
+		})
+		Expect(err).To(HaveOccurred())
+	})
+
 	It("returns an error if the end time is before the start time", func() {
 		_, err := r.Read(context.Background(), &logcache_v1.ReadRequest{
 			SourceId:      "some-source",
@@ -142,6 +332,55 @@ var _ = Describe("LocalStoreReader", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	Describe("Tail", func() {
+		It("streams envelopes that the store passes on", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			stream, err := r.Tail(ctx, &logcache_v1.TailRequest{
+				SourceId:      "some-source",
+				EnvelopeTypes: []logcache_v1.EnvelopeType{logcache_v1.EnvelopeType_ANY, logcache_v1.EnvelopeType_GAUGE},
+				NameFilter:    "cpu.*",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(spyStoreReader.subscribedSourceID).To(Equal("some-source"))
+			Expect(spyStoreReader.subscribedEnvelopeTypes).To(ConsistOf(logcache_v1.EnvelopeType_GAUGE))
+			Expect(spyStoreReader.subscribedNameFilter).To(Equal(regexp.MustCompile("cpu.*")))
+
+			spyStoreReader.publish(&loggregator_v2.Envelope{Timestamp: 1})
+			spyStoreReader.publish(&loggregator_v2.Envelope{Timestamp: 2})
+
+			resp, err := stream.Recv()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.GetEnvelopes().GetBatch()).To(Equal([]*loggregator_v2.Envelope{
+				{Timestamp: 1},
+				{Timestamp: 2},
+			}))
+		})
+
+		It("unsubscribes and ends the stream once the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+
+			stream, err := r.Tail(ctx, &logcache_v1.TailRequest{SourceId: "some-source"})
+			Expect(err).ToNot(HaveOccurred())
+
+			cancel()
+			Eventually(spyStoreReader.isUnsubscribed).Should(BeTrue())
+
+			_, err = stream.Recv()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("returns an error for an invalid name filter", func() {
+			_, err := r.Tail(context.Background(), &logcache_v1.TailRequest{
+				SourceId:   "some-source",
+				NameFilter: "[",
+			})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	It("returns local source IDs from the store", func() {
 		spyStoreReader.metaResponse = map[string]logcache_v1.MetaInfo{
 			"source-1": {
@@ -180,6 +419,36 @@ var _ = Describe("LocalStoreReader", func() {
 			},
 		}))
 	})
+
+	It("filters, sorts and limits the local source IDs", func() {
+		spyStoreReader.metaResponse = map[string]logcache_v1.MetaInfo{
+			"app-1":   {Count: 3},
+			"app-2":   {Count: 9},
+			"app-3":   {Count: 5},
+			"doppler": {Count: 100},
+		}
+
+		metaInfo, err := r.Meta(context.Background(), &logcache_v1.MetaRequest{
+			LocalOnly:      true,
+			SourceIdPrefix: "app-",
+			SortBy:         logcache_v1.MetaSortBy_COUNT,
+			Descending:     true,
+			Limit:          2,
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(metaInfo.Meta).To(HaveLen(2))
+		Expect(metaInfo.Meta).To(HaveKey("app-2"))
+		Expect(metaInfo.Meta).To(HaveKey("app-3"))
+		Expect(metaInfo.NextPageToken).ToNot(BeEmpty())
+	})
+
+	It("returns an error for an invalid source ID regex", func() {
+		_, err := r.Meta(context.Background(), &logcache_v1.MetaRequest{
+			SourceIdRegex: "[",
+		})
+		Expect(err).To(HaveOccurred())
+	})
 })
 
 type spyStoreReader struct {
@@ -192,33 +461,123 @@ type spyStoreReader struct {
 	limit         int
 	descending    bool
 	nameFilter    *regexp.Regexp
+	payloadFilter *regexp.Regexp
+	instanceID    string
+	tagMatchers   []store.TagMatcher
 	metaResponse  map[string]logcache_v1.MetaInfo
+	after         *store.Cursor
+	nextCursor    *store.Cursor
+
+	aggregateName        string
+	aggregateStep        time.Duration
+	aggregateAggregation logcache_v1.ReadAggregateRequest_Aggregation
+	aggregatePoints      []*logcache_v1.ReadAggregateResponse_Point
+
+	histogramStep    time.Duration
+	histogramGroupBy logcache_v1.ReadHistogramRequest_GroupBy
+	histogramBuckets []*logcache_v1.ReadHistogramResponse_Bucket
+
+	mu                      sync.Mutex
+	subscribedSourceID      string
+	subscribedEnvelopeTypes []logcache_v1.EnvelopeType
+	subscribedNameFilter    *regexp.Regexp
+	subscriber              func(e *loggregator_v2.Envelope)
+	unsubscribed            bool
 }
 
 func newSpyStoreReader() *spyStoreReader {
 	return &spyStoreReader{}
 }
 
-func (s *spyStoreReader) Get(
+func (s *spyStoreReader) GetPage(
 	sourceID string,
 	start time.Time,
 	end time.Time,
-	envelopeTypes []logcache_v1.EnvelopeType,
-	nameFilter *regexp.Regexp,
-	limit int,
-	descending bool,
-) []*loggregator_v2.Envelope {
+	opts store.ReadOptions,
+	after *store.Cursor,
+) ([]*loggregator_v2.Envelope, *store.Cursor) {
+	s.sourceID = sourceID
+	s.start = start
+	s.end = end
+	s.envelopeTypes = opts.EnvelopeTypes
+	s.nameFilter = opts.NameFilter
+	s.payloadFilter = opts.PayloadFilter
+	s.instanceID = opts.InstanceID
+	s.tagMatchers = opts.TagMatchers
+	s.limit = opts.Limit
+	s.descending = opts.Descending
+	s.after = after
+
+	return s.getEnvelopes, s.nextCursor
+}
+
+func (s *spyStoreReader) Aggregate(
+	sourceID string,
+	start time.Time,
+	end time.Time,
+	name string,
+	step time.Duration,
+	aggregation logcache_v1.ReadAggregateRequest_Aggregation,
+) []*logcache_v1.ReadAggregateResponse_Point {
+	s.sourceID = sourceID
+	s.start = start
+	s.end = end
+	s.aggregateName = name
+	s.aggregateStep = step
+	s.aggregateAggregation = aggregation
+
+	return s.aggregatePoints
+}
+
+func (s *spyStoreReader) Histogram(
+	sourceID string,
+	start time.Time,
+	end time.Time,
+	step time.Duration,
+	groupBy logcache_v1.ReadHistogramRequest_GroupBy,
+) []*logcache_v1.ReadHistogramResponse_Bucket {
 	s.sourceID = sourceID
 	s.start = start
 	s.end = end
-	s.envelopeTypes = envelopeTypes
-	s.nameFilter = nameFilter
-	s.limit = limit
-	s.descending = descending
+	s.histogramStep = step
+	s.histogramGroupBy = groupBy
 
-	return s.getEnvelopes
+	return s.histogramBuckets
 }
 
 func (s *spyStoreReader) Meta() map[string]logcache_v1.MetaInfo {
 	return s.metaResponse
 }
+
+func (s *spyStoreReader) Subscribe(
+	sourceID string,
+	envelopeTypes []logcache_v1.EnvelopeType,
+	nameFilter *regexp.Regexp,
+	f func(e *loggregator_v2.Envelope),
+) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.subscribedSourceID = sourceID
+	s.subscribedEnvelopeTypes = envelopeTypes
+	s.subscribedNameFilter = nameFilter
+	s.subscriber = f
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.unsubscribed = true
+	}
+}
+
+func (s *spyStoreReader) publish(e *loggregator_v2.Envelope) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriber(e)
+}
+
+func (s *spyStoreReader) isUnsubscribed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.unsubscribed
+}
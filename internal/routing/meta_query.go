@@ -0,0 +1,172 @@
+package routing
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+)
+
+// MetaQuery filters, sorts and pages meta information as described by a
+// MetaRequest. Applying a query to the meta information of each node and
+// then to the merged results gives the same page as applying it to the meta
+// information of the whole cluster.
+type MetaQuery struct {
+	prefix     string
+	regex      *regexp.Regexp
+	sortBy     logcache_v1.MetaSortBy
+	descending bool
+	limit      int
+	after      *metaCursor
+}
+
+// metaCursor is the position of the last source ID of a page.
+type metaCursor struct {
+	value    int64
+	sourceID string
+}
+
+// NewMetaQuery returns a MetaQuery for the given request. It returns an
+// error if the regular expression or the page token are invalid.
+func NewMetaQuery(req *logcache_v1.MetaRequest) (*MetaQuery, error) {
+	if req.GetLimit() < 0 {
+		return nil, fmt.Errorf("Limit (%d) must not be negative", req.GetLimit())
+	}
+
+	q := &MetaQuery{
+		prefix:     req.GetSourceIdPrefix(),
+		sortBy:     req.GetSortBy(),
+		descending: req.GetDescending(),
+		limit:      int(req.GetLimit()),
+	}
+
+	if req.GetSourceIdRegex() != "" {
+		var err error
+		q.regex, err = regexp.Compile(req.GetSourceIdRegex())
+		if err != nil {
+			return nil, fmt.Errorf("Source ID regex must be a valid regular expression: %s", err)
+		}
+	}
+
+	if req.GetPageToken() != "" {
+		var err error
+		q.after, err = q.decodePageToken(req.GetPageToken())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return q, nil
+}
+
+// Apply returns the page of the given meta information that the query
+// selects.
+func (q *MetaQuery) Apply(meta map[string]*logcache_v1.MetaInfo) *logcache_v1.MetaResponse {
+	var cursors []metaCursor
+	for sourceID, m := range meta {
+		if !strings.HasPrefix(sourceID, q.prefix) {
+			continue
+		}
+
+		if q.regex != nil && !q.regex.MatchString(sourceID) {
+			continue
+		}
+
+		c := metaCursor{value: q.value(m), sourceID: sourceID}
+		if q.after != nil && !q.less(*q.after, c) {
+			continue
+		}
+
+		cursors = append(cursors, c)
+	}
+
+	resp := &logcache_v1.MetaResponse{
+		Meta: make(map[string]*logcache_v1.MetaInfo),
+	}
+
+	if q.limit > 0 && len(cursors) > q.limit {
+		sort.Slice(cursors, func(i, j int) bool {
+			return q.less(cursors[i], cursors[j])
+		})
+
+		cursors = cursors[:q.limit]
+		resp.NextPageToken = q.encodePageToken(cursors[q.limit-1])
+	}
+
+	for _, c := range cursors {
+		resp.Meta[c.sourceID] = meta[c.sourceID]
+	}
+
+	return resp
+}
+
+func (q *MetaQuery) value(m *logcache_v1.MetaInfo) int64 {
+	switch q.sortBy {
+	case logcache_v1.MetaSortBy_COUNT:
+		return m.GetCount()
+	case logcache_v1.MetaSortBy_NEWEST_TIMESTAMP:
+		return m.GetNewestTimestamp()
+	case logcache_v1.MetaSortBy_EXPIRED:
+		return m.GetExpired()
+	default:
+		return 0
+	}
+}
+
+// less reports whether a comes before b in the order of the query.
+func (q *MetaQuery) less(a, b metaCursor) bool {
+	if q.descending {
+		a, b = b, a
+	}
+
+	if a.value != b.value {
+		return a.value < b.value
+	}
+
+	return a.sourceID < b.sourceID
+}
+
+// encodePageToken encodes the cursor along with the order it was taken in.
+func (q *MetaQuery) encodePageToken(c metaCursor) string {
+	token := fmt.Sprintf("%d:%t:%d:%s", q.sortBy, q.descending, c.value, c.sourceID)
+	return base64.RawURLEncoding.EncodeToString([]byte(token))
+}
+
+func (q *MetaQuery) decodePageToken(token string) (*metaCursor, error) {
+	invalid := fmt.Errorf("Page token (%s) is invalid", token)
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+
+	parts := strings.SplitN(string(b), ":", 4)
+	if len(parts) != 4 {
+		return nil, invalid
+	}
+
+	sortBy, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, invalid
+	}
+
+	descending, err := strconv.ParseBool(parts[1])
+	if err != nil {
+		return nil, invalid
+	}
+
+	if logcache_v1.MetaSortBy(sortBy) != q.sortBy || descending != q.descending {
+		return nil, fmt.Errorf("Page token (%s) does not match the sort order", token)
+	}
+
+	value, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+
+	return &metaCursor{value: value, sourceID: parts[3]}, nil
+}
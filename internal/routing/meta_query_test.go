@@ -0,0 +1,127 @@
+package routing_test
+
+import (
+	"sort"
+
+	"code.cloudfoundry.org/log-cache/internal/routing"
+	rpc "code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MetaQuery", func() {
+	var meta map[string]*rpc.MetaInfo
+
+	BeforeEach(func() {
+		meta = map[string]*rpc.MetaInfo{
+			"app-a":   {Count: 5, NewestTimestamp: 30, Expired: 1},
+			"app-b":   {Count: 5, NewestTimestamp: 10, Expired: 7},
+			"app-c":   {Count: 1, NewestTimestamp: 20, Expired: 3},
+			"doppler": {Count: 9, NewestTimestamp: 40, Expired: 0},
+		}
+	})
+
+	apply := func(req *rpc.MetaRequest) *rpc.MetaResponse {
+		q, err := routing.NewMetaQuery(req)
+		Expect(err).ToNot(HaveOccurred())
+		return q.Apply(meta)
+	}
+
+	It("returns every source ID by default", func() {
+		resp := apply(&rpc.MetaRequest{})
+		Expect(resp.Meta).To(Equal(meta))
+		Expect(resp.NextPageToken).To(BeEmpty())
+	})
+
+	It("filters by prefix and regex", func() {
+		resp := apply(&rpc.MetaRequest{SourceIdPrefix: "app-"})
+		Expect(resp.Meta).To(HaveLen(3))
+		Expect(resp.Meta).ToNot(HaveKey("doppler"))
+
+		resp = apply(&rpc.MetaRequest{SourceIdPrefix: "app-", SourceIdRegex: "[bc]$"})
+		Expect(resp.Meta).To(HaveLen(2))
+		Expect(resp.Meta).To(HaveKey("app-b"))
+		Expect(resp.Meta).To(HaveKey("app-c"))
+	})
+
+	It("pages through every source ID in order", func() {
+		pages := func(req *rpc.MetaRequest) [][]string {
+			var pages [][]string
+			for {
+				resp := apply(req)
+
+				var page []string
+				for sourceID := range resp.Meta {
+					page = append(page, sourceID)
+				}
+				sort.Strings(page)
+				pages = append(pages, page)
+
+				if resp.NextPageToken == "" {
+					return pages
+				}
+				req.PageToken = resp.NextPageToken
+			}
+		}
+
+		Expect(pages(&rpc.MetaRequest{
+			SortBy:     rpc.MetaSortBy_COUNT,
+			Descending: true,
+			Limit:      2,
+		})).To(Equal([][]string{
+			{"app-b", "doppler"},
+			{"app-a", "app-c"},
+		}), "ties are ordered by source ID")
+
+		Expect(pages(&rpc.MetaRequest{
+			SortBy: rpc.MetaSortBy_NEWEST_TIMESTAMP,
+			Limit:  3,
+		})).To(Equal([][]string{
+			{"app-a", "app-b", "app-c"},
+			{"doppler"},
+		}))
+
+		Expect(pages(&rpc.MetaRequest{
+			SortBy: rpc.MetaSortBy_EXPIRED,
+			Limit:  1,
+		})).To(Equal([][]string{
+			{"doppler"}, {"app-a"}, {"app-c"}, {"app-b"},
+		}))
+	})
+
+	It("continues after the page token when the source IDs change", func() {
+		req := &rpc.MetaRequest{Limit: 2}
+		resp := apply(req)
+		Expect(resp.Meta).To(HaveLen(2))
+		Expect(resp.Meta).To(HaveKey("app-a"))
+		Expect(resp.Meta).To(HaveKey("app-b"))
+
+		delete(meta, "app-b")
+		meta["app-0"] = &rpc.MetaInfo{}
+
+		req.PageToken = resp.NextPageToken
+		resp = apply(req)
+		Expect(resp.Meta).To(HaveLen(2))
+		Expect(resp.Meta).To(HaveKey("app-c"))
+		Expect(resp.Meta).To(HaveKey("doppler"))
+	})
+
+	It("returns an error for invalid requests", func() {
+		q, err := routing.NewMetaQuery(&rpc.MetaRequest{SortBy: rpc.MetaSortBy_COUNT, Limit: 1})
+		Expect(err).ToNot(HaveOccurred())
+		token := q.Apply(meta).NextPageToken
+
+		for _, req := range []*rpc.MetaRequest{
+			{SourceIdRegex: "["},
+			{Limit: -1},
+			{PageToken: "!"},
+			{PageToken: "aW52YWxpZA"},
+			{SortBy: rpc.MetaSortBy_EXPIRED, PageToken: token},
+			{SortBy: rpc.MetaSortBy_COUNT, Descending: true, PageToken: token},
+		} {
+			_, err := routing.NewMetaQuery(req)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+})
@@ -0,0 +1,54 @@
+package routing
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/log-cache/internal/cache/store"
+)
+
+// encodeReadPageToken encodes the cursor along with the direction of the
+// read it was taken in.
+func encodeReadPageToken(descending bool, c *store.Cursor) string {
+	token := fmt.Sprintf("%t:%d:%d", descending, c.Timestamp, c.Seq)
+	return base64.RawURLEncoding.EncodeToString([]byte(token))
+}
+
+// decodeReadPageToken returns the cursor of the token. It returns an error
+// if the token was taken in the other direction.
+func decodeReadPageToken(token string, descending bool) (*store.Cursor, error) {
+	invalid := fmt.Errorf("Page token (%s) is invalid", token)
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, invalid
+	}
+
+	parts := strings.Split(string(b), ":")
+	if len(parts) != 3 {
+		return nil, invalid
+	}
+
+	d, err := strconv.ParseBool(parts[0])
+	if err != nil {
+		return nil, invalid
+	}
+
+	if d != descending {
+		return nil, fmt.Errorf("Page token (%s) does not match the order", token)
+	}
+
+	timestamp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+
+	seq, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+
+	return &store.Cursor{Timestamp: timestamp, Seq: seq}, nil
+}
@@ -72,10 +72,17 @@ type SpyLogCache struct {
 	localOnlyValues    []bool
 	envelopes          []*loggregator_v2.Envelope
 	readRequests       []*rpc.ReadRequest
+	tailRequests       []*rpc.TailRequest
 	queryRequests      []*rpc.PromQL_InstantQueryRequest
 	QueryError         error
 	rangeQueryRequests []*rpc.PromQL_RangeQueryRequest
+	labelsRequests     []*rpc.PromQL_LabelsRequest
+	seriesRequests     []*rpc.PromQL_SeriesRequest
+	purgeRequests      []*rpc.PurgeRequest
+	aggregateRequests  []*rpc.ReadAggregateRequest
+	histogramRequests  []*rpc.ReadHistogramRequest
 	ReadEnvelopes      map[string]func() []*loggregator_v2.Envelope
+	TailEnvelopes      map[string][]*loggregator_v2.Envelope
 	MetaResponses      map[string]*rpc.MetaInfo
 	tlsConfig          *tls.Config
 	value              float64
@@ -90,6 +97,7 @@ func (s *SpyLogCache) SetValue(value float64) {
 func NewSpyLogCache(tlsConfig *tls.Config) *SpyLogCache {
 	return &SpyLogCache{
 		ReadEnvelopes: make(map[string]func() []*loggregator_v2.Envelope),
+		TailEnvelopes: make(map[string][]*loggregator_v2.Envelope),
 		tlsConfig:     tlsConfig,
 		value:         101,
 	}
@@ -107,6 +115,7 @@ func (s *SpyLogCache) Start() string {
 	rpc.RegisterIngressServer(srv, s)
 	rpc.RegisterEgressServer(srv, s)
 	rpc.RegisterPromQLQuerierServer(srv, s)
+	rpc.RegisterAdminServer(srv, s)
 	go srv.Serve(lis)
 
 	return lis.Addr().String()
@@ -169,12 +178,106 @@ func (s *SpyLogCache) Read(ctx context.Context, r *rpc.ReadRequest) (*rpc.ReadRe
 	}, nil
 }
 
+func (s *SpyLogCache) GetReadAggregateRequests() []*rpc.ReadAggregateRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := make([]*rpc.ReadAggregateRequest, len(s.aggregateRequests))
+	copy(r, s.aggregateRequests)
+	return r
+}
+
+func (s *SpyLogCache) ReadAggregate(ctx context.Context, r *rpc.ReadAggregateRequest) (*rpc.ReadAggregateResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.aggregateRequests = append(s.aggregateRequests, r)
+
+	return &rpc.ReadAggregateResponse{
+		Points: []*rpc.ReadAggregateResponse_Point{
+			{Timestamp: r.GetStartTime(), Value: s.value},
+		},
+	}, nil
+}
+
+func (s *SpyLogCache) GetReadHistogramRequests() []*rpc.ReadHistogramRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := make([]*rpc.ReadHistogramRequest, len(s.histogramRequests))
+	copy(r, s.histogramRequests)
+	return r
+}
+
+func (s *SpyLogCache) ReadHistogram(ctx context.Context, r *rpc.ReadHistogramRequest) (*rpc.ReadHistogramResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.histogramRequests = append(s.histogramRequests, r)
+
+	return &rpc.ReadHistogramResponse{
+		Buckets: []*rpc.ReadHistogramResponse_Bucket{
+			{
+				Timestamp: r.GetStartTime(),
+				Counts: []*rpc.ReadHistogramResponse_Count{
+					{EnvelopeType: rpc.EnvelopeType_LOG, Count: 1},
+				},
+			},
+		},
+	}, nil
+}
+
+func (s *SpyLogCache) GetTailRequests() []*rpc.TailRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := make([]*rpc.TailRequest, len(s.tailRequests))
+	copy(r, s.tailRequests)
+	return r
+}
+
+// Tail sends each of the TailEnvelopes for the source ID in its own
+// response and then ends the stream.
+func (s *SpyLogCache) Tail(r *rpc.TailRequest, srv rpc.Egress_TailServer) error {
+	s.mu.Lock()
+	s.tailRequests = append(s.tailRequests, r)
+	envelopes := s.TailEnvelopes[r.GetSourceId()]
+	s.mu.Unlock()
+
+	for _, e := range envelopes {
+		err := srv.Send(&rpc.TailResponse{
+			Envelopes: &loggregator_v2.EnvelopeBatch{
+				Batch: []*loggregator_v2.Envelope{e},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *SpyLogCache) Meta(ctx context.Context, r *rpc.MetaRequest) (*rpc.MetaResponse, error) {
 	return &rpc.MetaResponse{
 		Meta: s.MetaResponses,
 	}, nil
 }
 
+func (s *SpyLogCache) GetPurgeRequests() []*rpc.PurgeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := make([]*rpc.PurgeRequest, len(s.purgeRequests))
+	copy(r, s.purgeRequests)
+	return r
+}
+
+func (s *SpyLogCache) Purge(ctx context.Context, r *rpc.PurgeRequest) (*rpc.PurgeResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.purgeRequests = append(s.purgeRequests, r)
+
+	return &rpc.PurgeResponse{}, nil
+}
+
 func (s *SpyLogCache) InstantQuery(ctx context.Context, r *rpc.PromQL_InstantQueryRequest) (*rpc.PromQL_InstantQueryResult, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -238,6 +341,60 @@ func (s *SpyLogCache) GetRangeQueryRequests() []*rpc.PromQL_RangeQueryRequest {
 	return r
 }
 
+func (s *SpyLogCache) Labels(ctx context.Context, r *rpc.PromQL_LabelsRequest) (*rpc.PromQL_LabelsResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.labelsRequests = append(s.labelsRequests, r)
+
+	return &rpc.PromQL_LabelsResult{
+		Labels: []string{"__name__", "source_id"},
+	}, s.QueryError
+}
+
+func (s *SpyLogCache) GetLabelsRequests() []*rpc.PromQL_LabelsRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := make([]*rpc.PromQL_LabelsRequest, len(s.labelsRequests))
+	copy(r, s.labelsRequests)
+
+	return r
+}
+
+func (s *SpyLogCache) LabelValues(ctx context.Context, r *rpc.PromQL_LabelValuesRequest) (*rpc.PromQL_LabelValuesResult, error) {
+	return &rpc.PromQL_LabelValuesResult{
+		Values: []string{"test"},
+	}, s.QueryError
+}
+
+func (s *SpyLogCache) Series(ctx context.Context, r *rpc.PromQL_SeriesRequest) (*rpc.PromQL_SeriesResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seriesRequests = append(s.seriesRequests, r)
+
+	return &rpc.PromQL_SeriesResult{
+		Series: []*rpc.PromQL_LabelSet{
+			{
+				Labels: map[string]string{
+					"__name__": "test",
+				},
+			},
+		},
+	}, s.QueryError
+}
+
+func (s *SpyLogCache) GetSeriesRequests() []*rpc.PromQL_SeriesRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := make([]*rpc.PromQL_SeriesRequest, len(s.seriesRequests))
+	copy(r, s.seriesRequests)
+
+	return r
+}
+
 func StubUptimeFn() int64 {
 	return 789
 }
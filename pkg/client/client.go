@@ -103,18 +103,33 @@ func (c *Client) Read(
 	start time.Time,
 	opts ...ReadOption,
 ) ([]*loggregator_v2.Envelope, error) {
+	envelopes, _, err := c.ReadPage(ctx, sourceID, start, opts...)
+	return envelopes, err
+}
+
+// ReadPage behaves like Read but also returns the token of the next page.
+// Passing the token back via WithPageToken resumes the read after the last
+// envelope, including any envelopes that share its timestamp. The token is
+// only set if the response reached the limit; an empty token means the read
+// is complete.
+func (c *Client) ReadPage(
+	ctx context.Context,
+	sourceID string,
+	start time.Time,
+	opts ...ReadOption,
+) ([]*loggregator_v2.Envelope, string, error) {
 	if c.grpcClient != nil {
 		return c.grpcRead(ctx, sourceID, start, opts)
 	}
 
 	u, err := url.Parse(c.addr)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	baseApiPath, err := c.getBaseApiPath(ctx)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	u.Path = fmt.Sprintf("%s/read/%s", baseApiPath, sourceID)
@@ -129,26 +144,26 @@ func (c *Client) Read(
 
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	req = req.WithContext(ctx)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		return nil, "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
 	}
 
 	var r logcache_v1.ReadResponse
 	if err := jsonpb.Unmarshal(resp.Body, &r); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	return r.GetEnvelopes().GetBatch(), nil
+	return r.GetEnvelopes().GetBatch(), r.GetNextPageToken(), nil
 }
 
 // ReadOption configures the URL that is used to submit the query. The
@@ -196,7 +211,62 @@ func WithNameFilter(nameFilter string) ReadOption {
 	}
 }
 
-func (c *Client) grpcRead(ctx context.Context, sourceID string, start time.Time, opts []ReadOption) ([]*loggregator_v2.Envelope, error) {
+// WithPayloadFilter sets the 'payload_filter' query parameter to the given
+// value. Only log envelopes with a payload containing the value are
+// returned. It defaults to empty, and therefore no payload filtering.
+func WithPayloadFilter(payloadFilter string) ReadOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("payload_filter", payloadFilter)
+	}
+}
+
+// WithPayloadFilterRegex sets the 'payload_filter_regex' query parameter to
+// true. The payload filter is then treated as a RE2 regular expression
+// instead of a substring.
+func WithPayloadFilterRegex() ReadOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("payload_filter_regex", "true")
+	}
+}
+
+// WithPayloadFilterCaseInsensitive sets the
+// 'payload_filter_case_insensitive' query parameter to true. It defaults to
+// false, yielding case-sensitive matching.
+func WithPayloadFilterCaseInsensitive() ReadOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("payload_filter_case_insensitive", "true")
+	}
+}
+
+// WithInstanceID sets the 'instance_id' query parameter to the given value.
+// Only envelopes with the given instance ID are returned. It defaults to
+// empty, and therefore any instance ID.
+func WithInstanceID(instanceID string) ReadOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("instance_id", instanceID)
+	}
+}
+
+// WithTagMatcher adds to the 'tag_matchers' query parameter. Only envelopes
+// with a tag that satisfies every matcher are returned. The operator is one
+// of =, !=, =~ or !~. Regular expressions are anchored on both ends.
+func WithTagMatcher(name, operator, value string) ReadOption {
+	return func(u *url.URL, q url.Values) {
+		q.Add("tag_matchers", name+operator+value)
+	}
+}
+
+// WithPageToken sets the 'page_token' query parameter to a token returned
+// by ReadPage. The read resumes after the last envelope of that page. It
+// replaces the start time of ascending reads and the end time of
+// descending reads.
+func WithPageToken(token string) ReadOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("page_token", token)
+	}
+}
+
+func (c *Client) grpcRead(ctx context.Context, sourceID string, start time.Time, opts []ReadOption) ([]*loggregator_v2.Envelope, string, error) {
 	u := &url.URL{}
 	q := u.Query()
 	// allow the given options to configure the URL.
@@ -231,17 +301,164 @@ func (c *Client) grpcRead(ctx context.Context, sourceID string, start time.Time,
 		req.Descending = true
 	}
 
+	if v, ok := q["payload_filter"]; ok {
+		req.PayloadFilter = v[0]
+	}
+
+	if _, ok := q["payload_filter_regex"]; ok {
+		req.PayloadFilterRegex = true
+	}
+
+	if _, ok := q["payload_filter_case_insensitive"]; ok {
+		req.PayloadFilterCaseInsensitive = true
+	}
+
+	if v, ok := q["instance_id"]; ok {
+		req.InstanceId = v[0]
+	}
+
+	req.TagMatchers = q["tag_matchers"]
+
+	if v, ok := q["page_token"]; ok {
+		req.PageToken = v[0]
+	}
+
 	resp, err := c.grpcClient.Read(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+	return resp.Envelopes.Batch, resp.NextPageToken, nil
+}
+
+// ReadAggregate returns the values of the counters, gauge metrics or timers
+// with the given name, downsampled by the LogCache into buckets of the given
+// step. The values are aggregated by taking the last value of each bucket
+// unless WithAggregation is given.
+func (c *Client) ReadAggregate(
+	ctx context.Context,
+	sourceID string,
+	name string,
+	start time.Time,
+	step time.Duration,
+	opts ...AggregateOption,
+) ([]*logcache_v1.ReadAggregateResponse_Point, error) {
+	if c.grpcClient != nil {
+		return c.grpcReadAggregate(ctx, sourceID, name, start, step, opts)
+	}
+
+	u, err := url.Parse(c.addr)
 	if err != nil {
 		return nil, err
 	}
-	return resp.Envelopes.Batch, nil
+
+	baseApiPath, err := c.getBaseApiPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Path = fmt.Sprintf("%s/aggregate/%s", baseApiPath, sourceID)
+	q := u.Query()
+	q.Set("name", name)
+	q.Set("start_time", strconv.FormatInt(start.UnixNano(), 10))
+	q.Set("step", strconv.FormatInt(int64(step), 10))
+
+	// allow the given options to configure the URL.
+	for _, o := range opts {
+		o(u, q)
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var r logcache_v1.ReadAggregateResponse
+	if err := jsonpb.Unmarshal(resp.Body, &r); err != nil {
+		return nil, err
+	}
+
+	return r.GetPoints(), nil
 }
 
-// Meta returns meta information from the entire LogCache.
-func (c *Client) Meta(ctx context.Context) (map[string]*logcache_v1.MetaInfo, error) {
+// AggregateOption configures the URL that is used to read an aggregate. The
+// RawQuery is set to the decoded query parameters after each option is
+// invoked.
+type AggregateOption func(u *url.URL, q url.Values)
+
+// WithAggregateEndTime sets the 'end_time' query parameter to the given
+// time. It defaults to empty, and therefore the current time.
+func WithAggregateEndTime(t time.Time) AggregateOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("end_time", strconv.FormatInt(t.UnixNano(), 10))
+	}
+}
+
+// WithAggregation sets the 'aggregation' query parameter to the given
+// value. It defaults to LAST.
+func WithAggregation(a logcache_v1.ReadAggregateRequest_Aggregation) AggregateOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("aggregation", a.String())
+	}
+}
+
+func (c *Client) grpcReadAggregate(
+	ctx context.Context,
+	sourceID string,
+	name string,
+	start time.Time,
+	step time.Duration,
+	opts []AggregateOption,
+) ([]*logcache_v1.ReadAggregateResponse_Point, error) {
+	u := &url.URL{}
+	q := u.Query()
+	// allow the given options to configure the URL.
+	for _, o := range opts {
+		o(u, q)
+	}
+
+	req := &logcache_v1.ReadAggregateRequest{
+		SourceId:    sourceID,
+		Name:        name,
+		StartTime:   start.UnixNano(),
+		Step:        int64(step),
+		Aggregation: logcache_v1.ReadAggregateRequest_Aggregation(logcache_v1.ReadAggregateRequest_Aggregation_value[q.Get("aggregation")]),
+	}
+
+	if v, ok := q["end_time"]; ok {
+		req.EndTime, _ = strconv.ParseInt(v[0], 10, 64)
+	}
+
+	resp, err := c.grpcClient.ReadAggregate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Points, nil
+}
+
+// ReadHistogram returns the number of envelopes of the source ID in buckets
+// of the given step, split by envelope type. Use WithHistogramGroupBy to
+// further split the counts by log type or instance ID.
+func (c *Client) ReadHistogram(
+	ctx context.Context,
+	sourceID string,
+	start time.Time,
+	step time.Duration,
+	opts ...HistogramOption,
+) ([]*logcache_v1.ReadHistogramResponse_Bucket, error) {
 	if c.grpcClient != nil {
-		return c.grpcMeta(ctx)
+		return c.grpcReadHistogram(ctx, sourceID, start, step, opts)
 	}
 
 	u, err := url.Parse(c.addr)
@@ -254,7 +471,17 @@ func (c *Client) Meta(ctx context.Context) (map[string]*logcache_v1.MetaInfo, er
 		return nil, err
 	}
 
-	u.Path = fmt.Sprintf("%s/meta", baseApiPath)
+	u.Path = fmt.Sprintf("%s/histogram/%s", baseApiPath, sourceID)
+	q := u.Query()
+	q.Set("start_time", strconv.FormatInt(start.UnixNano(), 10))
+	q.Set("step", strconv.FormatInt(int64(step), 10))
+
+	// allow the given options to configure the URL.
+	for _, o := range opts {
+		o(u, q)
+	}
+	u.RawQuery = q.Encode()
+
 	req, err := http.NewRequest("GET", u.String(), nil)
 	if err != nil {
 		return nil, err
@@ -271,21 +498,207 @@ func (c *Client) Meta(ctx context.Context) (map[string]*logcache_v1.MetaInfo, er
 		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
 	}
 
-	var metaResponse logcache_v1.MetaResponse
-	if err := jsonpb.Unmarshal(resp.Body, &metaResponse); err != nil {
+	var r logcache_v1.ReadHistogramResponse
+	if err := jsonpb.Unmarshal(resp.Body, &r); err != nil {
 		return nil, err
 	}
 
-	return metaResponse.Meta, nil
+	return r.GetBuckets(), nil
+}
+
+// HistogramOption configures the URL that is used to read a histogram. The
+// RawQuery is set to the decoded query parameters after each option is
+// invoked.
+type HistogramOption func(u *url.URL, q url.Values)
+
+// WithHistogramEndTime sets the 'end_time' query parameter to the given
+// time. It defaults to empty, and therefore the current time.
+func WithHistogramEndTime(t time.Time) HistogramOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("end_time", strconv.FormatInt(t.UnixNano(), 10))
+	}
+}
+
+// WithHistogramGroupBy sets the 'group_by' query parameter to the given
+// value. It defaults to NONE, and therefore only splits by envelope type.
+func WithHistogramGroupBy(g logcache_v1.ReadHistogramRequest_GroupBy) HistogramOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("group_by", g.String())
+	}
 }
 
-func (c *Client) grpcMeta(ctx context.Context) (map[string]*logcache_v1.MetaInfo, error) {
-	resp, err := c.grpcClient.Meta(ctx, &logcache_v1.MetaRequest{})
+func (c *Client) grpcReadHistogram(
+	ctx context.Context,
+	sourceID string,
+	start time.Time,
+	step time.Duration,
+	opts []HistogramOption,
+) ([]*logcache_v1.ReadHistogramResponse_Bucket, error) {
+	u := &url.URL{}
+	q := u.Query()
+	// allow the given options to configure the URL.
+	for _, o := range opts {
+		o(u, q)
+	}
+
+	req := &logcache_v1.ReadHistogramRequest{
+		SourceId:  sourceID,
+		StartTime: start.UnixNano(),
+		Step:      int64(step),
+		GroupBy:   logcache_v1.ReadHistogramRequest_GroupBy(logcache_v1.ReadHistogramRequest_GroupBy_value[q.Get("group_by")]),
+	}
+
+	if v, ok := q["end_time"]; ok {
+		req.EndTime, _ = strconv.ParseInt(v[0], 10, 64)
+	}
+
+	resp, err := c.grpcClient.ReadHistogram(ctx, req)
 	if err != nil {
 		return nil, err
 	}
+	return resp.Buckets, nil
+}
+
+// MetaOption configures the URL that is used to request meta information.
+// The RawQuery is set to the decoded query parameters after each option is
+// invoked.
+type MetaOption func(u *url.URL, q url.Values)
+
+// WithMetaSourceIDPrefix sets the 'source_id_prefix' query parameter to the
+// given value. Only source IDs that start with the prefix are returned.
+func WithMetaSourceIDPrefix(prefix string) MetaOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("source_id_prefix", prefix)
+	}
+}
+
+// WithMetaSourceIDRegex sets the 'source_id_regex' query parameter to the
+// given value. Only source IDs that match the RE2 regular expression are
+// returned.
+func WithMetaSourceIDRegex(regex string) MetaOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("source_id_regex", regex)
+	}
+}
+
+// WithMetaSortBy sets the 'sort_by' query parameter to the given value. It
+// defaults to sorting by source ID.
+func WithMetaSortBy(sortBy logcache_v1.MetaSortBy) MetaOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("sort_by", sortBy.String())
+	}
+}
 
-	return resp.Meta, nil
+// WithMetaDescending sets the 'descending' query parameter to true. It
+// defaults to false, yielding ascending order.
+func WithMetaDescending() MetaOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("descending", "true")
+	}
+}
+
+// WithMetaLimit sets the 'limit' query parameter to the given value. It
+// defaults to empty, and therefore every source ID.
+func WithMetaLimit(limit int) MetaOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+}
+
+// WithMetaPageToken sets the 'page_token' query parameter to the next page
+// token returned by MetaPage.
+func WithMetaPageToken(token string) MetaOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("page_token", token)
+	}
+}
+
+// Meta returns meta information from the entire LogCache.
+func (c *Client) Meta(ctx context.Context, opts ...MetaOption) (map[string]*logcache_v1.MetaInfo, error) {
+	meta, _, err := c.MetaPage(ctx, opts...)
+	return meta, err
+}
+
+// MetaPage returns a page of meta information from the entire LogCache
+// along with the token of the next page. The token is empty if there are no
+// more pages.
+func (c *Client) MetaPage(ctx context.Context, opts ...MetaOption) (map[string]*logcache_v1.MetaInfo, string, error) {
+	if c.grpcClient != nil {
+		return c.grpcMeta(ctx, opts)
+	}
+
+	u, err := url.Parse(c.addr)
+	if err != nil {
+		return nil, "", err
+	}
+
+	baseApiPath, err := c.getBaseApiPath(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	u.Path = fmt.Sprintf("%s/meta", baseApiPath)
+	q := u.Query()
+
+	// allow the given options to configure the URL.
+	for _, o := range opts {
+		o(u, q)
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	var metaResponse logcache_v1.MetaResponse
+	if err := jsonpb.Unmarshal(resp.Body, &metaResponse); err != nil {
+		return nil, "", err
+	}
+
+	return metaResponse.Meta, metaResponse.NextPageToken, nil
+}
+
+func (c *Client) grpcMeta(ctx context.Context, opts []MetaOption) (map[string]*logcache_v1.MetaInfo, string, error) {
+	u := &url.URL{}
+	q := u.Query()
+	// allow the given options to configure the URL.
+	for _, o := range opts {
+		o(u, q)
+	}
+
+	req := &logcache_v1.MetaRequest{
+		SourceIdPrefix: q.Get("source_id_prefix"),
+		SourceIdRegex:  q.Get("source_id_regex"),
+		SortBy:         logcache_v1.MetaSortBy(logcache_v1.MetaSortBy_value[q.Get("sort_by")]),
+		PageToken:      q.Get("page_token"),
+	}
+
+	if _, ok := q["descending"]; ok {
+		req.Descending = true
+	}
+
+	if v, ok := q["limit"]; ok {
+		req.Limit, _ = strconv.ParseInt(v[0], 10, 64)
+	}
+
+	resp, err := c.grpcClient.Meta(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return resp.Meta, resp.NextPageToken, nil
 }
 
 func (c *Client) getBaseApiPath(ctx context.Context) (string, error) {
@@ -425,6 +838,14 @@ func WithPromQLStep(step string) PromQLOption {
 	}
 }
 
+// WithPromQLNoCache returns a PromQLOption that evaluates every step of a
+// range query instead of reusing cached results.
+func WithPromQLNoCache() PromQLOption {
+	return func(u *url.URL, q url.Values) {
+		q.Set("nocache", "true")
+	}
+}
+
 // PromQL issues a PromQL range query against Log Cache data.
 func (c *Client) PromQLRange(
 	ctx context.Context,
@@ -498,6 +919,10 @@ func (c *Client) grpcPromQLRange(ctx context.Context, query string, opts []PromQ
 		req.Step = v[0]
 	}
 
+	if v, ok := q["nocache"]; ok {
+		req.Nocache = v[0] == "true"
+	}
+
 	resp, err := c.promqlGrpcClient.RangeQuery(ctx, req)
 	if err != nil {
 		return nil, err
@@ -90,6 +90,24 @@ var _ = Describe("Log Cache Client", func() {
 				Expect(logCache.reqs[1].URL.Query()).To(HaveLen(1))
 			})
 
+			It("reads a page of envelopes", func() {
+				logCache := newStubLogCache()
+				logcache_client := client.NewClient(logCache.addr())
+
+				envelopes, token, err := logcache_client.ReadPage(
+					context.Background(),
+					"some-id",
+					time.Unix(0, 99),
+					client.WithPageToken("some-token"),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(envelopes).To(HaveLen(2))
+				Expect(token).To(Equal("some-read-token"))
+
+				Expect(logCache.reqs).To(HaveLen(2))
+				assertQueryParam(logCache.reqs[1].URL, "page_token", "some-token")
+			})
+
 			It("falls back to pre-1.4.7 endpoint", func() {
 				logCache := newStubOldLogCache()
 				logcache_client := client.NewClient(logCache.addr())
@@ -141,6 +159,47 @@ var _ = Describe("Log Cache Client", func() {
 				Expect(logCache.reqs[1].URL.Query()).To(HaveLen(6))
 			})
 
+			It("sets the payload filter options", func() {
+				logCache := newStubLogCache()
+				logcache_client := client.NewClient(logCache.addr())
+
+				_, err := logcache_client.Read(
+					context.Background(),
+					"some-id",
+					time.Unix(0, 99),
+					client.WithPayloadFilter("status=5.."),
+					client.WithPayloadFilterRegex(),
+					client.WithPayloadFilterCaseInsensitive(),
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(logCache.reqs).To(HaveLen(2))
+				assertQueryParam(logCache.reqs[1].URL, "payload_filter", "status=5..")
+				assertQueryParam(logCache.reqs[1].URL, "payload_filter_regex", "true")
+				assertQueryParam(logCache.reqs[1].URL, "payload_filter_case_insensitive", "true")
+			})
+
+			It("sets the instance ID and tag matchers", func() {
+				logCache := newStubLogCache()
+				logcache_client := client.NewClient(logCache.addr())
+
+				_, err := logcache_client.Read(
+					context.Background(),
+					"some-id",
+					time.Unix(0, 99),
+					client.WithInstanceID("3"),
+					client.WithTagMatcher("deployment", "=", "cf"),
+					client.WithTagMatcher("job", "=~", "diego.*"),
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(logCache.reqs).To(HaveLen(2))
+				assertQueryParam(logCache.reqs[1].URL, "instance_id", "3")
+				assertQueryParam(logCache.reqs[1].URL, "tag_matchers", "deployment=cf", "job=~diego.*")
+			})
+
 			It("closes the body", func() {
 				spyHTTPClient := newSpyHTTPClient()
 				logcache_client := client.NewClient("", client.WithHTTPClient(spyHTTPClient))
@@ -201,6 +260,151 @@ var _ = Describe("Log Cache Client", func() {
 			})
 		})
 
+		Describe("Tail", func() {
+			It("tails envelopes", func() {
+				logCache := newStubLogCache()
+				logcache_client := client.NewClient(logCache.addr())
+
+				var batches [][]*loggregator_v2.Envelope
+				err := logcache_client.Tail(context.Background(), "some-id", func(es []*loggregator_v2.Envelope) bool {
+					batches = append(batches, es)
+					return true
+				},
+					client.WithEnvelopeTypes(rpc.EnvelopeType_LOG),
+					client.WithNameFilter("name.*"),
+					client.WithLimit(10),
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(batches).To(HaveLen(2))
+				Expect(batches[0][0].Timestamp).To(BeEquivalentTo(99))
+				Expect(batches[1][0].Timestamp).To(BeEquivalentTo(100))
+
+				Expect(logCache.reqs).To(HaveLen(2))
+				Expect(logCache.reqs[1].URL.Path).To(Equal("/api/v1/tail/some-id"))
+				assertQueryParam(logCache.reqs[1].URL, "envelope_types", "LOG")
+				assertQueryParam(logCache.reqs[1].URL, "name_filter", "name.*")
+				Expect(logCache.reqs[1].URL.Query()).To(HaveLen(2))
+			})
+
+			It("stops once the visitor returns false", func() {
+				logCache := newStubLogCache()
+				logcache_client := client.NewClient(logCache.addr())
+
+				var calls int
+				err := logcache_client.Tail(context.Background(), "some-id", func([]*loggregator_v2.Envelope) bool {
+					calls++
+					return false
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(calls).To(Equal(1))
+			})
+
+			It("returns an error sent on the stream", func() {
+				logCache := newStubLogCache()
+				logcache_client := client.NewClient(logCache.addr())
+
+				err := logcache_client.Tail(context.Background(), "some-error-id", func([]*loggregator_v2.Envelope) bool {
+					return true
+				})
+				Expect(err).To(MatchError("some-error"))
+			})
+
+			It("returns an error on a non-200 status", func() {
+				logCache := newStubLogCache()
+				logCache.statusCode = 500
+				logcache_client := client.NewClient(logCache.addr())
+
+				err := logcache_client.Tail(context.Background(), "some-id", func([]*loggregator_v2.Envelope) bool {
+					return true
+				})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Describe("ReadAggregate", func() {
+			It("reads aggregated points", func() {
+				logCache := newStubLogCache()
+				logcache_client := client.NewClient(logCache.addr())
+
+				points, err := logcache_client.ReadAggregate(
+					context.Background(),
+					"some-id",
+					"cpu",
+					time.Unix(0, 99),
+					time.Minute,
+					client.WithAggregateEndTime(time.Unix(0, 101)),
+					client.WithAggregation(rpc.ReadAggregateRequest_MAX),
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(points).To(Equal([]*rpc.ReadAggregateResponse_Point{
+					{Timestamp: 60000000000, Value: 1.5},
+					{Timestamp: 120000000000, Value: 2},
+				}))
+
+				Expect(logCache.reqs).To(HaveLen(2))
+				Expect(logCache.reqs[1].URL.Path).To(Equal("/api/v1/aggregate/some-id"))
+				assertQueryParam(logCache.reqs[1].URL, "name", "cpu")
+				assertQueryParam(logCache.reqs[1].URL, "start_time", "99")
+				assertQueryParam(logCache.reqs[1].URL, "end_time", "101")
+				assertQueryParam(logCache.reqs[1].URL, "step", "60000000000")
+				assertQueryParam(logCache.reqs[1].URL, "aggregation", "MAX")
+			})
+
+			It("returns an error on a non-200 status code", func() {
+				logCache := newStubLogCache()
+				logCache.statusCode = 500
+				logcache_client := client.NewClient(logCache.addr())
+
+				_, err := logcache_client.ReadAggregate(context.Background(), "some-id", "cpu", time.Unix(0, 99), time.Minute)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Describe("ReadHistogram", func() {
+			It("reads histogram buckets", func() {
+				logCache := newStubLogCache()
+				logcache_client := client.NewClient(logCache.addr())
+
+				buckets, err := logcache_client.ReadHistogram(
+					context.Background(),
+					"some-id",
+					time.Unix(0, 99),
+					time.Minute,
+					client.WithHistogramEndTime(time.Unix(0, 101)),
+					client.WithHistogramGroupBy(rpc.ReadHistogramRequest_LOG_TYPE),
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(buckets).To(Equal([]*rpc.ReadHistogramResponse_Bucket{
+					{
+						Timestamp: 60000000000,
+						Counts: []*rpc.ReadHistogramResponse_Count{
+							{EnvelopeType: rpc.EnvelopeType_LOG, Group: "OUT", Count: 3},
+							{EnvelopeType: rpc.EnvelopeType_LOG, Group: "ERR", Count: 1},
+						},
+					},
+				}))
+
+				Expect(logCache.reqs).To(HaveLen(2))
+				Expect(logCache.reqs[1].URL.Path).To(Equal("/api/v1/histogram/some-id"))
+				assertQueryParam(logCache.reqs[1].URL, "start_time", "99")
+				assertQueryParam(logCache.reqs[1].URL, "end_time", "101")
+				assertQueryParam(logCache.reqs[1].URL, "step", "60000000000")
+				assertQueryParam(logCache.reqs[1].URL, "group_by", "LOG_TYPE")
+			})
+
+			It("returns an error on a non-200 status code", func() {
+				logCache := newStubLogCache()
+				logCache.statusCode = 500
+				logcache_client := client.NewClient(logCache.addr())
+
+				_, err := logcache_client.ReadHistogram(context.Background(), "some-id", time.Unix(0, 99), time.Minute)
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
 		Describe("Meta", func() {
 			It("retrieves meta information", func() {
 				logCache := newStubLogCache()
@@ -212,6 +416,40 @@ var _ = Describe("Log Cache Client", func() {
 				Expect(meta).To(HaveLen(2))
 				Expect(meta).To(HaveKey("source-0"))
 				Expect(meta).To(HaveKey("source-1"))
+
+				Expect(meta["source-0"].Count).To(Equal(int64(3)))
+				Expect(meta["source-0"].LogCount).To(Equal(int64(2)))
+				Expect(meta["source-0"].GaugeCount).To(Equal(int64(1)))
+				Expect(meta["source-0"].Bytes).To(Equal(int64(240)))
+				Expect(meta["source-0"].Rate).To(Equal(0.05))
+			})
+
+			It("filters, sorts and pages meta information", func() {
+				logCache := newStubLogCache()
+				logcache_client := client.NewClient(logCache.addr())
+
+				meta, token, err := logcache_client.MetaPage(context.Background(),
+					client.WithMetaSourceIDPrefix("app-"),
+					client.WithMetaSourceIDRegex("^app-[0-9]+$"),
+					client.WithMetaSortBy(rpc.MetaSortBy_COUNT),
+					client.WithMetaDescending(),
+					client.WithMetaLimit(50),
+					client.WithMetaPageToken("previous-token"),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(meta).To(HaveLen(2))
+				Expect(token).To(Equal("some-token"))
+
+				Expect(logCache.reqs).To(HaveLen(2))
+				Expect(logCache.reqs[1].URL.Path).To(Equal("/api/v1/meta"))
+				Expect(logCache.reqs[1].URL.Query()).To(Equal(url.Values{
+					"source_id_prefix": {"app-"},
+					"source_id_regex":  {"^app-[0-9]+$"},
+					"sort_by":          {"COUNT"},
+					"descending":       {"true"},
+					"limit":            {"50"},
+					"page_token":       {"previous-token"},
+				}))
 			})
 
 			It("falls back to the pre-1.4.7 endpoint", func() {
@@ -497,6 +735,21 @@ var _ = Describe("Log Cache Client", func() {
 				Expect(logCache.reqs[0].URL.Query()).To(HaveLen(4))
 			})
 
+			It("bypasses the results cache", func() {
+				logCache := newStubLogCache()
+				logcache_client := client.NewClient(logCache.addr())
+
+				_, err := logcache_client.PromQLRange(
+					context.Background(),
+					`some-query`,
+					client.WithPromQLNoCache(),
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(logCache.reqs).To(HaveLen(1))
+				assertQueryParam(logCache.reqs[0].URL, "nocache", "true")
+			})
+
 			It("closes the body", func() {
 				spyHTTPClient := newSpyHTTPClient()
 				logcache_client := client.NewClient("", client.WithHTTPClient(spyHTTPClient))
@@ -656,6 +909,11 @@ var _ = Describe("Log Cache Client", func() {
 					client.WithEnvelopeTypes(rpc.EnvelopeType_LOG, rpc.EnvelopeType_GAUGE),
 					client.WithDescending(),
 					client.WithNameFilter("name.*"),
+					client.WithPayloadFilter("payload"),
+					client.WithPayloadFilterRegex(),
+					client.WithPayloadFilterCaseInsensitive(),
+					client.WithInstanceID("3"),
+					client.WithTagMatcher("deployment", "!=", "cf"),
 				)
 
 				Expect(err).ToNot(HaveOccurred())
@@ -678,11 +936,32 @@ var _ = Describe("Log Cache Client", func() {
 							),
 							"NameFilter": Equal("name.*"),
 							"Descending": Equal(true),
+
+							"PayloadFilter":                Equal("payload"),
+							"PayloadFilterRegex":           Equal(true),
+							"PayloadFilterCaseInsensitive": Equal(true),
+							"InstanceId":                   Equal("3"),
+							"TagMatchers":                  ConsistOf("deployment!=cf"),
 						},
 					),
 				)))
 			})
 
+			It("reads a page of envelopes", func() {
+				logCache := newStubGrpcLogCache()
+				logcache_client := client.NewClient(logCache.addr(), client.WithViaGRPC(grpc.WithInsecure()))
+
+				envelopes, token, err := logcache_client.ReadPage(context.Background(), "some-id", time.Unix(0, 99),
+					client.WithPageToken("some-token"),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(envelopes).To(HaveLen(2))
+				Expect(token).To(Equal("some-read-token"))
+
+				Expect(logCache.requests()).To(HaveLen(1))
+				Expect(logCache.requests()[0].PageToken).To(Equal("some-token"))
+			})
+
 			It("returns an error when the context is cancelled", func() {
 				logCache := newStubGrpcLogCache()
 				logCache.block = true
@@ -703,6 +982,110 @@ var _ = Describe("Log Cache Client", func() {
 			})
 		})
 
+		Describe("Tail", func() {
+			It("tails envelopes", func() {
+				logCache := newStubGrpcLogCache()
+				logcache_client := client.NewClient(logCache.addr(), client.WithViaGRPC(grpc.WithInsecure()))
+
+				var envelopes []*loggregator_v2.Envelope
+				err := logcache_client.Tail(context.Background(), "some-id", func(es []*loggregator_v2.Envelope) bool {
+					envelopes = append(envelopes, es...)
+					return true
+				},
+					client.WithEnvelopeTypes(rpc.EnvelopeType_LOG, rpc.EnvelopeType_GAUGE),
+					client.WithNameFilter("name.*"),
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(envelopes).To(HaveLen(2))
+				Expect(envelopes[0].Timestamp).To(BeEquivalentTo(99))
+				Expect(envelopes[1].Timestamp).To(BeEquivalentTo(100))
+
+				Expect(logCache.tailRequests()).To(ConsistOf(PointTo(
+					MatchFields(IgnoreExtras,
+						Fields{
+							"SourceId": Equal("some-id"),
+							"EnvelopeTypes": ConsistOf(
+								Equal(rpc.EnvelopeType_LOG),
+								Equal(rpc.EnvelopeType_GAUGE),
+							),
+							"NameFilter": Equal("name.*"),
+						},
+					),
+				)))
+			})
+
+			It("returns an error when the context is cancelled", func() {
+				logCache := newStubGrpcLogCache()
+				logCache.block = true
+				logcache_client := client.NewClient(logCache.addr(), client.WithViaGRPC(grpc.WithInsecure()))
+
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+
+				err := logcache_client.Tail(ctx, "some-id", func([]*loggregator_v2.Envelope) bool {
+					return true
+				})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		Describe("ReadAggregate", func() {
+			It("reads aggregated points", func() {
+				logCache := newStubGrpcLogCache()
+				logcache_client := client.NewClient(logCache.addr(), client.WithViaGRPC(grpc.WithInsecure()))
+
+				points, err := logcache_client.ReadAggregate(
+					context.Background(),
+					"some-id",
+					"cpu",
+					time.Unix(0, 99),
+					time.Minute,
+					client.WithAggregateEndTime(time.Unix(0, 101)),
+					client.WithAggregation(rpc.ReadAggregateRequest_MAX),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(points).To(HaveLen(1))
+				Expect(points[0].Value).To(Equal(1.5))
+
+				Expect(logCache.aggregateRequests()).To(ConsistOf(&rpc.ReadAggregateRequest{
+					SourceId:    "some-id",
+					Name:        "cpu",
+					StartTime:   99,
+					EndTime:     101,
+					Step:        int64(time.Minute),
+					Aggregation: rpc.ReadAggregateRequest_MAX,
+				}))
+			})
+		})
+
+		Describe("ReadHistogram", func() {
+			It("reads histogram buckets", func() {
+				logCache := newStubGrpcLogCache()
+				logcache_client := client.NewClient(logCache.addr(), client.WithViaGRPC(grpc.WithInsecure()))
+
+				buckets, err := logcache_client.ReadHistogram(
+					context.Background(),
+					"some-id",
+					time.Unix(0, 99),
+					time.Minute,
+					client.WithHistogramEndTime(time.Unix(0, 101)),
+					client.WithHistogramGroupBy(rpc.ReadHistogramRequest_INSTANCE_ID),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(buckets).To(HaveLen(1))
+				Expect(buckets[0].Counts[0].Count).To(Equal(int64(4)))
+
+				Expect(logCache.histogramRequests()).To(ConsistOf(&rpc.ReadHistogramRequest{
+					SourceId:  "some-id",
+					StartTime: 99,
+					EndTime:   101,
+					Step:      int64(time.Minute),
+					GroupBy:   rpc.ReadHistogramRequest_INSTANCE_ID,
+				}))
+			})
+		})
+
 		Describe("Meta", func() {
 			It("retrieves meta information", func() {
 				logCache := newStubGrpcLogCache()
@@ -714,6 +1097,36 @@ var _ = Describe("Log Cache Client", func() {
 				Expect(meta).To(HaveLen(2))
 				Expect(meta).To(HaveKey("source-0"))
 				Expect(meta).To(HaveKey("source-1"))
+
+				Expect(meta["source-0"].CounterCount).To(Equal(int64(2)))
+				Expect(meta["source-0"].TimerCount).To(Equal(int64(1)))
+				Expect(meta["source-0"].Bytes).To(Equal(int64(240)))
+				Expect(meta["source-0"].Rate).To(Equal(0.05))
+			})
+
+			It("filters, sorts and pages meta information", func() {
+				logCache := newStubGrpcLogCache()
+				logcache_client := client.NewClient(logCache.addr(), client.WithViaGRPC(grpc.WithInsecure()))
+
+				_, token, err := logcache_client.MetaPage(context.Background(),
+					client.WithMetaSourceIDPrefix("app-"),
+					client.WithMetaSourceIDRegex("^app-[0-9]+$"),
+					client.WithMetaSortBy(rpc.MetaSortBy_NEWEST_TIMESTAMP),
+					client.WithMetaDescending(),
+					client.WithMetaLimit(50),
+					client.WithMetaPageToken("previous-token"),
+				)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(token).To(Equal("some-token"))
+
+				Expect(logCache.metaRequests()).To(ConsistOf(&rpc.MetaRequest{
+					SourceIdPrefix: "app-",
+					SourceIdRegex:  "^app-[0-9]+$",
+					SortBy:         rpc.MetaSortBy_NEWEST_TIMESTAMP,
+					Descending:     true,
+					Limit:          50,
+					PageToken:      "previous-token",
+				}))
 			})
 
 			It("returns an error when the context is cancelled", func() {
@@ -796,13 +1209,45 @@ func newStubLogCache() *stubLogCache {
 					"source_id": "some-id"
 				}
 			]
-		}
+		},
+		"next_page_token": "some-read-token"
+	}`),
+			"GET/api/v1/aggregate/some-id": []byte(`{
+		"points": [
+			{"timestamp": "60000000000", "value": 1.5},
+			{"timestamp": "120000000000", "value": 2}
+		]
+	}`),
+			"GET/api/v1/histogram/some-id": []byte(`{
+		"buckets": [
+			{
+				"timestamp": "60000000000",
+				"counts": [
+					{"envelope_type": "LOG", "group": "OUT", "count": "3"},
+					{"envelope_type": "LOG", "group": "ERR", "count": "1"}
+				]
+			}
+		]
 	}`),
+			"GET/api/v1/tail/some-id": []byte(`
+	{"result": {"envelopes": {"batch": [{"timestamp": 99, "source_id": "some-id"}]}}}
+	{"result": {"envelopes": {"batch": [{"timestamp": 100, "source_id": "some-id"}]}}}
+	`),
+			"GET/api/v1/tail/some-error-id": []byte(`
+	{"error": {"grpc_code": 2, "http_code": 500, "message": "some-error", "http_status": "Internal Server Error"}}
+	`),
 			"GET/api/v1/meta": []byte(`{
 		"meta": {
-			"source-0": {},
+			"source-0": {
+				"count": "3",
+				"log_count": "2",
+				"gauge_count": "1",
+				"bytes": "240",
+				"rate": 0.05
+			},
 			"source-1": {}
-		}
+		},
+		"next_page_token": "some-token"
 	}`),
 			"GET/api/v1/query": []byte(`
     {
@@ -948,8 +1393,12 @@ func assertQueryParam(u *url.URL, name string, values ...string) {
 type stubGrpcLogCache struct {
 	mu              sync.Mutex
 	reqs            []*rpc.ReadRequest
+	tailReqs        []*rpc.TailRequest
 	promInstantReqs []*rpc.PromQL_InstantQueryRequest
 	promRangeReqs   []*rpc.PromQL_RangeQueryRequest
+	metaReqs        []*rpc.MetaRequest
+	aggregateReqs   []*rpc.ReadAggregateRequest
+	histogramReqs   []*rpc.ReadHistogramRequest
 	lis             net.Listener
 	block           bool
 }
@@ -989,9 +1438,36 @@ func (s *stubGrpcLogCache) Read(c context.Context, r *rpc.ReadRequest) (*rpc.Rea
 				{Timestamp: 100, SourceId: "some-id"},
 			},
 		},
+		NextPageToken: "some-read-token",
 	}, nil
 }
 
+func (s *stubGrpcLogCache) Tail(r *rpc.TailRequest, srv rpc.Egress_TailServer) error {
+	if s.block {
+		var block chan struct{}
+		<-block
+	}
+
+	s.mu.Lock()
+	s.tailReqs = append(s.tailReqs, r)
+	s.mu.Unlock()
+
+	for _, ts := range []int64{99, 100} {
+		err := srv.Send(&rpc.TailResponse{
+			Envelopes: &loggregator_v2.EnvelopeBatch{
+				Batch: []*loggregator_v2.Envelope{
+					{Timestamp: ts, SourceId: "some-id"},
+				},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *stubGrpcLogCache) InstantQuery(c context.Context, r *rpc.PromQL_InstantQueryRequest) (*rpc.PromQL_InstantQueryResult, error) {
 	if s.block {
 		var block chan struct{}
@@ -1043,15 +1519,94 @@ func (s *stubGrpcLogCache) RangeQuery(c context.Context, r *rpc.PromQL_RangeQuer
 	}, nil
 }
 
-func (s *stubGrpcLogCache) Meta(context.Context, *rpc.MetaRequest) (*rpc.MetaResponse, error) {
+func (s *stubGrpcLogCache) Labels(context.Context, *rpc.PromQL_LabelsRequest) (*rpc.PromQL_LabelsResult, error) {
+	return &rpc.PromQL_LabelsResult{}, nil
+}
+
+func (s *stubGrpcLogCache) LabelValues(context.Context, *rpc.PromQL_LabelValuesRequest) (*rpc.PromQL_LabelValuesResult, error) {
+	return &rpc.PromQL_LabelValuesResult{}, nil
+}
+
+func (s *stubGrpcLogCache) Series(context.Context, *rpc.PromQL_SeriesRequest) (*rpc.PromQL_SeriesResult, error) {
+	return &rpc.PromQL_SeriesResult{}, nil
+}
+
+func (s *stubGrpcLogCache) Meta(_ context.Context, r *rpc.MetaRequest) (*rpc.MetaResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metaReqs = append(s.metaReqs, r)
+
 	return &rpc.MetaResponse{
 		Meta: map[string]*rpc.MetaInfo{
-			"source-0": {},
+			"source-0": {
+				Count:        3,
+				CounterCount: 2,
+				TimerCount:   1,
+				Bytes:        240,
+				Rate:         0.05,
+			},
 			"source-1": {},
 		},
+		NextPageToken: "some-token",
+	}, nil
+}
+
+func (s *stubGrpcLogCache) ReadAggregate(_ context.Context, r *rpc.ReadAggregateRequest) (*rpc.ReadAggregateResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.aggregateReqs = append(s.aggregateReqs, r)
+
+	return &rpc.ReadAggregateResponse{
+		Points: []*rpc.ReadAggregateResponse_Point{
+			{Timestamp: 60000000000, Value: 1.5},
+		},
+	}, nil
+}
+
+func (s *stubGrpcLogCache) aggregateRequests() []*rpc.ReadAggregateRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := make([]*rpc.ReadAggregateRequest, len(s.aggregateReqs))
+	copy(r, s.aggregateReqs)
+	return r
+}
+
+func (s *stubGrpcLogCache) ReadHistogram(_ context.Context, r *rpc.ReadHistogramRequest) (*rpc.ReadHistogramResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.histogramReqs = append(s.histogramReqs, r)
+
+	return &rpc.ReadHistogramResponse{
+		Buckets: []*rpc.ReadHistogramResponse_Bucket{
+			{
+				Timestamp: 60000000000,
+				Counts: []*rpc.ReadHistogramResponse_Count{
+					{EnvelopeType: rpc.EnvelopeType_LOG, Group: "some-instance", Count: 4},
+				},
+			},
+		},
 	}, nil
 }
 
+func (s *stubGrpcLogCache) histogramRequests() []*rpc.ReadHistogramRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := make([]*rpc.ReadHistogramRequest, len(s.histogramReqs))
+	copy(r, s.histogramReqs)
+	return r
+}
+
+func (s *stubGrpcLogCache) metaRequests() []*rpc.MetaRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := make([]*rpc.MetaRequest, len(s.metaReqs))
+	copy(r, s.metaReqs)
+	return r
+}
+
 func (s *stubGrpcLogCache) requests() []*rpc.ReadRequest {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -1061,6 +1616,15 @@ func (s *stubGrpcLogCache) requests() []*rpc.ReadRequest {
 	return r
 }
 
+func (s *stubGrpcLogCache) tailRequests() []*rpc.TailRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := make([]*rpc.TailRequest, len(s.tailReqs))
+	copy(r, s.tailReqs)
+	return r
+}
+
 func (s *stubGrpcLogCache) promQLRequests() []*rpc.PromQL_InstantQueryRequest {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -0,0 +1,154 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"code.cloudfoundry.org/log-cache/pkg/rpc/logcache_v1"
+	"github.com/golang/protobuf/jsonpb"
+)
+
+// Tail streams envelopes for the given source ID as they arrive in
+// LogCache and passes each batch to the Visitor. It blocks until the
+// Visitor returns false, the context is done or the stream fails. Only
+// envelopes that arrive after the stream is opened are passed on.
+//
+// Of the ReadOptions, only WithEnvelopeTypes and WithNameFilter apply to
+// Tail. When reading via HTTP, the HTTP client must not time out requests
+// (see WithHTTPClient) as the response is streamed.
+func (c *Client) Tail(ctx context.Context, sourceID string, v Visitor, opts ...ReadOption) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	q := tailQuery(opts)
+	if c.grpcClient != nil {
+		return c.grpcTail(ctx, sourceID, v, q)
+	}
+
+	u, err := url.Parse(c.addr)
+	if err != nil {
+		return err
+	}
+
+	baseApiPath, err := c.getBaseApiPath(ctx)
+	if err != nil {
+		return err
+	}
+
+	u.Path = fmt.Sprintf("%s/tail/%s", baseApiPath, sourceID)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	// The gateway writes one JSON object per response on the stream.
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var chunk struct {
+			Result json.RawMessage `json:"result"`
+			Error  *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+
+		if err := dec.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			return err
+		}
+
+		if chunk.Error != nil {
+			return errors.New(chunk.Error.Message)
+		}
+
+		var r logcache_v1.TailResponse
+		if err := jsonpb.Unmarshal(bytes.NewReader(chunk.Result), &r); err != nil {
+			return err
+		}
+
+		if !v(r.GetEnvelopes().GetBatch()) {
+			return nil
+		}
+	}
+}
+
+func (c *Client) grpcTail(ctx context.Context, sourceID string, v Visitor, q url.Values) error {
+	req := &logcache_v1.TailRequest{
+		SourceId:   sourceID,
+		NameFilter: q.Get("name_filter"),
+	}
+
+	for _, et := range q["envelope_types"] {
+		req.EnvelopeTypes = append(req.EnvelopeTypes,
+			logcache_v1.EnvelopeType(logcache_v1.EnvelopeType_value[et]),
+		)
+	}
+
+	stream, err := c.grpcClient.Tail(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			return err
+		}
+
+		if !v(resp.GetEnvelopes().GetBatch()) {
+			return nil
+		}
+	}
+}
+
+// tailQuery applies the ReadOptions and keeps the query parameters that
+// Tail supports.
+func tailQuery(opts []ReadOption) url.Values {
+	u := &url.URL{}
+	q := u.Query()
+	for _, o := range opts {
+		o(u, q)
+	}
+
+	tq := url.Values{}
+	for _, name := range []string{"envelope_types", "name_filter"} {
+		if v, ok := q[name]; ok {
+			tq[name] = v
+		}
+	}
+
+	return tq
+}
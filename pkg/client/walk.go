@@ -19,13 +19,36 @@ type Reader func(
 	opts ...ReadOption,
 ) ([]*loggregator_v2.Envelope, error)
 
+// PageReader reads a page of envelopes from LogCache along with the token
+// of the next page. It will be invoked by WalkPages several times to
+// traverse the length of the cache.
+type PageReader func(
+	ctx context.Context,
+	sourceID string,
+	start time.Time,
+	opts ...ReadOption,
+) ([]*loggregator_v2.Envelope, string, error)
+
 // Visitor is invoked for each envelope batch. If the function returns false,
 // it doesn't make any more requests. Otherwise it reaches out for the next
 // batch of envelopes.
 type Visitor func([]*loggregator_v2.Envelope) bool
 
-// Walk reads from the LogCache until the Visitor returns false.
+// Walk reads from the LogCache until the Visitor returns false. Each read
+// starts after the timestamp of the last envelope. Use WalkPages to resume
+// from page tokens instead.
 func Walk(ctx context.Context, sourceID string, v Visitor, r Reader, opts ...WalkOption) {
+	WalkPages(ctx, sourceID, v, func(ctx context.Context, sourceID string, start time.Time, opts ...ReadOption) ([]*loggregator_v2.Envelope, string, error) {
+		es, err := r(ctx, sourceID, start, opts...)
+		return es, "", err
+	}, opts...)
+}
+
+// WalkPages reads from the LogCache until the Visitor returns false. Each
+// read resumes from the page token of the previous one, so envelopes that
+// share a timestamp are never skipped. If there is no page token, the read
+// starts after the timestamp of the last envelope.
+func WalkPages(ctx context.Context, sourceID string, v Visitor, r PageReader, opts ...WalkOption) {
 	c := &walkConfig{
 		log:     log.New(ioutil.Discard, "", 0),
 		backoff: AlwaysDoneBackoff{},
@@ -53,10 +76,18 @@ func Walk(ctx context.Context, sourceID string, v Visitor, r Reader, opts ...Wal
 		readOpts = append(readOpts, WithNameFilter(c.nameFilter))
 	}
 
-	var receivedEmpty bool
+	var (
+		receivedEmpty bool
+		pageToken     string
+	)
 
 	for {
-		es, err := r(ctx, sourceID, time.Unix(0, c.start), readOpts...)
+		pageOpts := readOpts
+		if pageToken != "" {
+			pageOpts = append(readOpts[:len(readOpts):len(readOpts)], WithPageToken(pageToken))
+		}
+
+		es, nextPageToken, err := r(ctx, sourceID, time.Unix(0, c.start), pageOpts...)
 		if err != nil && ctx.Err() != nil {
 			// Context cancelled
 			return
@@ -70,6 +101,10 @@ func Walk(ctx context.Context, sourceID string, v Visitor, r Reader, opts ...Wal
 			continue
 		}
 
+		// The page token can only be used if every envelope of the page is
+		// visited.
+		read := len(es)
+
 		if c.end.IsZero() || !receivedEmpty {
 			// Prune envelopes for any that are too new or from the future.
 			withDelay := time.Now().Add(-c.delay).UnixNano()
@@ -112,6 +147,10 @@ func Walk(ctx context.Context, sourceID string, v Visitor, r Reader, opts ...Wal
 		}
 
 		c.start = es[len(es)-1].Timestamp + 1
+		pageToken = ""
+		if len(es) == read {
+			pageToken = nextPageToken
+		}
 	}
 }
 
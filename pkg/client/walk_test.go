@@ -325,6 +325,75 @@ func TestWalkPassesOpts(t *testing.T) {
 	assertQueryParam(u, "envelope_types", "LOG", "GAUGE")
 }
 
+func TestWalkPagesUsesPageTokens(t *testing.T) {
+	t.Parallel()
+
+	r := &stubReader{
+		envelopes: [][]*loggregator_v2.Envelope{
+			{{Timestamp: 1}, {Timestamp: 2}},
+			{{Timestamp: 2}, {Timestamp: 3}},
+			nil,
+		},
+		tokens: []string{"token-1", "token-2", ""},
+		errs:   []error{nil, nil, nil},
+	}
+
+	var es int
+	client.WalkPages(context.Background(), "some-id", func(e []*loggregator_v2.Envelope) bool {
+		es += len(e)
+		return true
+	}, r.readPage)
+
+	if es != 4 {
+		t.Fatalf("expected to visit 4 envelopes: %d", es)
+	}
+
+	if len(r.opts) != 3 {
+		t.Fatalf("expected read to be invoked 3 times: %d", len(r.opts))
+	}
+
+	for i, token := range []string{"", "token-1", "token-2"} {
+		if actual := pageTokenOf(r.opts[i]); actual != token {
+			t.Fatalf("expected read %d to use page token %q: %q", i, token, actual)
+		}
+	}
+}
+
+func TestWalkPagesDoesNotUsePageTokenOfTrimmedPage(t *testing.T) {
+	t.Parallel()
+
+	r := &stubReader{
+		envelopes: [][]*loggregator_v2.Envelope{
+			{
+				{Timestamp: 1},
+				// Give too new of a value.
+				{Timestamp: time.Now().Add(-5 * time.Second).UnixNano()},
+			},
+			nil,
+		},
+		tokens: []string{"token-1", ""},
+		errs:   []error{nil, nil},
+	}
+
+	client.WalkPages(context.Background(), "some-id", func(e []*loggregator_v2.Envelope) bool {
+		return true
+	}, r.readPage,
+		client.WithWalkDelay(6*time.Second),
+	)
+
+	if len(r.starts) != 2 {
+		t.Fatalf("expected starts to have 2 entries: %d", len(r.starts))
+	}
+
+	if r.starts[1] != 2 {
+		t.Fatalf("expected to start after the last visited envelope: %d", r.starts[1])
+	}
+
+	if token := pageTokenOf(r.opts[1]); token != "" {
+		t.Fatalf("expected not to use the page token: %q", token)
+	}
+}
+
 // func TestWalkFiltersOnName(t *testing.T) {
 // 	t.Parallel()
 
@@ -463,6 +532,7 @@ type stubReader struct {
 	opts      [][]client.ReadOption
 
 	envelopes [][]*loggregator_v2.Envelope
+	tokens    []string
 	errs      []error
 }
 
@@ -490,3 +560,24 @@ func (s *stubReader) read(ctx context.Context, sourceID string, start time.Time,
 
 	return s.envelopes[0], s.errs[0]
 }
+
+func (s *stubReader) readPage(ctx context.Context, sourceID string, start time.Time, opts ...client.ReadOption) ([]*loggregator_v2.Envelope, string, error) {
+	var token string
+	if len(s.tokens) > 0 {
+		token = s.tokens[0]
+		s.tokens = s.tokens[1:]
+	}
+
+	es, err := s.read(ctx, sourceID, start, opts...)
+	return es, token, err
+}
+
+func pageTokenOf(opts []client.ReadOption) string {
+	u := &url.URL{}
+	q := u.Query()
+	for _, o := range opts {
+		o(u, q)
+	}
+
+	return q.Get("page_token")
+}
@@ -36,6 +36,10 @@ func (m *PromqlMarshaler) Marshal(v interface{}) ([]byte, error) {
 		}
 
 		return appendNewLine(json.Marshal(result))
+	case *logcache_v1.PromQL_LabelsResult,
+		*logcache_v1.PromQL_LabelValuesResult,
+		*logcache_v1.PromQL_SeriesResult:
+		return appendNewLine(json.Marshal(assembleDiscoveryResult(q)))
 	default:
 		return appendNewLine(m.fallback.Marshal(v))
 	}
@@ -52,6 +56,13 @@ type queryResult struct {
 	Error     string     `json:"error,omitempty"`
 }
 
+// discoveryResult is the response of the label and series discovery
+// endpoints, whose data is a plain list rather than a typed result.
+type discoveryResult struct {
+	Status string      `json:"status"`
+	Data   interface{} `json:"data"`
+}
+
 type resultData struct {
 	ResultType string          `json:"resultType"`
 	Result     json.RawMessage `json:"result,omitempty"`
@@ -109,6 +120,37 @@ func (m *PromqlMarshaler) assembleRangeQueryResult(v *logcache_v1.PromQL_RangeQu
 	}, nil
 }
 
+func assembleDiscoveryResult(v interface{}) *discoveryResult {
+	// NOTE: This is required to make sure that JSON marshals an empty result
+	// set as `[]` and not `null`.
+	data := make([]interface{}, 0)
+
+	switch r := v.(type) {
+	case *logcache_v1.PromQL_LabelsResult:
+		for _, l := range r.GetLabels() {
+			data = append(data, l)
+		}
+	case *logcache_v1.PromQL_LabelValuesResult:
+		for _, value := range r.GetValues() {
+			data = append(data, value)
+		}
+	case *logcache_v1.PromQL_SeriesResult:
+		for _, s := range r.GetSeries() {
+			labels := s.GetLabels()
+			if labels == nil {
+				labels = make(map[string]string, 0)
+			}
+
+			data = append(data, labels)
+		}
+	}
+
+	return &discoveryResult{
+		Status: "success",
+		Data:   data,
+	}
+}
+
 func assembleScalarResultData(v *logcache_v1.PromQL_Scalar) (resultData, error) {
 	point, err := assemblePoint(v.GetTime(), v.GetValue())
 	if err != nil {
@@ -230,6 +272,10 @@ func (m *PromqlMarshaler) NewEncoder(w io.Writer) runtime.Encoder {
 			}
 
 			return jsonEncoder.Encode(result)
+		case *logcache_v1.PromQL_LabelsResult,
+			*logcache_v1.PromQL_LabelValuesResult,
+			*logcache_v1.PromQL_SeriesResult:
+			return jsonEncoder.Encode(assembleDiscoveryResult(q))
 		default:
 			return fallbackEncoder.Encode(v)
 		}
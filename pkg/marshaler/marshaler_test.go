@@ -428,6 +428,74 @@ var _ = Describe("PromqlMarshaler", func() {
 			}`))
 		})
 
+		It("handles a labels result", func() {
+			marshaler := marshaler.NewPromqlMarshaler(&mockMarshaler{})
+
+			result, err := marshaler.Marshal(&logcache_v1.PromQL_LabelsResult{
+				Labels: []string{"__name__", "source_id"},
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(MatchJSON(`{
+				"status": "success",
+				"data": ["__name__", "source_id"]
+			}`))
+		})
+
+		It("handles a label values result", func() {
+			marshaler := marshaler.NewPromqlMarshaler(&mockMarshaler{})
+
+			result, err := marshaler.Marshal(&logcache_v1.PromQL_LabelValuesResult{
+				Values: []string{"cpu", "memory"},
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(MatchJSON(`{
+				"status": "success",
+				"data": ["cpu", "memory"]
+			}`))
+		})
+
+		It("handles a series result", func() {
+			marshaler := marshaler.NewPromqlMarshaler(&mockMarshaler{})
+
+			result, err := marshaler.Marshal(&logcache_v1.PromQL_SeriesResult{
+				Series: []*logcache_v1.PromQL_LabelSet{
+					{
+						Labels: map[string]string{
+							"__name__":  "cpu",
+							"source_id": "some-id",
+						},
+					},
+					{},
+				},
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(MatchJSON(`{
+				"status": "success",
+				"data": [
+					{
+						"__name__": "cpu",
+						"source_id": "some-id"
+					},
+					{}
+				]
+			}`))
+		})
+
+		It("handles an empty labels result", func() {
+			marshaler := marshaler.NewPromqlMarshaler(&mockMarshaler{})
+
+			result, err := marshaler.Marshal(&logcache_v1.PromQL_LabelsResult{})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(MatchJSON(`{
+				"status": "success",
+				"data": []
+			}`))
+		})
+
 		It("reports errors for invalid timestamps", func() {
 			marshaler := marshaler.NewPromqlMarshaler(&mockMarshaler{})
 
@@ -485,6 +553,24 @@ var _ = Describe("PromqlMarshaler", func() {
 			}`))
 		})
 
+		It("can encode a series result to a writer", func() {
+			encoded := bytes.NewBuffer(nil)
+			marshaler := marshaler.NewPromqlMarshaler(&mockMarshaler{})
+			encoder := marshaler.NewEncoder(encoded)
+
+			err := encoder.Encode(&logcache_v1.PromQL_SeriesResult{
+				Series: []*logcache_v1.PromQL_LabelSet{
+					{Labels: map[string]string{"__name__": "cpu"}},
+				},
+			})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(encoded.String()).To(MatchJSON(`{
+				"status": "success",
+				"data": [{"__name__": "cpu"}]
+			}`))
+		})
+
 		It("falls back to the fallback marshaler for non-PromQL replies", func() {
 			encoded := bytes.NewBuffer(nil)
 			marshaler := marshaler.NewPromqlMarshaler(&mockMarshaler{})
@@ -0,0 +1,232 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: admin.proto
+
+package logcache_v1
+
+import proto "github.com/golang/protobuf/proto"
+import fmt "fmt"
+import math "math"
+import _ "google.golang.org/genproto/googleapis/api/annotations"
+
+import (
+	context "golang.org/x/net/context"
+	grpc "google.golang.org/grpc"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
+
+type PurgeRequest struct {
+	SourceId string `protobuf:"bytes,1,opt,name=source_id,json=sourceId,proto3" json:"source_id,omitempty"`
+	// start_time and end_time bound the envelopes that are deleted to
+	// [start_time..end_time). They default to every envelope.
+	StartTime            int64    `protobuf:"varint,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime              int64    `protobuf:"varint,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	LocalOnly            bool     `protobuf:"varint,4,opt,name=local_only,json=localOnly,proto3" json:"local_only,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PurgeRequest) Reset()         { *m = PurgeRequest{} }
+func (m *PurgeRequest) String() string { return proto.CompactTextString(m) }
+func (*PurgeRequest) ProtoMessage()    {}
+func (*PurgeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_admin_21d3c8daada367e0, []int{0}
+}
+func (m *PurgeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PurgeRequest.Unmarshal(m, b)
+}
+func (m *PurgeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PurgeRequest.Marshal(b, m, deterministic)
+}
+func (dst *PurgeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PurgeRequest.Merge(dst, src)
+}
+func (m *PurgeRequest) XXX_Size() int {
+	return xxx_messageInfo_PurgeRequest.Size(m)
+}
+func (m *PurgeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PurgeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PurgeRequest proto.InternalMessageInfo
+
+func (m *PurgeRequest) GetSourceId() string {
+	if m != nil {
+		return m.SourceId
+	}
+	return ""
+}
+
+func (m *PurgeRequest) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *PurgeRequest) GetEndTime() int64 {
+	if m != nil {
+		return m.EndTime
+	}
+	return 0
+}
+
+func (m *PurgeRequest) GetLocalOnly() bool {
+	if m != nil {
+		return m.LocalOnly
+	}
+	return false
+}
+
+type PurgeResponse struct {
+	// The number of envelopes that were deleted.
+	Purged               int64    `protobuf:"varint,1,opt,name=purged,proto3" json:"purged,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PurgeResponse) Reset()         { *m = PurgeResponse{} }
+func (m *PurgeResponse) String() string { return proto.CompactTextString(m) }
+func (*PurgeResponse) ProtoMessage()    {}
+func (*PurgeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_admin_21d3c8daada367e0, []int{1}
+}
+func (m *PurgeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PurgeResponse.Unmarshal(m, b)
+}
+func (m *PurgeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PurgeResponse.Marshal(b, m, deterministic)
+}
+func (dst *PurgeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PurgeResponse.Merge(dst, src)
+}
+func (m *PurgeResponse) XXX_Size() int {
+	return xxx_messageInfo_PurgeResponse.Size(m)
+}
+func (m *PurgeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PurgeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PurgeResponse proto.InternalMessageInfo
+
+func (m *PurgeResponse) GetPurged() int64 {
+	if m != nil {
+		return m.Purged
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterType((*PurgeRequest)(nil), "logcache.v1.PurgeRequest")
+	proto.RegisterType((*PurgeResponse)(nil), "logcache.v1.PurgeResponse")
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AdminClient interface {
+	// Purge deletes the envelopes of a source ID from every node that
+	// stores it.
+	Purge(ctx context.Context, in *PurgeRequest, opts ...grpc.CallOption) (*PurgeResponse, error)
+}
+
+type adminClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAdminClient(cc *grpc.ClientConn) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) Purge(ctx context.Context, in *PurgeRequest, opts ...grpc.CallOption) (*PurgeResponse, error) {
+	out := new(PurgeResponse)
+	err := c.cc.Invoke(ctx, "/logcache.v1.Admin/Purge", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+type AdminServer interface {
+	// Purge deletes the envelopes of a source ID from every node that
+	// stores it.
+	Purge(context.Context, *PurgeRequest) (*PurgeResponse, error)
+}
+
+func RegisterAdminServer(s *grpc.Server, srv AdminServer) {
+	s.RegisterService(&_Admin_serviceDesc, srv)
+}
+
+func _Admin_Purge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).Purge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/logcache.v1.Admin/Purge",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).Purge(ctx, req.(*PurgeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Admin_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "logcache.v1.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Purge",
+			Handler:    _Admin_Purge_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "admin.proto",
+}
+
+func init() { proto.RegisterFile("admin.proto", fileDescriptor_admin_21d3c8daada367e0) }
+
+var fileDescriptor_admin_21d3c8daada367e0 = []byte{
+	// 268 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x90, 0xc1, 0x4a, 0xc3, 0x40,
+	0x10, 0x86, 0x49, 0x6b, 0x6b, 0x33, 0xd5, 0xcb, 0x1e, 0x24, 0x8d, 0xad, 0x84, 0x20, 0x18, 0x72,
+	0x48, 0xa8, 0x9e, 0x3d, 0x78, 0xf4, 0xa4, 0x04, 0xef, 0x61, 0xcd, 0x0e, 0x71, 0x61, 0xb3, 0x13,
+	0xb3, 0x9b, 0x42, 0x11, 0x2f, 0xe2, 0x1b, 0xf8, 0x68, 0xbe, 0x82, 0x0f, 0x22, 0xdd, 0x14, 0xe9,
+	0xc1, 0xe3, 0xfe, 0xdf, 0x0e, 0x33, 0xdf, 0x0f, 0x73, 0x2e, 0x1a, 0xa9, 0xb3, 0xb6, 0x23, 0x4b,
+	0x6c, 0xae, 0xa8, 0xae, 0x78, 0xf5, 0x82, 0xd9, 0x66, 0x1d, 0x2e, 0x6b, 0xa2, 0x5a, 0x61, 0xce,
+	0x5b, 0x99, 0x73, 0xad, 0xc9, 0x72, 0x2b, 0x49, 0x9b, 0xe1, 0x6b, 0xfc, 0xe9, 0xc1, 0xc9, 0x63,
+	0xdf, 0xd5, 0x58, 0xe0, 0x6b, 0x8f, 0xc6, 0xb2, 0x73, 0xf0, 0x0d, 0xf5, 0x5d, 0x85, 0xa5, 0x14,
+	0x81, 0x17, 0x79, 0x89, 0x5f, 0xcc, 0x86, 0xe0, 0x5e, 0xb0, 0x15, 0x80, 0xb1, 0xbc, 0xb3, 0xa5,
+	0x95, 0x0d, 0x06, 0xa3, 0xc8, 0x4b, 0xc6, 0x85, 0xef, 0x92, 0x27, 0xd9, 0x20, 0x5b, 0xc0, 0x0c,
+	0xb5, 0x18, 0xe0, 0xd8, 0xc1, 0x63, 0xd4, 0xc2, 0xa1, 0x15, 0x80, 0xa2, 0x8a, 0xab, 0x92, 0xb4,
+	0xda, 0x06, 0x47, 0x91, 0x97, 0xcc, 0x0a, 0xdf, 0x25, 0x0f, 0x5a, 0x6d, 0xe3, 0x2b, 0x38, 0xdd,
+	0x5f, 0x61, 0x5a, 0xd2, 0x06, 0xd9, 0x19, 0x4c, 0xdb, 0x5d, 0x30, 0xdc, 0x30, 0x2e, 0xf6, 0xaf,
+	0xeb, 0x06, 0x26, 0x77, 0x3b, 0x53, 0x26, 0x60, 0xe2, 0x26, 0xd8, 0x22, 0x3b, 0xb0, 0xcd, 0x0e,
+	0x5d, 0xc2, 0xf0, 0x3f, 0x34, 0x2c, 0x88, 0x2f, 0x3f, 0xbe, 0x7f, 0xbe, 0x46, 0x17, 0xe9, 0xd2,
+	0x15, 0xb3, 0x59, 0xe7, 0x6e, 0x41, 0xfe, 0xf6, 0x27, 0x7f, 0x9b, 0xa6, 0xef, 0xcf, 0x53, 0xd7,
+	0xd2, 0xcd, 0xef, 0x00, 0x2c, 0xda, 0xce, 0xfc, 0x5f, 0x01, 0x00, 0x00,
+}
@@ -57,27 +57,133 @@ func (x EnvelopeType) String() string {
 	return proto.EnumName(EnvelopeType_name, int32(x))
 }
 func (EnvelopeType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_egress_39930eba197832be, []int{0}
+	return fileDescriptor_egress_a5020915a711fd32, []int{0}
+}
+
+type MetaSortBy int32
+
+const (
+	MetaSortBy_SOURCE_ID        MetaSortBy = 0
+	MetaSortBy_COUNT            MetaSortBy = 1
+	MetaSortBy_NEWEST_TIMESTAMP MetaSortBy = 2
+	MetaSortBy_EXPIRED          MetaSortBy = 3
+)
+
+var MetaSortBy_name = map[int32]string{
+	0: "SOURCE_ID",
+	1: "COUNT",
+	2: "NEWEST_TIMESTAMP",
+	3: "EXPIRED",
+}
+var MetaSortBy_value = map[string]int32{
+	"SOURCE_ID":        0,
+	"COUNT":            1,
+	"NEWEST_TIMESTAMP": 2,
+	"EXPIRED":          3,
+}
+
+func (x MetaSortBy) String() string {
+	return proto.EnumName(MetaSortBy_name, int32(x))
+}
+func (MetaSortBy) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_egress_a5020915a711fd32, []int{1}
+}
+
+type ReadAggregateRequest_Aggregation int32
+
+const (
+	ReadAggregateRequest_LAST  ReadAggregateRequest_Aggregation = 0
+	ReadAggregateRequest_MIN   ReadAggregateRequest_Aggregation = 1
+	ReadAggregateRequest_MAX   ReadAggregateRequest_Aggregation = 2
+	ReadAggregateRequest_AVG   ReadAggregateRequest_Aggregation = 3
+	ReadAggregateRequest_SUM   ReadAggregateRequest_Aggregation = 4
+	ReadAggregateRequest_COUNT ReadAggregateRequest_Aggregation = 5
+)
+
+var ReadAggregateRequest_Aggregation_name = map[int32]string{
+	0: "LAST",
+	1: "MIN",
+	2: "MAX",
+	3: "AVG",
+	4: "SUM",
+	5: "COUNT",
+}
+var ReadAggregateRequest_Aggregation_value = map[string]int32{
+	"LAST":  0,
+	"MIN":   1,
+	"MAX":   2,
+	"AVG":   3,
+	"SUM":   4,
+	"COUNT": 5,
+}
+
+func (x ReadAggregateRequest_Aggregation) String() string {
+	return proto.EnumName(ReadAggregateRequest_Aggregation_name, int32(x))
+}
+func (ReadAggregateRequest_Aggregation) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_egress_a5020915a711fd32, []int{2, 0}
+}
+
+type ReadHistogramRequest_GroupBy int32
+
+const (
+	ReadHistogramRequest_NONE        ReadHistogramRequest_GroupBy = 0
+	ReadHistogramRequest_LOG_TYPE    ReadHistogramRequest_GroupBy = 1
+	ReadHistogramRequest_INSTANCE_ID ReadHistogramRequest_GroupBy = 2
+)
+
+var ReadHistogramRequest_GroupBy_name = map[int32]string{
+	0: "NONE",
+	1: "LOG_TYPE",
+	2: "INSTANCE_ID",
+}
+var ReadHistogramRequest_GroupBy_value = map[string]int32{
+	"NONE":        0,
+	"LOG_TYPE":    1,
+	"INSTANCE_ID": 2,
+}
+
+func (x ReadHistogramRequest_GroupBy) String() string {
+	return proto.EnumName(ReadHistogramRequest_GroupBy_name, int32(x))
+}
+func (ReadHistogramRequest_GroupBy) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_egress_a5020915a711fd32, []int{4, 0}
 }
 
 type ReadRequest struct {
-	SourceId             string         `protobuf:"bytes,1,opt,name=source_id,json=sourceId,proto3" json:"source_id,omitempty"`
-	StartTime            int64          `protobuf:"varint,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
-	EndTime              int64          `protobuf:"varint,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
-	Limit                int64          `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
-	EnvelopeTypes        []EnvelopeType `protobuf:"varint,5,rep,packed,name=envelope_types,json=envelopeTypes,proto3,enum=logcache.v1.EnvelopeType" json:"envelope_types,omitempty"`
-	Descending           bool           `protobuf:"varint,6,opt,name=descending,proto3" json:"descending,omitempty"`
-	NameFilter           string         `protobuf:"bytes,7,opt,name=name_filter,json=nameFilter,proto3" json:"name_filter,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
-	XXX_unrecognized     []byte         `json:"-"`
-	XXX_sizecache        int32          `json:"-"`
+	SourceId      string         `protobuf:"bytes,1,opt,name=source_id,json=sourceId,proto3" json:"source_id,omitempty"`
+	StartTime     int64          `protobuf:"varint,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime       int64          `protobuf:"varint,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	Limit         int64          `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	EnvelopeTypes []EnvelopeType `protobuf:"varint,5,rep,packed,name=envelope_types,json=envelopeTypes,proto3,enum=logcache.v1.EnvelopeType" json:"envelope_types,omitempty"`
+	Descending    bool           `protobuf:"varint,6,opt,name=descending,proto3" json:"descending,omitempty"`
+	NameFilter    string         `protobuf:"bytes,7,opt,name=name_filter,json=nameFilter,proto3" json:"name_filter,omitempty"`
+	// payload_filter only returns log envelopes with a matching payload. It
+	// is treated as a substring unless payload_filter_regex is set, in which
+	// case it is a RE2 regular expression.
+	PayloadFilter                string `protobuf:"bytes,8,opt,name=payload_filter,json=payloadFilter,proto3" json:"payload_filter,omitempty"`
+	PayloadFilterRegex           bool   `protobuf:"varint,9,opt,name=payload_filter_regex,json=payloadFilterRegex,proto3" json:"payload_filter_regex,omitempty"`
+	PayloadFilterCaseInsensitive bool   `protobuf:"varint,10,opt,name=payload_filter_case_insensitive,json=payloadFilterCaseInsensitive,proto3" json:"payload_filter_case_insensitive,omitempty"`
+	// tag_matchers only returns envelopes with tags that satisfy every
+	// matcher. Each matcher is of the form <name><op><value> where op is one
+	// of =, !=, =~ or !~. Regular expressions are anchored on both ends.
+	TagMatchers []string `protobuf:"bytes,11,rep,name=tag_matchers,json=tagMatchers,proto3" json:"tag_matchers,omitempty"`
+	// instance_id only returns envelopes with the given instance ID.
+	InstanceId string `protobuf:"bytes,12,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
+	// page_token resumes a read after the last envelope of a previous
+	// response. It replaces start_time for ascending reads and end_time for
+	// descending reads. Every other field has to match the previous request.
+	PageToken            string   `protobuf:"bytes,13,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *ReadRequest) Reset()         { *m = ReadRequest{} }
 func (m *ReadRequest) String() string { return proto.CompactTextString(m) }
 func (*ReadRequest) ProtoMessage()    {}
 func (*ReadRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_egress_39930eba197832be, []int{0}
+	return fileDescriptor_egress_a5020915a711fd32, []int{0}
 }
 func (m *ReadRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadRequest.Unmarshal(m, b)
@@ -146,18 +252,65 @@ func (m *ReadRequest) GetNameFilter() string {
 	return ""
 }
 
+func (m *ReadRequest) GetPayloadFilter() string {
+	if m != nil {
+		return m.PayloadFilter
+	}
+	return ""
+}
+
+func (m *ReadRequest) GetPayloadFilterRegex() bool {
+	if m != nil {
+		return m.PayloadFilterRegex
+	}
+	return false
+}
+
+func (m *ReadRequest) GetPayloadFilterCaseInsensitive() bool {
+	if m != nil {
+		return m.PayloadFilterCaseInsensitive
+	}
+	return false
+}
+
+func (m *ReadRequest) GetTagMatchers() []string {
+	if m != nil {
+		return m.TagMatchers
+	}
+	return nil
+}
+
+func (m *ReadRequest) GetInstanceId() string {
+	if m != nil {
+		return m.InstanceId
+	}
+	return ""
+}
+
+func (m *ReadRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type ReadResponse struct {
-	Envelopes            *loggregator_v2.EnvelopeBatch `protobuf:"bytes,1,opt,name=envelopes,proto3" json:"envelopes,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}                      `json:"-"`
-	XXX_unrecognized     []byte                        `json:"-"`
-	XXX_sizecache        int32                         `json:"-"`
+	Envelopes *loggregator_v2.EnvelopeBatch `protobuf:"bytes,1,opt,name=envelopes,proto3" json:"envelopes,omitempty"`
+	// next_page_token is the position after the last envelope of the
+	// response. Unlike the timestamp of the last envelope, it does not skip
+	// envelopes that share that timestamp. It is only set if the response
+	// reached the limit; an empty token means the read is complete.
+	NextPageToken        string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *ReadResponse) Reset()         { *m = ReadResponse{} }
 func (m *ReadResponse) String() string { return proto.CompactTextString(m) }
 func (*ReadResponse) ProtoMessage()    {}
 func (*ReadResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_egress_39930eba197832be, []int{1}
+	return fileDescriptor_egress_a5020915a711fd32, []int{1}
 }
 func (m *ReadResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_ReadResponse.Unmarshal(m, b)
@@ -184,8 +337,509 @@ func (m *ReadResponse) GetEnvelopes() *loggregator_v2.EnvelopeBatch {
 	return nil
 }
 
+func (m *ReadResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
+type ReadAggregateRequest struct {
+	SourceId  string `protobuf:"bytes,1,opt,name=source_id,json=sourceId,proto3" json:"source_id,omitempty"`
+	StartTime int64  `protobuf:"varint,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime   int64  `protobuf:"varint,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// name is the name of the counter, gauge metric or timer to aggregate.
+	// The value of a timer is its duration.
+	Name string `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	// step is the width of each bucket in nanoseconds. Buckets start at
+	// multiples of the step.
+	Step int64 `protobuf:"varint,5,opt,name=step,proto3" json:"step,omitempty"`
+	// aggregation combines the values of each bucket. It defaults to LAST.
+	Aggregation          ReadAggregateRequest_Aggregation `protobuf:"varint,6,opt,name=aggregation,proto3,enum=logcache.v1.ReadAggregateRequest_Aggregation" json:"aggregation,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                         `json:"-"`
+	XXX_unrecognized     []byte                           `json:"-"`
+	XXX_sizecache        int32                            `json:"-"`
+}
+
+func (m *ReadAggregateRequest) Reset()         { *m = ReadAggregateRequest{} }
+func (m *ReadAggregateRequest) String() string { return proto.CompactTextString(m) }
+func (*ReadAggregateRequest) ProtoMessage()    {}
+func (*ReadAggregateRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_egress_a5020915a711fd32, []int{2}
+}
+func (m *ReadAggregateRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadAggregateRequest.Unmarshal(m, b)
+}
+func (m *ReadAggregateRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadAggregateRequest.Marshal(b, m, deterministic)
+}
+func (dst *ReadAggregateRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadAggregateRequest.Merge(dst, src)
+}
+func (m *ReadAggregateRequest) XXX_Size() int {
+	return xxx_messageInfo_ReadAggregateRequest.Size(m)
+}
+func (m *ReadAggregateRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadAggregateRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadAggregateRequest proto.InternalMessageInfo
+
+func (m *ReadAggregateRequest) GetSourceId() string {
+	if m != nil {
+		return m.SourceId
+	}
+	return ""
+}
+
+func (m *ReadAggregateRequest) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *ReadAggregateRequest) GetEndTime() int64 {
+	if m != nil {
+		return m.EndTime
+	}
+	return 0
+}
+
+func (m *ReadAggregateRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *ReadAggregateRequest) GetStep() int64 {
+	if m != nil {
+		return m.Step
+	}
+	return 0
+}
+
+func (m *ReadAggregateRequest) GetAggregation() ReadAggregateRequest_Aggregation {
+	if m != nil {
+		return m.Aggregation
+	}
+	return ReadAggregateRequest_LAST
+}
+
+type ReadAggregateResponse struct {
+	// points holds a point for each bucket with values in ascending order.
+	Points               []*ReadAggregateResponse_Point `protobuf:"bytes,1,rep,name=points,proto3" json:"points,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                       `json:"-"`
+	XXX_unrecognized     []byte                         `json:"-"`
+	XXX_sizecache        int32                          `json:"-"`
+}
+
+func (m *ReadAggregateResponse) Reset()         { *m = ReadAggregateResponse{} }
+func (m *ReadAggregateResponse) String() string { return proto.CompactTextString(m) }
+func (*ReadAggregateResponse) ProtoMessage()    {}
+func (*ReadAggregateResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_egress_a5020915a711fd32, []int{3}
+}
+func (m *ReadAggregateResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadAggregateResponse.Unmarshal(m, b)
+}
+func (m *ReadAggregateResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadAggregateResponse.Marshal(b, m, deterministic)
+}
+func (dst *ReadAggregateResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadAggregateResponse.Merge(dst, src)
+}
+func (m *ReadAggregateResponse) XXX_Size() int {
+	return xxx_messageInfo_ReadAggregateResponse.Size(m)
+}
+func (m *ReadAggregateResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadAggregateResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadAggregateResponse proto.InternalMessageInfo
+
+func (m *ReadAggregateResponse) GetPoints() []*ReadAggregateResponse_Point {
+	if m != nil {
+		return m.Points
+	}
+	return nil
+}
+
+type ReadAggregateResponse_Point struct {
+	// timestamp is the start of the bucket.
+	Timestamp            int64    `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Value                float64  `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadAggregateResponse_Point) Reset()         { *m = ReadAggregateResponse_Point{} }
+func (m *ReadAggregateResponse_Point) String() string { return proto.CompactTextString(m) }
+func (*ReadAggregateResponse_Point) ProtoMessage()    {}
+func (*ReadAggregateResponse_Point) Descriptor() ([]byte, []int) {
+	return fileDescriptor_egress_a5020915a711fd32, []int{3, 0}
+}
+func (m *ReadAggregateResponse_Point) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadAggregateResponse_Point.Unmarshal(m, b)
+}
+func (m *ReadAggregateResponse_Point) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadAggregateResponse_Point.Marshal(b, m, deterministic)
+}
+func (dst *ReadAggregateResponse_Point) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadAggregateResponse_Point.Merge(dst, src)
+}
+func (m *ReadAggregateResponse_Point) XXX_Size() int {
+	return xxx_messageInfo_ReadAggregateResponse_Point.Size(m)
+}
+func (m *ReadAggregateResponse_Point) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadAggregateResponse_Point.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadAggregateResponse_Point proto.InternalMessageInfo
+
+func (m *ReadAggregateResponse_Point) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *ReadAggregateResponse_Point) GetValue() float64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+type ReadHistogramRequest struct {
+	SourceId  string `protobuf:"bytes,1,opt,name=source_id,json=sourceId,proto3" json:"source_id,omitempty"`
+	StartTime int64  `protobuf:"varint,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime   int64  `protobuf:"varint,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	// step is the width of each bucket in nanoseconds. Buckets start at
+	// multiples of the step.
+	Step int64 `protobuf:"varint,4,opt,name=step,proto3" json:"step,omitempty"`
+	// group_by further splits the counts of each envelope type. LOG_TYPE
+	// splits logs into OUT and ERR, INSTANCE_ID splits every envelope type
+	// by the instance ID.
+	GroupBy              ReadHistogramRequest_GroupBy `protobuf:"varint,5,opt,name=group_by,json=groupBy,proto3,enum=logcache.v1.ReadHistogramRequest_GroupBy" json:"group_by,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                     `json:"-"`
+	XXX_unrecognized     []byte                       `json:"-"`
+	XXX_sizecache        int32                        `json:"-"`
+}
+
+func (m *ReadHistogramRequest) Reset()         { *m = ReadHistogramRequest{} }
+func (m *ReadHistogramRequest) String() string { return proto.CompactTextString(m) }
+func (*ReadHistogramRequest) ProtoMessage()    {}
+func (*ReadHistogramRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_egress_a5020915a711fd32, []int{4}
+}
+func (m *ReadHistogramRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadHistogramRequest.Unmarshal(m, b)
+}
+func (m *ReadHistogramRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadHistogramRequest.Marshal(b, m, deterministic)
+}
+func (dst *ReadHistogramRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadHistogramRequest.Merge(dst, src)
+}
+func (m *ReadHistogramRequest) XXX_Size() int {
+	return xxx_messageInfo_ReadHistogramRequest.Size(m)
+}
+func (m *ReadHistogramRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadHistogramRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadHistogramRequest proto.InternalMessageInfo
+
+func (m *ReadHistogramRequest) GetSourceId() string {
+	if m != nil {
+		return m.SourceId
+	}
+	return ""
+}
+
+func (m *ReadHistogramRequest) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *ReadHistogramRequest) GetEndTime() int64 {
+	if m != nil {
+		return m.EndTime
+	}
+	return 0
+}
+
+func (m *ReadHistogramRequest) GetStep() int64 {
+	if m != nil {
+		return m.Step
+	}
+	return 0
+}
+
+func (m *ReadHistogramRequest) GetGroupBy() ReadHistogramRequest_GroupBy {
+	if m != nil {
+		return m.GroupBy
+	}
+	return ReadHistogramRequest_NONE
+}
+
+type ReadHistogramResponse struct {
+	// buckets holds a bucket for each step with envelopes in ascending
+	// order.
+	Buckets              []*ReadHistogramResponse_Bucket `protobuf:"bytes,1,rep,name=buckets,proto3" json:"buckets,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                        `json:"-"`
+	XXX_unrecognized     []byte                          `json:"-"`
+	XXX_sizecache        int32                           `json:"-"`
+}
+
+func (m *ReadHistogramResponse) Reset()         { *m = ReadHistogramResponse{} }
+func (m *ReadHistogramResponse) String() string { return proto.CompactTextString(m) }
+func (*ReadHistogramResponse) ProtoMessage()    {}
+func (*ReadHistogramResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_egress_a5020915a711fd32, []int{5}
+}
+func (m *ReadHistogramResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadHistogramResponse.Unmarshal(m, b)
+}
+func (m *ReadHistogramResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadHistogramResponse.Marshal(b, m, deterministic)
+}
+func (dst *ReadHistogramResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadHistogramResponse.Merge(dst, src)
+}
+func (m *ReadHistogramResponse) XXX_Size() int {
+	return xxx_messageInfo_ReadHistogramResponse.Size(m)
+}
+func (m *ReadHistogramResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadHistogramResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadHistogramResponse proto.InternalMessageInfo
+
+func (m *ReadHistogramResponse) GetBuckets() []*ReadHistogramResponse_Bucket {
+	if m != nil {
+		return m.Buckets
+	}
+	return nil
+}
+
+type ReadHistogramResponse_Count struct {
+	EnvelopeType EnvelopeType `protobuf:"varint,1,opt,name=envelope_type,json=envelopeType,proto3,enum=logcache.v1.EnvelopeType" json:"envelope_type,omitempty"`
+	// group is the log type or instance ID the count belongs to. It is
+	// empty unless the request groups the counts.
+	Group                string   `protobuf:"bytes,2,opt,name=group,proto3" json:"group,omitempty"`
+	Count                int64    `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ReadHistogramResponse_Count) Reset()         { *m = ReadHistogramResponse_Count{} }
+func (m *ReadHistogramResponse_Count) String() string { return proto.CompactTextString(m) }
+func (*ReadHistogramResponse_Count) ProtoMessage()    {}
+func (*ReadHistogramResponse_Count) Descriptor() ([]byte, []int) {
+	return fileDescriptor_egress_a5020915a711fd32, []int{5, 0}
+}
+func (m *ReadHistogramResponse_Count) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadHistogramResponse_Count.Unmarshal(m, b)
+}
+func (m *ReadHistogramResponse_Count) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadHistogramResponse_Count.Marshal(b, m, deterministic)
+}
+func (dst *ReadHistogramResponse_Count) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadHistogramResponse_Count.Merge(dst, src)
+}
+func (m *ReadHistogramResponse_Count) XXX_Size() int {
+	return xxx_messageInfo_ReadHistogramResponse_Count.Size(m)
+}
+func (m *ReadHistogramResponse_Count) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadHistogramResponse_Count.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadHistogramResponse_Count proto.InternalMessageInfo
+
+func (m *ReadHistogramResponse_Count) GetEnvelopeType() EnvelopeType {
+	if m != nil {
+		return m.EnvelopeType
+	}
+	return EnvelopeType_ANY
+}
+
+func (m *ReadHistogramResponse_Count) GetGroup() string {
+	if m != nil {
+		return m.Group
+	}
+	return ""
+}
+
+func (m *ReadHistogramResponse_Count) GetCount() int64 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+type ReadHistogramResponse_Bucket struct {
+	// timestamp is the start of the bucket.
+	Timestamp            int64                          `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Counts               []*ReadHistogramResponse_Count `protobuf:"bytes,2,rep,name=counts,proto3" json:"counts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                       `json:"-"`
+	XXX_unrecognized     []byte                         `json:"-"`
+	XXX_sizecache        int32                          `json:"-"`
+}
+
+func (m *ReadHistogramResponse_Bucket) Reset()         { *m = ReadHistogramResponse_Bucket{} }
+func (m *ReadHistogramResponse_Bucket) String() string { return proto.CompactTextString(m) }
+func (*ReadHistogramResponse_Bucket) ProtoMessage()    {}
+func (*ReadHistogramResponse_Bucket) Descriptor() ([]byte, []int) {
+	return fileDescriptor_egress_a5020915a711fd32, []int{5, 1}
+}
+func (m *ReadHistogramResponse_Bucket) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ReadHistogramResponse_Bucket.Unmarshal(m, b)
+}
+func (m *ReadHistogramResponse_Bucket) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ReadHistogramResponse_Bucket.Marshal(b, m, deterministic)
+}
+func (dst *ReadHistogramResponse_Bucket) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ReadHistogramResponse_Bucket.Merge(dst, src)
+}
+func (m *ReadHistogramResponse_Bucket) XXX_Size() int {
+	return xxx_messageInfo_ReadHistogramResponse_Bucket.Size(m)
+}
+func (m *ReadHistogramResponse_Bucket) XXX_DiscardUnknown() {
+	xxx_messageInfo_ReadHistogramResponse_Bucket.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ReadHistogramResponse_Bucket proto.InternalMessageInfo
+
+func (m *ReadHistogramResponse_Bucket) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *ReadHistogramResponse_Bucket) GetCounts() []*ReadHistogramResponse_Count {
+	if m != nil {
+		return m.Counts
+	}
+	return nil
+}
+
+type TailRequest struct {
+	SourceId             string         `protobuf:"bytes,1,opt,name=source_id,json=sourceId,proto3" json:"source_id,omitempty"`
+	EnvelopeTypes        []EnvelopeType `protobuf:"varint,2,rep,packed,name=envelope_types,json=envelopeTypes,proto3,enum=logcache.v1.EnvelopeType" json:"envelope_types,omitempty"`
+	NameFilter           string         `protobuf:"bytes,3,opt,name=name_filter,json=nameFilter,proto3" json:"name_filter,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *TailRequest) Reset()         { *m = TailRequest{} }
+func (m *TailRequest) String() string { return proto.CompactTextString(m) }
+func (*TailRequest) ProtoMessage()    {}
+func (*TailRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_egress_a5020915a711fd32, []int{6}
+}
+func (m *TailRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TailRequest.Unmarshal(m, b)
+}
+func (m *TailRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TailRequest.Marshal(b, m, deterministic)
+}
+func (dst *TailRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TailRequest.Merge(dst, src)
+}
+func (m *TailRequest) XXX_Size() int {
+	return xxx_messageInfo_TailRequest.Size(m)
+}
+func (m *TailRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TailRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TailRequest proto.InternalMessageInfo
+
+func (m *TailRequest) GetSourceId() string {
+	if m != nil {
+		return m.SourceId
+	}
+	return ""
+}
+
+func (m *TailRequest) GetEnvelopeTypes() []EnvelopeType {
+	if m != nil {
+		return m.EnvelopeTypes
+	}
+	return nil
+}
+
+func (m *TailRequest) GetNameFilter() string {
+	if m != nil {
+		return m.NameFilter
+	}
+	return ""
+}
+
+type TailResponse struct {
+	Envelopes            *loggregator_v2.EnvelopeBatch `protobuf:"bytes,1,opt,name=envelopes,proto3" json:"envelopes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                      `json:"-"`
+	XXX_unrecognized     []byte                        `json:"-"`
+	XXX_sizecache        int32                         `json:"-"`
+}
+
+func (m *TailResponse) Reset()         { *m = TailResponse{} }
+func (m *TailResponse) String() string { return proto.CompactTextString(m) }
+func (*TailResponse) ProtoMessage()    {}
+func (*TailResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_egress_a5020915a711fd32, []int{7}
+}
+func (m *TailResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_TailResponse.Unmarshal(m, b)
+}
+func (m *TailResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_TailResponse.Marshal(b, m, deterministic)
+}
+func (dst *TailResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TailResponse.Merge(dst, src)
+}
+func (m *TailResponse) XXX_Size() int {
+	return xxx_messageInfo_TailResponse.Size(m)
+}
+func (m *TailResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TailResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TailResponse proto.InternalMessageInfo
+
+func (m *TailResponse) GetEnvelopes() *loggregator_v2.EnvelopeBatch {
+	if m != nil {
+		return m.Envelopes
+	}
+	return nil
+}
+
 type MetaRequest struct {
-	LocalOnly            bool     `protobuf:"varint,1,opt,name=local_only,json=localOnly,proto3" json:"local_only,omitempty"`
+	LocalOnly bool `protobuf:"varint,1,opt,name=local_only,json=localOnly,proto3" json:"local_only,omitempty"`
+	// source_id_prefix and source_id_regex only return the source IDs that
+	// start with the prefix and match the RE2 regular expression.
+	SourceIdPrefix string `protobuf:"bytes,2,opt,name=source_id_prefix,json=sourceIdPrefix,proto3" json:"source_id_prefix,omitempty"`
+	SourceIdRegex  string `protobuf:"bytes,3,opt,name=source_id_regex,json=sourceIdRegex,proto3" json:"source_id_regex,omitempty"`
+	// sort_by and descending order the source IDs before the limit is
+	// applied. Source IDs with equal values are ordered by source ID.
+	SortBy     MetaSortBy `protobuf:"varint,4,opt,name=sort_by,json=sortBy,proto3,enum=logcache.v1.MetaSortBy" json:"sort_by,omitempty"`
+	Descending bool       `protobuf:"varint,5,opt,name=descending,proto3" json:"descending,omitempty"`
+	// limit is the maximum number of source IDs to return. It defaults to
+	// every source ID.
+	Limit int64 `protobuf:"varint,6,opt,name=limit,proto3" json:"limit,omitempty"`
+	// page_token is the next_page_token of a previous response. Only the
+	// source IDs that come after the previous page are returned. The page
+	// token is only valid with the same sort_by and descending.
+	PageToken            string   `protobuf:"bytes,7,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -195,7 +849,7 @@ func (m *MetaRequest) Reset()         { *m = MetaRequest{} }
 func (m *MetaRequest) String() string { return proto.CompactTextString(m) }
 func (*MetaRequest) ProtoMessage()    {}
 func (*MetaRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_egress_39930eba197832be, []int{2}
+	return fileDescriptor_egress_a5020915a711fd32, []int{8}
 }
 func (m *MetaRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_MetaRequest.Unmarshal(m, b)
@@ -222,18 +876,63 @@ func (m *MetaRequest) GetLocalOnly() bool {
 	return false
 }
 
+func (m *MetaRequest) GetSourceIdPrefix() string {
+	if m != nil {
+		return m.SourceIdPrefix
+	}
+	return ""
+}
+
+func (m *MetaRequest) GetSourceIdRegex() string {
+	if m != nil {
+		return m.SourceIdRegex
+	}
+	return ""
+}
+
+func (m *MetaRequest) GetSortBy() MetaSortBy {
+	if m != nil {
+		return m.SortBy
+	}
+	return MetaSortBy_SOURCE_ID
+}
+
+func (m *MetaRequest) GetDescending() bool {
+	if m != nil {
+		return m.Descending
+	}
+	return false
+}
+
+func (m *MetaRequest) GetLimit() int64 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+func (m *MetaRequest) GetPageToken() string {
+	if m != nil {
+		return m.PageToken
+	}
+	return ""
+}
+
 type MetaResponse struct {
-	Meta                 map[string]*MetaInfo `protobuf:"bytes,1,rep,name=meta,proto3" json:"meta,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
-	XXX_unrecognized     []byte               `json:"-"`
-	XXX_sizecache        int32                `json:"-"`
+	Meta map[string]*MetaInfo `protobuf:"bytes,1,rep,name=meta,proto3" json:"meta,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// next_page_token is set when the limit left out source IDs. It can be
+	// passed as the page_token of the next request.
+	NextPageToken        string   `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *MetaResponse) Reset()         { *m = MetaResponse{} }
 func (m *MetaResponse) String() string { return proto.CompactTextString(m) }
 func (*MetaResponse) ProtoMessage()    {}
 func (*MetaResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_egress_39930eba197832be, []int{3}
+	return fileDescriptor_egress_a5020915a711fd32, []int{9}
 }
 func (m *MetaResponse) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_MetaResponse.Unmarshal(m, b)
@@ -260,11 +959,28 @@ func (m *MetaResponse) GetMeta() map[string]*MetaInfo {
 	return nil
 }
 
+func (m *MetaResponse) GetNextPageToken() string {
+	if m != nil {
+		return m.NextPageToken
+	}
+	return ""
+}
+
 type MetaInfo struct {
-	Count                int64    `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
-	Expired              int64    `protobuf:"varint,2,opt,name=expired,proto3" json:"expired,omitempty"`
-	OldestTimestamp      int64    `protobuf:"varint,3,opt,name=oldest_timestamp,json=oldestTimestamp,proto3" json:"oldest_timestamp,omitempty"`
-	NewestTimestamp      int64    `protobuf:"varint,4,opt,name=newest_timestamp,json=newestTimestamp,proto3" json:"newest_timestamp,omitempty"`
+	Count           int64 `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	Expired         int64 `protobuf:"varint,2,opt,name=expired,proto3" json:"expired,omitempty"`
+	OldestTimestamp int64 `protobuf:"varint,3,opt,name=oldest_timestamp,json=oldestTimestamp,proto3" json:"oldest_timestamp,omitempty"`
+	NewestTimestamp int64 `protobuf:"varint,4,opt,name=newest_timestamp,json=newestTimestamp,proto3" json:"newest_timestamp,omitempty"`
+	// The number of stored envelopes of each type.
+	LogCount     int64 `protobuf:"varint,5,opt,name=log_count,json=logCount,proto3" json:"log_count,omitempty"`
+	CounterCount int64 `protobuf:"varint,6,opt,name=counter_count,json=counterCount,proto3" json:"counter_count,omitempty"`
+	GaugeCount   int64 `protobuf:"varint,7,opt,name=gauge_count,json=gaugeCount,proto3" json:"gauge_count,omitempty"`
+	TimerCount   int64 `protobuf:"varint,8,opt,name=timer_count,json=timerCount,proto3" json:"timer_count,omitempty"`
+	EventCount   int64 `protobuf:"varint,9,opt,name=event_count,json=eventCount,proto3" json:"event_count,omitempty"`
+	// The approximate number of bytes the stored envelopes occupy.
+	Bytes int64 `protobuf:"varint,10,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	// The number of envelopes per second written over the last minute.
+	Rate                 float64  `protobuf:"fixed64,11,opt,name=rate,proto3" json:"rate,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -274,7 +990,7 @@ func (m *MetaInfo) Reset()         { *m = MetaInfo{} }
 func (m *MetaInfo) String() string { return proto.CompactTextString(m) }
 func (*MetaInfo) ProtoMessage()    {}
 func (*MetaInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_egress_39930eba197832be, []int{4}
+	return fileDescriptor_egress_a5020915a711fd32, []int{10}
 }
 func (m *MetaInfo) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_MetaInfo.Unmarshal(m, b)
@@ -322,14 +1038,75 @@ func (m *MetaInfo) GetNewestTimestamp() int64 {
 	return 0
 }
 
+func (m *MetaInfo) GetLogCount() int64 {
+	if m != nil {
+		return m.LogCount
+	}
+	return 0
+}
+
+func (m *MetaInfo) GetCounterCount() int64 {
+	if m != nil {
+		return m.CounterCount
+	}
+	return 0
+}
+
+func (m *MetaInfo) GetGaugeCount() int64 {
+	if m != nil {
+		return m.GaugeCount
+	}
+	return 0
+}
+
+func (m *MetaInfo) GetTimerCount() int64 {
+	if m != nil {
+		return m.TimerCount
+	}
+	return 0
+}
+
+func (m *MetaInfo) GetEventCount() int64 {
+	if m != nil {
+		return m.EventCount
+	}
+	return 0
+}
+
+func (m *MetaInfo) GetBytes() int64 {
+	if m != nil {
+		return m.Bytes
+	}
+	return 0
+}
+
+func (m *MetaInfo) GetRate() float64 {
+	if m != nil {
+		return m.Rate
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*ReadRequest)(nil), "logcache.v1.ReadRequest")
 	proto.RegisterType((*ReadResponse)(nil), "logcache.v1.ReadResponse")
+	proto.RegisterType((*ReadAggregateRequest)(nil), "logcache.v1.ReadAggregateRequest")
+	proto.RegisterType((*ReadAggregateResponse)(nil), "logcache.v1.ReadAggregateResponse")
+	proto.RegisterType((*ReadAggregateResponse_Point)(nil), "logcache.v1.ReadAggregateResponse.Point")
+	proto.RegisterType((*ReadHistogramRequest)(nil), "logcache.v1.ReadHistogramRequest")
+	proto.RegisterType((*ReadHistogramResponse)(nil), "logcache.v1.ReadHistogramResponse")
+	proto.RegisterType((*ReadHistogramResponse_Count)(nil), "logcache.v1.ReadHistogramResponse.Count")
+	proto.RegisterType((*ReadHistogramResponse_Bucket)(nil), "logcache.v1.ReadHistogramResponse.Bucket")
+	proto.RegisterType((*TailRequest)(nil), "logcache.v1.TailRequest")
+	proto.RegisterType((*TailResponse)(nil), "logcache.v1.TailResponse")
 	proto.RegisterType((*MetaRequest)(nil), "logcache.v1.MetaRequest")
 	proto.RegisterType((*MetaResponse)(nil), "logcache.v1.MetaResponse")
 	proto.RegisterMapType((map[string]*MetaInfo)(nil), "logcache.v1.MetaResponse.MetaEntry")
 	proto.RegisterType((*MetaInfo)(nil), "logcache.v1.MetaInfo")
 	proto.RegisterEnum("logcache.v1.EnvelopeType", EnvelopeType_name, EnvelopeType_value)
+	proto.RegisterEnum("logcache.v1.MetaSortBy", MetaSortBy_name, MetaSortBy_value)
+	proto.RegisterEnum("logcache.v1.ReadAggregateRequest_Aggregation", ReadAggregateRequest_Aggregation_name, ReadAggregateRequest_Aggregation_value)
+	proto.RegisterEnum("logcache.v1.ReadHistogramRequest_GroupBy", ReadHistogramRequest_GroupBy_name, ReadHistogramRequest_GroupBy_value)
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -346,6 +1123,15 @@ const _ = grpc.SupportPackageIsVersion4
 type EgressClient interface {
 	Read(ctx context.Context, in *ReadRequest, opts ...grpc.CallOption) (*ReadResponse, error)
 	Meta(ctx context.Context, in *MetaRequest, opts ...grpc.CallOption) (*MetaResponse, error)
+	// Tail streams envelopes for a source ID as they arrive. It does not
+	// return any envelopes that were stored before the stream was opened.
+	Tail(ctx context.Context, in *TailRequest, opts ...grpc.CallOption) (Egress_TailClient, error)
+	// ReadAggregate downsamples the values of a metric of a source ID into
+	// buckets of a fixed step.
+	ReadAggregate(ctx context.Context, in *ReadAggregateRequest, opts ...grpc.CallOption) (*ReadAggregateResponse, error)
+	// ReadHistogram counts the envelopes of a source ID in buckets of a
+	// fixed step, split by envelope type.
+	ReadHistogram(ctx context.Context, in *ReadHistogramRequest, opts ...grpc.CallOption) (*ReadHistogramResponse, error)
 }
 
 type egressClient struct {
@@ -374,10 +1160,69 @@ func (c *egressClient) Meta(ctx context.Context, in *MetaRequest, opts ...grpc.C
 	return out, nil
 }
 
+func (c *egressClient) Tail(ctx context.Context, in *TailRequest, opts ...grpc.CallOption) (Egress_TailClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Egress_serviceDesc.Streams[0], "/logcache.v1.Egress/Tail", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &egressTailClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Egress_TailClient interface {
+	Recv() (*TailResponse, error)
+	grpc.ClientStream
+}
+
+type egressTailClient struct {
+	grpc.ClientStream
+}
+
+func (x *egressTailClient) Recv() (*TailResponse, error) {
+	m := new(TailResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *egressClient) ReadAggregate(ctx context.Context, in *ReadAggregateRequest, opts ...grpc.CallOption) (*ReadAggregateResponse, error) {
+	out := new(ReadAggregateResponse)
+	err := c.cc.Invoke(ctx, "/logcache.v1.Egress/ReadAggregate", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *egressClient) ReadHistogram(ctx context.Context, in *ReadHistogramRequest, opts ...grpc.CallOption) (*ReadHistogramResponse, error) {
+	out := new(ReadHistogramResponse)
+	err := c.cc.Invoke(ctx, "/logcache.v1.Egress/ReadHistogram", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // EgressServer is the server API for Egress service.
 type EgressServer interface {
 	Read(context.Context, *ReadRequest) (*ReadResponse, error)
 	Meta(context.Context, *MetaRequest) (*MetaResponse, error)
+	// Tail streams envelopes for a source ID as they arrive. It does not
+	// return any envelopes that were stored before the stream was opened.
+	Tail(*TailRequest, Egress_TailServer) error
+	// ReadAggregate downsamples the values of a metric of a source ID into
+	// buckets of a fixed step.
+	ReadAggregate(context.Context, *ReadAggregateRequest) (*ReadAggregateResponse, error)
+	// ReadHistogram counts the envelopes of a source ID in buckets of a
+	// fixed step, split by envelope type.
+	ReadHistogram(context.Context, *ReadHistogramRequest) (*ReadHistogramResponse, error)
 }
 
 func RegisterEgressServer(s *grpc.Server, srv EgressServer) {
@@ -420,6 +1265,63 @@ func _Egress_Meta_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Egress_Tail_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TailRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EgressServer).Tail(m, &egressTailServer{stream})
+}
+
+type Egress_TailServer interface {
+	Send(*TailResponse) error
+	grpc.ServerStream
+}
+
+type egressTailServer struct {
+	grpc.ServerStream
+}
+
+func (x *egressTailServer) Send(m *TailResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Egress_ReadAggregate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadAggregateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EgressServer).ReadAggregate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/logcache.v1.Egress/ReadAggregate",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EgressServer).ReadAggregate(ctx, req.(*ReadAggregateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Egress_ReadHistogram_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadHistogramRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EgressServer).ReadHistogram(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/logcache.v1.Egress/ReadHistogram",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EgressServer).ReadHistogram(ctx, req.(*ReadHistogramRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Egress_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "logcache.v1.Egress",
 	HandlerType: (*EgressServer)(nil),
@@ -432,52 +1334,115 @@ var _Egress_serviceDesc = grpc.ServiceDesc{
 			MethodName: "Meta",
 			Handler:    _Egress_Meta_Handler,
 		},
+		{
+			MethodName: "ReadAggregate",
+			Handler:    _Egress_ReadAggregate_Handler,
+		},
+		{
+			MethodName: "ReadHistogram",
+			Handler:    _Egress_ReadHistogram_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Tail",
+			Handler:       _Egress_Tail_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "egress.proto",
 }
 
-func init() { proto.RegisterFile("egress.proto", fileDescriptor_egress_39930eba197832be) }
-
-var fileDescriptor_egress_39930eba197832be = []byte{
-	// 613 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x53, 0xcf, 0x6e, 0xd3, 0x4e,
-	0x10, 0xfe, 0x39, 0x4e, 0x9a, 0x78, 0x9c, 0x5f, 0x6b, 0x56, 0x45, 0x72, 0x53, 0x02, 0x91, 0x7b,
-	0x09, 0x05, 0x25, 0xaa, 0x39, 0x80, 0x40, 0x48, 0x14, 0x64, 0xaa, 0x0a, 0x9a, 0x0a, 0x2b, 0x45,
-	0xe2, 0x14, 0x16, 0x7b, 0xea, 0x5a, 0x38, 0xbb, 0xc6, 0xde, 0x04, 0x22, 0xc4, 0x85, 0x27, 0x40,
-	0xe2, 0xc0, 0xd3, 0xf0, 0x14, 0xbc, 0x02, 0xaf, 0x81, 0x84, 0xbc, 0x1b, 0x07, 0xa7, 0x70, 0x9b,
-	0xf9, 0xe6, 0xdb, 0x9d, 0x7f, 0xdf, 0x40, 0x1b, 0xa3, 0x0c, 0xf3, 0x7c, 0x90, 0x66, 0x5c, 0x70,
-	0x62, 0x26, 0x3c, 0x0a, 0x68, 0x70, 0x81, 0x83, 0xf9, 0x41, 0xe7, 0xca, 0xdc, 0x1d, 0x22, 0x9b,
-	0x63, 0xc2, 0x53, 0x54, 0xf1, 0xce, 0xb5, 0x88, 0xf3, 0x28, 0xc1, 0x21, 0x4d, 0xe3, 0x21, 0x65,
-	0x8c, 0x0b, 0x2a, 0x62, 0xce, 0x96, 0xaf, 0x9d, 0x5f, 0x1a, 0x98, 0x3e, 0xd2, 0xd0, 0xc7, 0x77,
-	0x33, 0xcc, 0x05, 0xd9, 0x05, 0x23, 0xe7, 0xb3, 0x2c, 0xc0, 0x49, 0x1c, 0xda, 0x5a, 0x4f, 0xeb,
-	0x1b, 0x7e, 0x4b, 0x01, 0xc7, 0x21, 0xe9, 0x02, 0xe4, 0x82, 0x66, 0x62, 0x22, 0xe2, 0x29, 0xda,
-	0xb5, 0x9e, 0xd6, 0xd7, 0x7d, 0x43, 0x22, 0xe3, 0x78, 0x8a, 0x64, 0x07, 0x5a, 0xc8, 0x42, 0x15,
-	0xd4, 0x65, 0xb0, 0x89, 0x2c, 0x94, 0xa1, 0x6d, 0x68, 0x24, 0xf1, 0x34, 0x16, 0x76, 0x5d, 0xe2,
-	0xca, 0x21, 0x8f, 0x60, 0xb3, 0x2c, 0x76, 0x22, 0x16, 0x29, 0xe6, 0x76, 0xa3, 0xa7, 0xf7, 0x37,
-	0xdd, 0x9d, 0x41, 0xa5, 0xa7, 0x81, 0xb7, 0xa4, 0x8c, 0x17, 0x29, 0xfa, 0xff, 0x63, 0xc5, 0xcb,
-	0xc9, 0x75, 0x80, 0x10, 0xf3, 0x00, 0x59, 0x18, 0xb3, 0xc8, 0xde, 0xe8, 0x69, 0xfd, 0x96, 0x5f,
-	0x41, 0xc8, 0x0d, 0x30, 0x19, 0x9d, 0xe2, 0xe4, 0x3c, 0x4e, 0x04, 0x66, 0x76, 0x53, 0x36, 0x04,
-	0x05, 0xf4, 0x54, 0x22, 0xce, 0x33, 0x68, 0xab, 0xf6, 0xf3, 0x94, 0xb3, 0x1c, 0xc9, 0x03, 0x30,
-	0xca, 0x0c, 0xb9, 0xec, 0xdf, 0x74, 0xbb, 0x45, 0x35, 0x51, 0x86, 0x11, 0x15, 0x3c, 0x1b, 0xcc,
-	0xdd, 0x55, 0x41, 0x8f, 0xa9, 0x08, 0x2e, 0xfc, 0x3f, 0x7c, 0xe7, 0x36, 0x98, 0x27, 0x28, 0x68,
-	0x39, 0xcb, 0x2e, 0x40, 0xc2, 0x03, 0x9a, 0x4c, 0x38, 0x4b, 0x16, 0xf2, 0xb3, 0x96, 0x6f, 0x48,
-	0xe4, 0x94, 0x25, 0x0b, 0xe7, 0x9b, 0x06, 0x6d, 0x45, 0x5f, 0xe6, 0xbe, 0x0b, 0xf5, 0x29, 0x0a,
-	0x6a, 0x6b, 0x3d, 0xbd, 0x6f, 0xba, 0x7b, 0x6b, 0x43, 0xa8, 0x12, 0xa5, 0xe3, 0x31, 0x91, 0x2d,
-	0x7c, 0xf9, 0xa0, 0x33, 0x02, 0x63, 0x05, 0x11, 0x0b, 0xf4, 0xb7, 0xb8, 0x58, 0xee, 0xae, 0x30,
-	0xc9, 0x2d, 0x68, 0xcc, 0x69, 0x32, 0x53, 0x1b, 0x33, 0xdd, 0xab, 0x7f, 0x7d, 0x7c, 0xcc, 0xce,
-	0xb9, 0xaf, 0x38, 0xf7, 0x6b, 0xf7, 0x34, 0xe7, 0x8b, 0x06, 0xad, 0x12, 0x2f, 0x56, 0x17, 0xf0,
-	0x19, 0x13, 0xf2, 0x47, 0xdd, 0x57, 0x0e, 0xb1, 0xa1, 0x89, 0x1f, 0xd2, 0x38, 0xc3, 0x70, 0xa9,
-	0x83, 0xd2, 0x25, 0x37, 0xc1, 0xe2, 0x49, 0x88, 0xb9, 0x52, 0x49, 0x2e, 0xe8, 0x34, 0x5d, 0xaa,
-	0x61, 0x4b, 0xe1, 0xe3, 0x12, 0x2e, 0xa8, 0x0c, 0xdf, 0xaf, 0x53, 0x95, 0x40, 0xb6, 0x14, 0xbe,
-	0xa2, 0xee, 0x8f, 0xa0, 0x5d, 0xd5, 0x01, 0x69, 0x82, 0x7e, 0x38, 0x7a, 0x65, 0xfd, 0x57, 0x18,
-	0xcf, 0x4f, 0x8f, 0x2c, 0x8d, 0x98, 0xd0, 0x7c, 0x72, 0x7a, 0x36, 0x1a, 0x7b, 0xbe, 0x55, 0x23,
-	0x06, 0x34, 0x8e, 0x0e, 0xcf, 0x8e, 0x3c, 0x4b, 0x2f, 0xcc, 0xf1, 0xf1, 0x89, 0xe7, 0x5b, 0xf5,
-	0xc2, 0xf4, 0x5e, 0x7a, 0xa3, 0xb1, 0xd5, 0x70, 0xbf, 0x6b, 0xb0, 0xe1, 0xc9, 0x33, 0x22, 0xaf,
-	0xa1, 0x5e, 0x48, 0x80, 0xd8, 0x6b, 0x73, 0xa9, 0x1c, 0x45, 0x67, 0xe7, 0x1f, 0x11, 0xb5, 0x0a,
-	0x67, 0xef, 0xf3, 0x8f, 0x9f, 0x5f, 0x6b, 0x5d, 0xb2, 0x2b, 0xef, 0x6b, 0x7e, 0x30, 0xcc, 0x90,
-	0x86, 0xc3, 0x8f, 0xab, 0x1b, 0x7a, 0xb8, 0xbf, 0xff, 0x89, 0xbc, 0x80, 0x7a, 0x31, 0xce, 0x4b,
-	0x19, 0x2a, 0x52, 0xb9, 0x94, 0xa1, 0xba, 0x6c, 0x67, 0x5b, 0x66, 0xd8, 0x24, 0xed, 0x32, 0x43,
-	0xb1, 0xf2, 0x37, 0x1b, 0xf2, 0x7c, 0xef, 0xfc, 0x0e, 0x00, 0x00, 0xff, 0xff, 0x79, 0x93, 0x8f,
-	0x97, 0x0c, 0x04, 0x00, 0x00,
+func init() { proto.RegisterFile("egress.proto", fileDescriptor_egress_a5020915a711fd32) }
+
+var fileDescriptor_egress_a5020915a711fd32 = []byte{
+	// 1398 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x57, 0xcf, 0x8e, 0xdb, 0x44,
+	0x18, 0xaf, 0x9d, 0xbf, 0xfe, 0x9c, 0xec, 0x9a, 0xd1, 0x56, 0x78, 0xd3, 0x2e, 0xdd, 0xba, 0x02,
+	0xa5, 0x8b, 0xc8, 0xb6, 0xe1, 0x00, 0xa2, 0x02, 0x35, 0xbb, 0x75, 0x97, 0x88, 0x6e, 0x76, 0x71,
+	0xb2, 0xa5, 0x3d, 0x99, 0xd9, 0x64, 0xea, 0xb5, 0xea, 0xd8, 0xc6, 0x9e, 0x84, 0x8d, 0x10, 0x52,
+	0x85, 0xc4, 0x91, 0x13, 0x12, 0xaf, 0xc0, 0x8d, 0x17, 0xe0, 0xc0, 0x43, 0xf0, 0x00, 0x5c, 0x78,
+	0x0b, 0x2e, 0x68, 0x66, 0xec, 0xc4, 0x71, 0xb6, 0xdb, 0x22, 0xd1, 0x53, 0xc6, 0xbf, 0xf9, 0x7d,
+	0xf3, 0x7d, 0xf3, 0xfd, 0x9d, 0x40, 0x8d, 0x38, 0x11, 0x89, 0xe3, 0x56, 0x18, 0x05, 0x34, 0x40,
+	0xaa, 0x17, 0x38, 0x43, 0x3c, 0x3c, 0x23, 0xad, 0xe9, 0xdd, 0xc6, 0x5b, 0xd3, 0xf6, 0x2e, 0xf1,
+	0xa7, 0xc4, 0x0b, 0x42, 0x22, 0xf6, 0x1b, 0xd7, 0x9d, 0x20, 0x70, 0x3c, 0xb2, 0x8b, 0x43, 0x77,
+	0x17, 0xfb, 0x7e, 0x40, 0x31, 0x75, 0x03, 0x3f, 0x91, 0x36, 0x5e, 0x14, 0x41, 0xb5, 0x08, 0x1e,
+	0x59, 0xe4, 0x9b, 0x09, 0x89, 0x29, 0xba, 0x06, 0x4a, 0x1c, 0x4c, 0xa2, 0x21, 0xb1, 0xdd, 0x91,
+	0x2e, 0x6d, 0x4b, 0x4d, 0xc5, 0xaa, 0x0a, 0xa0, 0x3b, 0x42, 0x5b, 0x00, 0x31, 0xc5, 0x11, 0xb5,
+	0xa9, 0x3b, 0x26, 0xba, 0xbc, 0x2d, 0x35, 0x0b, 0x96, 0xc2, 0x91, 0x81, 0x3b, 0x26, 0x68, 0x13,
+	0xaa, 0xc4, 0x1f, 0x89, 0xcd, 0x02, 0xdf, 0xac, 0x10, 0x7f, 0xc4, 0xb7, 0x36, 0xa0, 0xe4, 0xb9,
+	0x63, 0x97, 0xea, 0x45, 0x8e, 0x8b, 0x0f, 0x74, 0x1f, 0xd6, 0x52, 0x63, 0x6d, 0x3a, 0x0b, 0x49,
+	0xac, 0x97, 0xb6, 0x0b, 0xcd, 0xb5, 0xf6, 0x66, 0x2b, 0x73, 0xa7, 0x96, 0x99, 0x50, 0x06, 0xb3,
+	0x90, 0x58, 0x75, 0x92, 0xf9, 0x8a, 0xd1, 0x3b, 0x00, 0x23, 0x12, 0x0f, 0x89, 0x3f, 0x72, 0x7d,
+	0x47, 0x2f, 0x6f, 0x4b, 0xcd, 0xaa, 0x95, 0x41, 0xd0, 0x0d, 0x50, 0x7d, 0x3c, 0x26, 0xf6, 0x33,
+	0xd7, 0xa3, 0x24, 0xd2, 0x2b, 0xfc, 0x42, 0xc0, 0xa0, 0x87, 0x1c, 0x41, 0xef, 0xc2, 0x5a, 0x88,
+	0x67, 0x5e, 0x80, 0x47, 0x29, 0xa7, 0xca, 0x39, 0xf5, 0x04, 0x4d, 0x68, 0x77, 0x60, 0x63, 0x99,
+	0x66, 0x47, 0xc4, 0x21, 0xe7, 0xba, 0xc2, 0x35, 0xa2, 0x25, 0xb2, 0xc5, 0x76, 0x90, 0x09, 0x37,
+	0x72, 0x12, 0x43, 0x1c, 0x13, 0xdb, 0xf5, 0x63, 0xe2, 0xc7, 0x2e, 0x75, 0xa7, 0x44, 0x07, 0x2e,
+	0x7c, 0x7d, 0x49, 0x78, 0x1f, 0xc7, 0xa4, 0xbb, 0xe0, 0xa0, 0x9b, 0x50, 0xa3, 0xd8, 0xb1, 0xc7,
+	0x98, 0x0e, 0xcf, 0x48, 0x14, 0xeb, 0xea, 0x76, 0xa1, 0xa9, 0x58, 0x2a, 0xc5, 0xce, 0x61, 0x02,
+	0xb1, 0x3b, 0xba, 0x7e, 0x4c, 0xb1, 0x2f, 0x82, 0x56, 0x13, 0x77, 0x4c, 0x21, 0x11, 0xb6, 0x10,
+	0x3b, 0xc4, 0xa6, 0xc1, 0x73, 0xe2, 0xeb, 0x75, 0xbe, 0xaf, 0x30, 0x64, 0xc0, 0x00, 0x23, 0x86,
+	0x9a, 0xc8, 0x80, 0x38, 0x0c, 0xfc, 0x98, 0xa0, 0x7b, 0xa0, 0xa4, 0x4e, 0x8e, 0x79, 0x0a, 0xa8,
+	0xed, 0x2d, 0x16, 0x10, 0x27, 0x22, 0x0e, 0xa6, 0x41, 0xd4, 0x9a, 0xb6, 0xe7, 0x31, 0xd9, 0x63,
+	0x46, 0x58, 0x0b, 0x3e, 0x7a, 0x0f, 0xd6, 0x7d, 0x72, 0x4e, 0xed, 0x8c, 0x42, 0x59, 0x38, 0x94,
+	0xc1, 0xc7, 0x73, 0xa5, 0xbf, 0xc9, 0xb0, 0xc1, 0xb4, 0x76, 0x92, 0x63, 0xc9, 0x1b, 0x4e, 0x40,
+	0x04, 0x45, 0x16, 0x75, 0x9e, 0x7f, 0x8a, 0xc5, 0xd7, 0x0c, 0x8b, 0x29, 0x09, 0xf5, 0x12, 0xa7,
+	0xf2, 0x35, 0x3a, 0x02, 0x15, 0x27, 0x26, 0xb9, 0x81, 0xcf, 0x33, 0x6a, 0xad, 0xfd, 0xc1, 0x52,
+	0x3e, 0x5e, 0x64, 0x76, 0xab, 0xb3, 0x10, 0xb2, 0xb2, 0x27, 0x18, 0x0f, 0x41, 0xcd, 0xec, 0xa1,
+	0x2a, 0x14, 0x1f, 0x75, 0xfa, 0x03, 0xed, 0x0a, 0xaa, 0x40, 0xe1, 0xb0, 0xdb, 0xd3, 0x24, 0xbe,
+	0xe8, 0x3c, 0xd1, 0x64, 0xb6, 0xe8, 0x3c, 0x3e, 0xd0, 0x0a, 0x6c, 0xd1, 0x3f, 0x39, 0xd4, 0x8a,
+	0x48, 0x81, 0xd2, 0xfe, 0xd1, 0x49, 0x6f, 0xa0, 0x95, 0x8c, 0x5f, 0x24, 0xb8, 0x9a, 0xd3, 0x9c,
+	0xc4, 0xeb, 0x3e, 0x94, 0xc3, 0xc0, 0xf5, 0x29, 0x0b, 0x56, 0xa1, 0xa9, 0xb6, 0x9b, 0x97, 0x59,
+	0x2b, 0x64, 0x5a, 0xc7, 0x4c, 0xc0, 0x4a, 0xe4, 0x1a, 0xf7, 0xa0, 0xc4, 0x01, 0x74, 0x1d, 0x14,
+	0xe6, 0xbc, 0x98, 0xe2, 0x71, 0xc8, 0x9d, 0x5f, 0xb0, 0x16, 0x00, 0x2b, 0xe2, 0x29, 0xf6, 0x26,
+	0xc2, 0xf1, 0x92, 0x25, 0x3e, 0x8c, 0x7f, 0x24, 0x11, 0xc9, 0xcf, 0xdd, 0x98, 0x06, 0x4e, 0x84,
+	0xc7, 0x6f, 0x3e, 0x92, 0x3c, 0x6a, 0xc5, 0x4c, 0xd4, 0x1e, 0x40, 0xd5, 0x89, 0x82, 0x49, 0x68,
+	0x9f, 0xce, 0x78, 0x34, 0xd7, 0xda, 0xb7, 0x57, 0x9c, 0x90, 0xb7, 0xaf, 0x75, 0xc0, 0x24, 0xf6,
+	0x66, 0x56, 0xc5, 0x11, 0x0b, 0xa3, 0x0d, 0x95, 0x04, 0x63, 0x61, 0xea, 0x1d, 0xf5, 0x4c, 0xed,
+	0x0a, 0xaa, 0x41, 0xf5, 0xd1, 0xd1, 0x81, 0x3d, 0x78, 0x7a, 0x6c, 0x6a, 0x12, 0x5a, 0x07, 0xb5,
+	0xdb, 0xeb, 0x0f, 0x3a, 0xbd, 0x7d, 0xd3, 0xee, 0x3e, 0xd0, 0x64, 0xe3, 0x77, 0x19, 0xae, 0xe6,
+	0x4e, 0x4f, 0xc2, 0xb2, 0x0f, 0x95, 0xd3, 0xc9, 0xf0, 0x39, 0x99, 0xc7, 0xe5, 0x52, 0x93, 0x92,
+	0xb8, 0xec, 0x71, 0x09, 0x2b, 0x95, 0x6c, 0xc4, 0x50, 0xda, 0x0f, 0x26, 0x3e, 0x45, 0x9f, 0x41,
+	0x7d, 0xa9, 0x55, 0x72, 0x87, 0x5e, 0xda, 0x29, 0x6b, 0xd9, 0x4e, 0xc9, 0x62, 0xc7, 0xaf, 0x99,
+	0x54, 0xa3, 0xf8, 0x60, 0xe8, 0x90, 0x1d, 0x9f, 0xf8, 0x58, 0x7c, 0x34, 0xce, 0xa0, 0x2c, 0xec,
+	0x78, 0x45, 0x3e, 0xdc, 0x87, 0x32, 0x17, 0x88, 0x75, 0xf9, 0x25, 0x89, 0xb7, 0x7a, 0x41, 0x7e,
+	0x1b, 0x2b, 0x91, 0x33, 0x7e, 0x92, 0x40, 0x1d, 0x60, 0xd7, 0x7b, 0xad, 0x94, 0x59, 0x9d, 0x16,
+	0xf2, 0x7f, 0x9c, 0x16, 0xb9, 0x69, 0x50, 0xc8, 0x4f, 0x03, 0xe3, 0x0b, 0xa8, 0x09, 0x73, 0xfe,
+	0x87, 0x56, 0x68, 0xfc, 0x28, 0x83, 0x7a, 0x48, 0x28, 0x4e, 0x2f, 0xb7, 0x05, 0xe0, 0x05, 0x43,
+	0xec, 0xd9, 0x81, 0xef, 0xcd, 0xf8, 0x69, 0x55, 0x4b, 0xe1, 0xc8, 0x91, 0xef, 0xcd, 0x50, 0x13,
+	0xb4, 0xf9, 0xdd, 0xed, 0x30, 0x22, 0xcf, 0xdc, 0xf3, 0x24, 0x58, 0x6b, 0xa9, 0x0b, 0x8e, 0x39,
+	0xca, 0x7a, 0xec, 0x82, 0x29, 0xe6, 0x90, 0xb8, 0x4a, 0x3d, 0x25, 0x8a, 0x11, 0x74, 0x07, 0x2a,
+	0x71, 0x10, 0x51, 0x56, 0x14, 0x45, 0x9e, 0x2d, 0x6f, 0x2f, 0x79, 0x8a, 0xd9, 0xd6, 0x0f, 0x22,
+	0xba, 0x37, 0xb3, 0xca, 0x31, 0xff, 0xcd, 0x8d, 0xd3, 0xd2, 0xca, 0x38, 0x9d, 0x8f, 0xf1, 0x72,
+	0x76, 0x8c, 0x2f, 0xcf, 0x97, 0x4a, 0x7e, 0xbe, 0xfc, 0x21, 0x41, 0x4d, 0xf8, 0x21, 0xf1, 0xea,
+	0x47, 0x50, 0x1c, 0x13, 0x8a, 0x93, 0xb2, 0xb8, 0xb5, 0x62, 0xd4, 0x3c, 0x59, 0xd8, 0x87, 0xe9,
+	0xd3, 0x68, 0x66, 0x71, 0x81, 0xd7, 0x1d, 0x2e, 0x8d, 0x1e, 0x28, 0x73, 0x51, 0xa4, 0x41, 0xe1,
+	0x39, 0x99, 0x25, 0xd9, 0xc4, 0x96, 0xe8, 0xfd, 0x6c, 0x1f, 0x53, 0xdb, 0x57, 0x57, 0x0c, 0xe8,
+	0xfa, 0xcf, 0x82, 0xa4, 0xbd, 0x7d, 0x22, 0x7f, 0x2c, 0x19, 0x7f, 0xc9, 0x50, 0x4d, 0xf1, 0x45,
+	0xcd, 0x48, 0x99, 0x9a, 0x41, 0x3a, 0x54, 0xc8, 0x79, 0xe8, 0x46, 0x64, 0x94, 0x34, 0xb3, 0xf4,
+	0x13, 0xdd, 0x06, 0x2d, 0xf0, 0x46, 0x24, 0xa6, 0xf6, 0xbc, 0x72, 0x92, 0x72, 0x5b, 0x17, 0xf8,
+	0x20, 0x85, 0x19, 0xd5, 0x27, 0xdf, 0x2e, 0x53, 0x45, 0x9b, 0x5b, 0x17, 0xf8, 0x82, 0x7a, 0x0d,
+	0x14, 0x2f, 0x70, 0x6c, 0x61, 0x89, 0x18, 0x60, 0x55, 0x2f, 0x70, 0x44, 0xb3, 0xb8, 0x05, 0x75,
+	0xbe, 0xc1, 0x1e, 0x1d, 0x9c, 0x20, 0xc2, 0x55, 0x4b, 0x40, 0x41, 0xba, 0x01, 0xaa, 0x83, 0x27,
+	0x0e, 0x49, 0x28, 0x15, 0x4e, 0x01, 0x0e, 0xcd, 0x09, 0xcc, 0x8c, 0xf4, 0x8c, 0xaa, 0x20, 0x70,
+	0x68, 0x4e, 0x20, 0x53, 0xe2, 0xd3, 0x84, 0xa0, 0x08, 0x02, 0x87, 0x04, 0x61, 0x03, 0x4a, 0xa7,
+	0x33, 0x4a, 0x62, 0xfe, 0xd2, 0x29, 0x58, 0xe2, 0x83, 0x35, 0xf0, 0x08, 0x53, 0xa2, 0xab, 0x7c,
+	0x8a, 0xf0, 0xf5, 0x4e, 0x0f, 0x6a, 0xd9, 0xc2, 0xe5, 0xa3, 0xb0, 0xf7, 0x54, 0x4c, 0xc9, 0x47,
+	0x47, 0x07, 0x9a, 0x84, 0x54, 0xa8, 0xf0, 0x51, 0x68, 0x5a, 0x9a, 0xcc, 0xe6, 0xe2, 0x41, 0xe7,
+	0xe4, 0xc0, 0xd4, 0x0a, 0x6c, 0x39, 0xe8, 0x1e, 0x9a, 0x96, 0x98, 0x96, 0xe6, 0x63, 0x93, 0x4d,
+	0xcb, 0x9d, 0x2e, 0xc0, 0x22, 0xbd, 0x51, 0x1d, 0x94, 0xfe, 0xd1, 0x89, 0x25, 0x7a, 0xf6, 0x95,
+	0xc5, 0x54, 0x95, 0xd0, 0x06, 0x68, 0x3d, 0xf3, 0x2b, 0xb3, 0x3f, 0xb0, 0xd9, 0x21, 0xfd, 0x41,
+	0xe7, 0xf0, 0x58, 0x93, 0x99, 0x2e, 0xf3, 0xc9, 0x71, 0xd7, 0x32, 0x1f, 0x68, 0x85, 0xf6, 0xaf,
+	0x45, 0x28, 0x9b, 0xfc, 0xc1, 0x8d, 0xbe, 0x86, 0x22, 0xeb, 0x6a, 0x48, 0x5f, 0x69, 0x74, 0x49,
+	0x8d, 0x37, 0x36, 0x2f, 0xd8, 0x11, 0xc9, 0x6c, 0xdc, 0xfa, 0xe1, 0xcf, 0xbf, 0x7f, 0x96, 0xb7,
+	0xd0, 0x35, 0xfe, 0x12, 0x9f, 0xde, 0xdd, 0x8d, 0x08, 0x1e, 0xed, 0x7e, 0x37, 0xaf, 0xe4, 0x4f,
+	0x77, 0x76, 0xbe, 0x47, 0x5f, 0x42, 0x91, 0xd9, 0x9d, 0xd3, 0x90, 0xe9, 0x22, 0x8d, 0xcd, 0x0b,
+	0x76, 0x12, 0x0d, 0x1b, 0x5c, 0xc3, 0x1a, 0xaa, 0xa5, 0x1a, 0x78, 0xd1, 0x9c, 0x42, 0x91, 0xf5,
+	0xb4, 0xdc, 0x91, 0x99, 0xae, 0xdb, 0xd8, 0xbc, 0x60, 0xe7, 0x65, 0x46, 0x53, 0xec, 0x7a, 0x39,
+	0xa3, 0xef, 0x48, 0xe8, 0x85, 0x04, 0xf5, 0xa5, 0x87, 0x06, 0xba, 0xf9, 0xca, 0x27, 0x53, 0xc3,
+	0x78, 0xf5, 0x3b, 0xc5, 0x68, 0x72, 0xfd, 0x06, 0xda, 0x4e, 0xf5, 0xa7, 0x4f, 0x2b, 0x92, 0xf7,
+	0x5c, 0x6a, 0xc2, 0x7c, 0xe4, 0x5c, 0x60, 0x42, 0xfe, 0x09, 0xd0, 0x30, 0x2e, 0xa3, 0xbc, 0xcc,
+	0x84, 0xb3, 0x94, 0x92, 0x33, 0xe1, 0xb4, 0xcc, 0xff, 0x52, 0x7d, 0xf8, 0xef, 0x00, 0x0e, 0x32,
+	0x72, 0xaa, 0xa0, 0x0d, 0x00, 0x00,
 }
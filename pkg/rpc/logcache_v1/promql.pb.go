@@ -34,7 +34,7 @@ func (m *PromQL) Reset()         { *m = PromQL{} }
 func (m *PromQL) String() string { return proto.CompactTextString(m) }
 func (*PromQL) ProtoMessage()    {}
 func (*PromQL) Descriptor() ([]byte, []int) {
-	return fileDescriptor_promql_8af6a03dca3ddd7b, []int{0}
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0}
 }
 func (m *PromQL) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PromQL.Unmarshal(m, b)
@@ -66,7 +66,7 @@ func (m *PromQL_InstantQueryRequest) Reset()         { *m = PromQL_InstantQueryR
 func (m *PromQL_InstantQueryRequest) String() string { return proto.CompactTextString(m) }
 func (*PromQL_InstantQueryRequest) ProtoMessage()    {}
 func (*PromQL_InstantQueryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_promql_8af6a03dca3ddd7b, []int{0, 0}
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 0}
 }
 func (m *PromQL_InstantQueryRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PromQL_InstantQueryRequest.Unmarshal(m, b)
@@ -101,10 +101,12 @@ func (m *PromQL_InstantQueryRequest) GetTime() string {
 }
 
 type PromQL_RangeQueryRequest struct {
-	Query                string   `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
-	Start                string   `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
-	End                  string   `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
-	Step                 string   `protobuf:"bytes,4,opt,name=step,proto3" json:"step,omitempty"`
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Start string `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End   string `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	Step  string `protobuf:"bytes,4,opt,name=step,proto3" json:"step,omitempty"`
+	// nocache bypasses the results cache and evaluates every step.
+	Nocache              bool     `protobuf:"varint,5,opt,name=nocache,proto3" json:"nocache,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -114,7 +116,7 @@ func (m *PromQL_RangeQueryRequest) Reset()         { *m = PromQL_RangeQueryReque
 func (m *PromQL_RangeQueryRequest) String() string { return proto.CompactTextString(m) }
 func (*PromQL_RangeQueryRequest) ProtoMessage()    {}
 func (*PromQL_RangeQueryRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_promql_8af6a03dca3ddd7b, []int{0, 1}
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 1}
 }
 func (m *PromQL_RangeQueryRequest) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PromQL_RangeQueryRequest.Unmarshal(m, b)
@@ -162,6 +164,337 @@ func (m *PromQL_RangeQueryRequest) GetStep() string {
 	return ""
 }
 
+func (m *PromQL_RangeQueryRequest) GetNocache() bool {
+	if m != nil {
+		return m.Nocache
+	}
+	return false
+}
+
+// The match fields hold series selectors. Each selector has to have a
+// source_id matcher.
+type PromQL_LabelsRequest struct {
+	Match                []string `protobuf:"bytes,1,rep,name=match,proto3" json:"match,omitempty"`
+	Start                string   `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End                  string   `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PromQL_LabelsRequest) Reset()         { *m = PromQL_LabelsRequest{} }
+func (m *PromQL_LabelsRequest) String() string { return proto.CompactTextString(m) }
+func (*PromQL_LabelsRequest) ProtoMessage()    {}
+func (*PromQL_LabelsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 2}
+}
+func (m *PromQL_LabelsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PromQL_LabelsRequest.Unmarshal(m, b)
+}
+func (m *PromQL_LabelsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PromQL_LabelsRequest.Marshal(b, m, deterministic)
+}
+func (dst *PromQL_LabelsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PromQL_LabelsRequest.Merge(dst, src)
+}
+func (m *PromQL_LabelsRequest) XXX_Size() int {
+	return xxx_messageInfo_PromQL_LabelsRequest.Size(m)
+}
+func (m *PromQL_LabelsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PromQL_LabelsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PromQL_LabelsRequest proto.InternalMessageInfo
+
+func (m *PromQL_LabelsRequest) GetMatch() []string {
+	if m != nil {
+		return m.Match
+	}
+	return nil
+}
+
+func (m *PromQL_LabelsRequest) GetStart() string {
+	if m != nil {
+		return m.Start
+	}
+	return ""
+}
+
+func (m *PromQL_LabelsRequest) GetEnd() string {
+	if m != nil {
+		return m.End
+	}
+	return ""
+}
+
+type PromQL_LabelValuesRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Match                []string `protobuf:"bytes,2,rep,name=match,proto3" json:"match,omitempty"`
+	Start                string   `protobuf:"bytes,3,opt,name=start,proto3" json:"start,omitempty"`
+	End                  string   `protobuf:"bytes,4,opt,name=end,proto3" json:"end,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PromQL_LabelValuesRequest) Reset()         { *m = PromQL_LabelValuesRequest{} }
+func (m *PromQL_LabelValuesRequest) String() string { return proto.CompactTextString(m) }
+func (*PromQL_LabelValuesRequest) ProtoMessage()    {}
+func (*PromQL_LabelValuesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 3}
+}
+func (m *PromQL_LabelValuesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PromQL_LabelValuesRequest.Unmarshal(m, b)
+}
+func (m *PromQL_LabelValuesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PromQL_LabelValuesRequest.Marshal(b, m, deterministic)
+}
+func (dst *PromQL_LabelValuesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PromQL_LabelValuesRequest.Merge(dst, src)
+}
+func (m *PromQL_LabelValuesRequest) XXX_Size() int {
+	return xxx_messageInfo_PromQL_LabelValuesRequest.Size(m)
+}
+func (m *PromQL_LabelValuesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PromQL_LabelValuesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PromQL_LabelValuesRequest proto.InternalMessageInfo
+
+func (m *PromQL_LabelValuesRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *PromQL_LabelValuesRequest) GetMatch() []string {
+	if m != nil {
+		return m.Match
+	}
+	return nil
+}
+
+func (m *PromQL_LabelValuesRequest) GetStart() string {
+	if m != nil {
+		return m.Start
+	}
+	return ""
+}
+
+func (m *PromQL_LabelValuesRequest) GetEnd() string {
+	if m != nil {
+		return m.End
+	}
+	return ""
+}
+
+type PromQL_SeriesRequest struct {
+	Match                []string `protobuf:"bytes,1,rep,name=match,proto3" json:"match,omitempty"`
+	Start                string   `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End                  string   `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PromQL_SeriesRequest) Reset()         { *m = PromQL_SeriesRequest{} }
+func (m *PromQL_SeriesRequest) String() string { return proto.CompactTextString(m) }
+func (*PromQL_SeriesRequest) ProtoMessage()    {}
+func (*PromQL_SeriesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 4}
+}
+func (m *PromQL_SeriesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PromQL_SeriesRequest.Unmarshal(m, b)
+}
+func (m *PromQL_SeriesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PromQL_SeriesRequest.Marshal(b, m, deterministic)
+}
+func (dst *PromQL_SeriesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PromQL_SeriesRequest.Merge(dst, src)
+}
+func (m *PromQL_SeriesRequest) XXX_Size() int {
+	return xxx_messageInfo_PromQL_SeriesRequest.Size(m)
+}
+func (m *PromQL_SeriesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PromQL_SeriesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PromQL_SeriesRequest proto.InternalMessageInfo
+
+func (m *PromQL_SeriesRequest) GetMatch() []string {
+	if m != nil {
+		return m.Match
+	}
+	return nil
+}
+
+func (m *PromQL_SeriesRequest) GetStart() string {
+	if m != nil {
+		return m.Start
+	}
+	return ""
+}
+
+func (m *PromQL_SeriesRequest) GetEnd() string {
+	if m != nil {
+		return m.End
+	}
+	return ""
+}
+
+type PromQL_LabelsResult struct {
+	Labels               []string `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PromQL_LabelsResult) Reset()         { *m = PromQL_LabelsResult{} }
+func (m *PromQL_LabelsResult) String() string { return proto.CompactTextString(m) }
+func (*PromQL_LabelsResult) ProtoMessage()    {}
+func (*PromQL_LabelsResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 5}
+}
+func (m *PromQL_LabelsResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PromQL_LabelsResult.Unmarshal(m, b)
+}
+func (m *PromQL_LabelsResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PromQL_LabelsResult.Marshal(b, m, deterministic)
+}
+func (dst *PromQL_LabelsResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PromQL_LabelsResult.Merge(dst, src)
+}
+func (m *PromQL_LabelsResult) XXX_Size() int {
+	return xxx_messageInfo_PromQL_LabelsResult.Size(m)
+}
+func (m *PromQL_LabelsResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_PromQL_LabelsResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PromQL_LabelsResult proto.InternalMessageInfo
+
+func (m *PromQL_LabelsResult) GetLabels() []string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+type PromQL_LabelValuesResult struct {
+	Values               []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PromQL_LabelValuesResult) Reset()         { *m = PromQL_LabelValuesResult{} }
+func (m *PromQL_LabelValuesResult) String() string { return proto.CompactTextString(m) }
+func (*PromQL_LabelValuesResult) ProtoMessage()    {}
+func (*PromQL_LabelValuesResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 6}
+}
+func (m *PromQL_LabelValuesResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PromQL_LabelValuesResult.Unmarshal(m, b)
+}
+func (m *PromQL_LabelValuesResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PromQL_LabelValuesResult.Marshal(b, m, deterministic)
+}
+func (dst *PromQL_LabelValuesResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PromQL_LabelValuesResult.Merge(dst, src)
+}
+func (m *PromQL_LabelValuesResult) XXX_Size() int {
+	return xxx_messageInfo_PromQL_LabelValuesResult.Size(m)
+}
+func (m *PromQL_LabelValuesResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_PromQL_LabelValuesResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PromQL_LabelValuesResult proto.InternalMessageInfo
+
+func (m *PromQL_LabelValuesResult) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type PromQL_SeriesResult struct {
+	Series               []*PromQL_LabelSet `protobuf:"bytes,1,rep,name=series,proto3" json:"series,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *PromQL_SeriesResult) Reset()         { *m = PromQL_SeriesResult{} }
+func (m *PromQL_SeriesResult) String() string { return proto.CompactTextString(m) }
+func (*PromQL_SeriesResult) ProtoMessage()    {}
+func (*PromQL_SeriesResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 7}
+}
+func (m *PromQL_SeriesResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PromQL_SeriesResult.Unmarshal(m, b)
+}
+func (m *PromQL_SeriesResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PromQL_SeriesResult.Marshal(b, m, deterministic)
+}
+func (dst *PromQL_SeriesResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PromQL_SeriesResult.Merge(dst, src)
+}
+func (m *PromQL_SeriesResult) XXX_Size() int {
+	return xxx_messageInfo_PromQL_SeriesResult.Size(m)
+}
+func (m *PromQL_SeriesResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_PromQL_SeriesResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PromQL_SeriesResult proto.InternalMessageInfo
+
+func (m *PromQL_SeriesResult) GetSeries() []*PromQL_LabelSet {
+	if m != nil {
+		return m.Series
+	}
+	return nil
+}
+
+type PromQL_LabelSet struct {
+	Labels               map[string]string `protobuf:"bytes,1,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *PromQL_LabelSet) Reset()         { *m = PromQL_LabelSet{} }
+func (m *PromQL_LabelSet) String() string { return proto.CompactTextString(m) }
+func (*PromQL_LabelSet) ProtoMessage()    {}
+func (*PromQL_LabelSet) Descriptor() ([]byte, []int) {
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 8}
+}
+func (m *PromQL_LabelSet) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PromQL_LabelSet.Unmarshal(m, b)
+}
+func (m *PromQL_LabelSet) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PromQL_LabelSet.Marshal(b, m, deterministic)
+}
+func (dst *PromQL_LabelSet) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PromQL_LabelSet.Merge(dst, src)
+}
+func (m *PromQL_LabelSet) XXX_Size() int {
+	return xxx_messageInfo_PromQL_LabelSet.Size(m)
+}
+func (m *PromQL_LabelSet) XXX_DiscardUnknown() {
+	xxx_messageInfo_PromQL_LabelSet.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PromQL_LabelSet proto.InternalMessageInfo
+
+func (m *PromQL_LabelSet) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
 type PromQL_InstantQueryResult struct {
 	// Types that are valid to be assigned to Result:
 	//	*PromQL_InstantQueryResult_Scalar
@@ -177,7 +510,7 @@ func (m *PromQL_InstantQueryResult) Reset()         { *m = PromQL_InstantQueryRe
 func (m *PromQL_InstantQueryResult) String() string { return proto.CompactTextString(m) }
 func (*PromQL_InstantQueryResult) ProtoMessage()    {}
 func (*PromQL_InstantQueryResult) Descriptor() ([]byte, []int) {
-	return fileDescriptor_promql_8af6a03dca3ddd7b, []int{0, 2}
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 9}
 }
 func (m *PromQL_InstantQueryResult) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PromQL_InstantQueryResult.Unmarshal(m, b)
@@ -353,7 +686,7 @@ func (m *PromQL_RangeQueryResult) Reset()         { *m = PromQL_RangeQueryResult
 func (m *PromQL_RangeQueryResult) String() string { return proto.CompactTextString(m) }
 func (*PromQL_RangeQueryResult) ProtoMessage()    {}
 func (*PromQL_RangeQueryResult) Descriptor() ([]byte, []int) {
-	return fileDescriptor_promql_8af6a03dca3ddd7b, []int{0, 3}
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 10}
 }
 func (m *PromQL_RangeQueryResult) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PromQL_RangeQueryResult.Unmarshal(m, b)
@@ -464,7 +797,7 @@ func (m *PromQL_Scalar) Reset()         { *m = PromQL_Scalar{} }
 func (m *PromQL_Scalar) String() string { return proto.CompactTextString(m) }
 func (*PromQL_Scalar) ProtoMessage()    {}
 func (*PromQL_Scalar) Descriptor() ([]byte, []int) {
-	return fileDescriptor_promql_8af6a03dca3ddd7b, []int{0, 4}
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 11}
 }
 func (m *PromQL_Scalar) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PromQL_Scalar.Unmarshal(m, b)
@@ -509,7 +842,7 @@ func (m *PromQL_Vector) Reset()         { *m = PromQL_Vector{} }
 func (m *PromQL_Vector) String() string { return proto.CompactTextString(m) }
 func (*PromQL_Vector) ProtoMessage()    {}
 func (*PromQL_Vector) Descriptor() ([]byte, []int) {
-	return fileDescriptor_promql_8af6a03dca3ddd7b, []int{0, 5}
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 12}
 }
 func (m *PromQL_Vector) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PromQL_Vector.Unmarshal(m, b)
@@ -548,7 +881,7 @@ func (m *PromQL_Point) Reset()         { *m = PromQL_Point{} }
 func (m *PromQL_Point) String() string { return proto.CompactTextString(m) }
 func (*PromQL_Point) ProtoMessage()    {}
 func (*PromQL_Point) Descriptor() ([]byte, []int) {
-	return fileDescriptor_promql_8af6a03dca3ddd7b, []int{0, 6}
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 13}
 }
 func (m *PromQL_Point) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PromQL_Point.Unmarshal(m, b)
@@ -594,7 +927,7 @@ func (m *PromQL_Sample) Reset()         { *m = PromQL_Sample{} }
 func (m *PromQL_Sample) String() string { return proto.CompactTextString(m) }
 func (*PromQL_Sample) ProtoMessage()    {}
 func (*PromQL_Sample) Descriptor() ([]byte, []int) {
-	return fileDescriptor_promql_8af6a03dca3ddd7b, []int{0, 7}
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 14}
 }
 func (m *PromQL_Sample) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PromQL_Sample.Unmarshal(m, b)
@@ -639,7 +972,7 @@ func (m *PromQL_Matrix) Reset()         { *m = PromQL_Matrix{} }
 func (m *PromQL_Matrix) String() string { return proto.CompactTextString(m) }
 func (*PromQL_Matrix) ProtoMessage()    {}
 func (*PromQL_Matrix) Descriptor() ([]byte, []int) {
-	return fileDescriptor_promql_8af6a03dca3ddd7b, []int{0, 8}
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 15}
 }
 func (m *PromQL_Matrix) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PromQL_Matrix.Unmarshal(m, b)
@@ -678,7 +1011,7 @@ func (m *PromQL_Series) Reset()         { *m = PromQL_Series{} }
 func (m *PromQL_Series) String() string { return proto.CompactTextString(m) }
 func (*PromQL_Series) ProtoMessage()    {}
 func (*PromQL_Series) Descriptor() ([]byte, []int) {
-	return fileDescriptor_promql_8af6a03dca3ddd7b, []int{0, 9}
+	return fileDescriptor_promql_7c65ba6456d64b4a, []int{0, 16}
 }
 func (m *PromQL_Series) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PromQL_Series.Unmarshal(m, b)
@@ -716,6 +1049,14 @@ func init() {
 	proto.RegisterType((*PromQL)(nil), "logcache.v1.PromQL")
 	proto.RegisterType((*PromQL_InstantQueryRequest)(nil), "logcache.v1.PromQL.InstantQueryRequest")
 	proto.RegisterType((*PromQL_RangeQueryRequest)(nil), "logcache.v1.PromQL.RangeQueryRequest")
+	proto.RegisterType((*PromQL_LabelsRequest)(nil), "logcache.v1.PromQL.LabelsRequest")
+	proto.RegisterType((*PromQL_LabelValuesRequest)(nil), "logcache.v1.PromQL.LabelValuesRequest")
+	proto.RegisterType((*PromQL_SeriesRequest)(nil), "logcache.v1.PromQL.SeriesRequest")
+	proto.RegisterType((*PromQL_LabelsResult)(nil), "logcache.v1.PromQL.LabelsResult")
+	proto.RegisterType((*PromQL_LabelValuesResult)(nil), "logcache.v1.PromQL.LabelValuesResult")
+	proto.RegisterType((*PromQL_SeriesResult)(nil), "logcache.v1.PromQL.SeriesResult")
+	proto.RegisterType((*PromQL_LabelSet)(nil), "logcache.v1.PromQL.LabelSet")
+	proto.RegisterMapType((map[string]string)(nil), "logcache.v1.PromQL.LabelSet.LabelsEntry")
 	proto.RegisterType((*PromQL_InstantQueryResult)(nil), "logcache.v1.PromQL.InstantQueryResult")
 	proto.RegisterType((*PromQL_RangeQueryResult)(nil), "logcache.v1.PromQL.RangeQueryResult")
 	proto.RegisterType((*PromQL_Scalar)(nil), "logcache.v1.PromQL.Scalar")
@@ -742,6 +1083,15 @@ const _ = grpc.SupportPackageIsVersion4
 type PromQLQuerierClient interface {
 	InstantQuery(ctx context.Context, in *PromQL_InstantQueryRequest, opts ...grpc.CallOption) (*PromQL_InstantQueryResult, error)
 	RangeQuery(ctx context.Context, in *PromQL_RangeQueryRequest, opts ...grpc.CallOption) (*PromQL_RangeQueryResult, error)
+	// Labels returns the label names of the series that match any of the
+	// selectors.
+	Labels(ctx context.Context, in *PromQL_LabelsRequest, opts ...grpc.CallOption) (*PromQL_LabelsResult, error)
+	// LabelValues returns the values of a label of the series that match
+	// any of the selectors.
+	LabelValues(ctx context.Context, in *PromQL_LabelValuesRequest, opts ...grpc.CallOption) (*PromQL_LabelValuesResult, error)
+	// Series returns the label sets of the series that match any of the
+	// selectors.
+	Series(ctx context.Context, in *PromQL_SeriesRequest, opts ...grpc.CallOption) (*PromQL_SeriesResult, error)
 }
 
 type promQLQuerierClient struct {
@@ -770,10 +1120,46 @@ func (c *promQLQuerierClient) RangeQuery(ctx context.Context, in *PromQL_RangeQu
 	return out, nil
 }
 
+func (c *promQLQuerierClient) Labels(ctx context.Context, in *PromQL_LabelsRequest, opts ...grpc.CallOption) (*PromQL_LabelsResult, error) {
+	out := new(PromQL_LabelsResult)
+	err := c.cc.Invoke(ctx, "/logcache.v1.PromQLQuerier/Labels", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *promQLQuerierClient) LabelValues(ctx context.Context, in *PromQL_LabelValuesRequest, opts ...grpc.CallOption) (*PromQL_LabelValuesResult, error) {
+	out := new(PromQL_LabelValuesResult)
+	err := c.cc.Invoke(ctx, "/logcache.v1.PromQLQuerier/LabelValues", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *promQLQuerierClient) Series(ctx context.Context, in *PromQL_SeriesRequest, opts ...grpc.CallOption) (*PromQL_SeriesResult, error) {
+	out := new(PromQL_SeriesResult)
+	err := c.cc.Invoke(ctx, "/logcache.v1.PromQLQuerier/Series", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PromQLQuerierServer is the server API for PromQLQuerier service.
 type PromQLQuerierServer interface {
 	InstantQuery(context.Context, *PromQL_InstantQueryRequest) (*PromQL_InstantQueryResult, error)
 	RangeQuery(context.Context, *PromQL_RangeQueryRequest) (*PromQL_RangeQueryResult, error)
+	// Labels returns the label names of the series that match any of the
+	// selectors.
+	Labels(context.Context, *PromQL_LabelsRequest) (*PromQL_LabelsResult, error)
+	// LabelValues returns the values of a label of the series that match
+	// any of the selectors.
+	LabelValues(context.Context, *PromQL_LabelValuesRequest) (*PromQL_LabelValuesResult, error)
+	// Series returns the label sets of the series that match any of the
+	// selectors.
+	Series(context.Context, *PromQL_SeriesRequest) (*PromQL_SeriesResult, error)
 }
 
 func RegisterPromQLQuerierServer(s *grpc.Server, srv PromQLQuerierServer) {
@@ -816,6 +1202,60 @@ func _PromQLQuerier_RangeQuery_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PromQLQuerier_Labels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PromQL_LabelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PromQLQuerierServer).Labels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/logcache.v1.PromQLQuerier/Labels",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PromQLQuerierServer).Labels(ctx, req.(*PromQL_LabelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PromQLQuerier_LabelValues_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PromQL_LabelValuesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PromQLQuerierServer).LabelValues(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/logcache.v1.PromQLQuerier/LabelValues",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PromQLQuerierServer).LabelValues(ctx, req.(*PromQL_LabelValuesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PromQLQuerier_Series_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PromQL_SeriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PromQLQuerierServer).Series(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/logcache.v1.PromQLQuerier/Series",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PromQLQuerierServer).Series(ctx, req.(*PromQL_SeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _PromQLQuerier_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "logcache.v1.PromQLQuerier",
 	HandlerType: (*PromQLQuerierServer)(nil),
@@ -828,47 +1268,73 @@ var _PromQLQuerier_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RangeQuery",
 			Handler:    _PromQLQuerier_RangeQuery_Handler,
 		},
+		{
+			MethodName: "Labels",
+			Handler:    _PromQLQuerier_Labels_Handler,
+		},
+		{
+			MethodName: "LabelValues",
+			Handler:    _PromQLQuerier_LabelValues_Handler,
+		},
+		{
+			MethodName: "Series",
+			Handler:    _PromQLQuerier_Series_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "promql.proto",
 }
 
-func init() { proto.RegisterFile("promql.proto", fileDescriptor_promql_8af6a03dca3ddd7b) }
-
-var fileDescriptor_promql_8af6a03dca3ddd7b = []byte{
-	// 533 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x94, 0xc1, 0x6e, 0x13, 0x31,
-	0x10, 0x86, 0x71, 0xda, 0x98, 0x76, 0xd2, 0x8a, 0xe2, 0xb6, 0xd2, 0x62, 0x38, 0x54, 0x15, 0x14,
-	0x4e, 0x1b, 0x25, 0x70, 0x00, 0x84, 0x8a, 0x84, 0x84, 0x04, 0x12, 0x95, 0x5a, 0x23, 0xf5, 0x8a,
-	0xcc, 0x62, 0x85, 0x15, 0xbb, 0xeb, 0x8d, 0xed, 0xac, 0xe8, 0x95, 0x57, 0xe0, 0xca, 0x63, 0x20,
-	0xce, 0xbc, 0x03, 0xaf, 0xc0, 0x7b, 0x80, 0x3c, 0x76, 0x68, 0x22, 0x36, 0xa4, 0xc0, 0x6d, 0xbc,
-	0xfa, 0xfe, 0xf9, 0x67, 0xc6, 0xde, 0x81, 0x8d, 0xda, 0xe8, 0x72, 0x5c, 0xa4, 0xb5, 0xd1, 0x4e,
-	0xb3, 0x5e, 0xa1, 0x47, 0x99, 0xcc, 0xde, 0xaa, 0xb4, 0x19, 0xf0, 0x1b, 0x23, 0xad, 0x47, 0x85,
-	0xea, 0xcb, 0x3a, 0xef, 0xcb, 0xaa, 0xd2, 0x4e, 0xba, 0x5c, 0x57, 0x36, 0xa0, 0xfb, 0x9f, 0xd6,
-	0x80, 0x1e, 0x1b, 0x5d, 0x9e, 0xbc, 0xe0, 0x8f, 0x61, 0xfb, 0x79, 0x65, 0x9d, 0xac, 0xdc, 0xc9,
-	0x44, 0x99, 0x33, 0xa1, 0xc6, 0x13, 0x65, 0x1d, 0xdb, 0x81, 0xee, 0xd8, 0x9f, 0x13, 0xb2, 0x47,
-	0xee, 0xac, 0x8b, 0x70, 0x60, 0x0c, 0x56, 0x5d, 0x5e, 0xaa, 0xa4, 0x83, 0x1f, 0x31, 0xe6, 0x0a,
-	0xae, 0x0a, 0x59, 0x8d, 0xd4, 0x05, 0xe4, 0x3b, 0xd0, 0xb5, 0x4e, 0x1a, 0x17, 0xf5, 0xe1, 0xc0,
-	0xb6, 0x60, 0x45, 0x55, 0x6f, 0x92, 0x15, 0xfc, 0xe6, 0x43, 0x6f, 0x63, 0x9d, 0xaa, 0x93, 0xd5,
-	0x60, 0xe3, 0x63, 0xfe, 0x95, 0x00, 0x9b, 0x2f, 0xd4, 0x4e, 0x0a, 0xc7, 0xee, 0x01, 0xb5, 0x99,
-	0x2c, 0xa4, 0x41, 0xa7, 0xde, 0x90, 0xa7, 0x33, 0x53, 0x48, 0x43, 0x8f, 0xe9, 0x4b, 0x24, 0x9e,
-	0x5d, 0x12, 0x91, 0xf5, 0xaa, 0x46, 0x65, 0x4e, 0x1b, 0xac, 0x64, 0x81, 0xea, 0x14, 0x09, 0xaf,
-	0x0a, 0xac, 0x57, 0x95, 0xd2, 0x99, 0xfc, 0x3d, 0xd6, 0xba, 0x40, 0x75, 0x84, 0x84, 0x57, 0x05,
-	0xf6, 0xc9, 0x1a, 0xd0, 0x50, 0x2b, 0x17, 0xb0, 0x35, 0x3b, 0xa9, 0x69, 0xfd, 0x31, 0x27, 0xf9,
-	0xa7, 0x9c, 0x43, 0xa0, 0xa1, 0xbb, 0x5f, 0x77, 0x43, 0xce, 0xef, 0xc6, 0x0f, 0xbc, 0x91, 0xc5,
-	0x24, 0x5c, 0x18, 0x11, 0xe1, 0xc0, 0x0f, 0x81, 0x9e, 0x4e, 0x3b, 0xba, 0x6c, 0x65, 0x59, 0x17,
-	0xca, 0x26, 0x64, 0x6f, 0x65, 0xe1, 0xf8, 0x10, 0x11, 0x53, 0x94, 0x0f, 0xa0, 0x7b, 0xac, 0xf3,
-	0xca, 0xfd, 0x85, 0xe5, 0x67, 0x02, 0x34, 0xa4, 0x61, 0x87, 0x40, 0x4b, 0xe5, 0x4c, 0x9e, 0x45,
-	0xcb, 0x83, 0xc5, 0x96, 0xe9, 0x11, 0x82, 0x4f, 0x2b, 0x67, 0xce, 0x44, 0x54, 0xb1, 0x3e, 0x74,
-	0x6b, 0xef, 0x1e, 0xaf, 0xee, 0x5a, 0x9b, 0x1c, 0xcb, 0x13, 0x81, 0xe3, 0x0f, 0xa0, 0x37, 0x93,
-	0xc7, 0x3f, 0xb7, 0x77, 0x6a, 0xfa, 0x30, 0x7d, 0x38, 0x5f, 0xf2, 0x7a, 0x2c, 0xf9, 0x61, 0xe7,
-	0x3e, 0xe1, 0x8f, 0x80, 0x86, 0xd9, 0xb3, 0x21, 0x50, 0xab, 0x4c, 0xbe, 0x64, 0x50, 0x48, 0x88,
-	0x48, 0xf2, 0x2f, 0xbe, 0x69, 0x0c, 0x2f, 0xd8, 0x34, 0xb2, 0xad, 0x4d, 0x0f, 0x80, 0x62, 0x33,
-	0x36, 0xe9, 0xa0, 0xfe, 0x0f, 0x5d, 0x47, 0xf0, 0x3f, 0xda, 0x1e, 0xfe, 0x20, 0xb0, 0x19, 0x72,
-	0xfa, 0xa7, 0x9a, 0x2b, 0xc3, 0x1a, 0xd8, 0x98, 0xfd, 0xf9, 0xd8, 0xed, 0x36, 0xff, 0x96, 0x3d,
-	0xc2, 0x0f, 0x96, 0x83, 0xfe, 0x1d, 0xef, 0xef, 0x7e, 0xf8, 0xf6, 0xfd, 0x63, 0xe7, 0x0a, 0xdb,
-	0xc4, 0x8d, 0xd5, 0x0c, 0xfa, 0x61, 0x63, 0x34, 0x00, 0xe7, 0xbf, 0x0c, 0xbb, 0xd5, 0x96, 0xec,
-	0xb7, 0xe5, 0xc3, 0x6f, 0x2e, 0xc3, 0xd0, 0xf1, 0x3a, 0x3a, 0xee, 0xb2, 0xed, 0x39, 0xc7, 0x57,
-	0xc6, 0x73, 0xaf, 0x29, 0xee, 0xc9, 0xbb, 0x3f, 0x03, 0x00, 0x00, 0xff, 0xff, 0xd8, 0xdc, 0x69,
-	0xcb, 0x62, 0x05, 0x00, 0x00,
+func init() { proto.RegisterFile("promql.proto", fileDescriptor_promql_7c65ba6456d64b4a) }
+
+var fileDescriptor_promql_7c65ba6456d64b4a = []byte{
+	// 759 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0x6d, 0x6b, 0x13, 0x5b,
+	0x10, 0xbe, 0x9b, 0x97, 0x6d, 0xef, 0x24, 0xb9, 0xb7, 0x3d, 0x7d, 0x61, 0xef, 0x69, 0x2f, 0xa4,
+	0xd5, 0xc6, 0x82, 0x90, 0x90, 0xe8, 0x07, 0x15, 0xa9, 0x22, 0x0a, 0x0a, 0x0d, 0xb4, 0x5b, 0xe8,
+	0x57, 0x39, 0x8d, 0xc7, 0x74, 0x71, 0x5f, 0xd2, 0xdd, 0x93, 0x60, 0x11, 0x11, 0xfd, 0xe2, 0x0f,
+	0xf0, 0xaf, 0x88, 0x9f, 0xfd, 0x0b, 0xe2, 0x2f, 0x10, 0xfc, 0x21, 0x72, 0xe6, 0x9c, 0x93, 0xec,
+	0xda, 0x4d, 0xda, 0xaa, 0xdf, 0x66, 0x96, 0x67, 0xe6, 0x99, 0x67, 0x32, 0x73, 0x26, 0x50, 0x1d,
+	0xc4, 0x51, 0x70, 0xe2, 0x37, 0x07, 0x71, 0x24, 0x22, 0x52, 0xf1, 0xa3, 0x7e, 0x8f, 0xf5, 0x8e,
+	0x79, 0x73, 0xd4, 0xa6, 0xeb, 0xfd, 0x28, 0xea, 0xfb, 0xbc, 0xc5, 0x06, 0x5e, 0x8b, 0x85, 0x61,
+	0x24, 0x98, 0xf0, 0xa2, 0x30, 0x51, 0xd0, 0xcd, 0x6f, 0x55, 0xb0, 0xf7, 0xe2, 0x28, 0xd8, 0xdf,
+	0xa5, 0xf7, 0x60, 0xe9, 0x49, 0x98, 0x08, 0x16, 0x8a, 0xfd, 0x21, 0x8f, 0x4f, 0x5d, 0x7e, 0x32,
+	0xe4, 0x89, 0x20, 0xcb, 0x50, 0x3e, 0x91, 0xbe, 0x63, 0xd5, 0xad, 0xed, 0xbf, 0x5d, 0xe5, 0x10,
+	0x02, 0x25, 0xe1, 0x05, 0xdc, 0x29, 0xe0, 0x47, 0xb4, 0xe9, 0x1b, 0x58, 0x74, 0x59, 0xd8, 0xe7,
+	0x17, 0x08, 0x5f, 0x86, 0x72, 0x22, 0x58, 0x2c, 0x74, 0xbc, 0x72, 0xc8, 0x02, 0x14, 0x79, 0xf8,
+	0xcc, 0x29, 0xe2, 0x37, 0x69, 0x4a, 0x9a, 0x44, 0xf0, 0x81, 0x53, 0x52, 0x34, 0xd2, 0x26, 0x0e,
+	0xcc, 0x85, 0x11, 0xca, 0x73, 0xca, 0x75, 0x6b, 0x7b, 0xde, 0x35, 0x2e, 0xed, 0x42, 0x6d, 0x97,
+	0x1d, 0x71, 0x3f, 0x49, 0x91, 0x07, 0x4c, 0xf4, 0x8e, 0x1d, 0xab, 0x5e, 0x94, 0x34, 0xe8, 0x5c,
+	0x94, 0x9c, 0x3e, 0x07, 0x82, 0xe9, 0x0e, 0x99, 0x3f, 0xe4, 0xe3, 0x9c, 0x04, 0x4a, 0x21, 0x0b,
+	0xb8, 0xd6, 0x83, 0xf6, 0x84, 0xa7, 0x90, 0xcb, 0x53, 0xcc, 0xe1, 0x29, 0x4d, 0x78, 0xba, 0x50,
+	0x3b, 0xe0, 0xb1, 0xc7, 0xff, 0x50, 0xd9, 0x0d, 0xa8, 0x9a, 0x2e, 0x24, 0x43, 0x5f, 0x90, 0x55,
+	0xb0, 0x7d, 0xf4, 0x75, 0x3a, 0xed, 0xd1, 0xeb, 0xb0, 0x98, 0x91, 0x67, 0xc0, 0x23, 0xf4, 0x0d,
+	0x58, 0x79, 0xf4, 0x21, 0x54, 0x4d, 0x8d, 0x88, 0xbb, 0x09, 0x76, 0x82, 0x3e, 0xe2, 0x2a, 0x9d,
+	0xf5, 0x66, 0x6a, 0xe6, 0x9a, 0x6a, 0xa2, 0x9a, 0x98, 0xfe, 0x80, 0x0b, 0x57, 0x63, 0xe9, 0x7b,
+	0x0b, 0xe6, 0xcd, 0x47, 0x72, 0x3f, 0x53, 0x57, 0xa5, 0xb3, 0x3d, 0x2b, 0x85, 0x32, 0x92, 0x47,
+	0xa1, 0x88, 0x4f, 0xc7, 0x0a, 0x6e, 0x43, 0x25, 0xf5, 0x59, 0xb6, 0xe2, 0x05, 0x37, 0x83, 0x26,
+	0x4d, 0xd9, 0x32, 0xac, 0xdf, 0xb4, 0x0c, 0x9d, 0x3b, 0x85, 0x5b, 0x16, 0xfd, 0x6c, 0x01, 0xc9,
+	0x4e, 0xfb, 0x58, 0x56, 0x8f, 0xf9, 0x2c, 0xc6, 0x2c, 0x95, 0x0e, 0xcd, 0xab, 0xe9, 0x00, 0x11,
+	0x8f, 0xff, 0x72, 0x35, 0x56, 0x46, 0x8d, 0x78, 0x4f, 0x44, 0xb1, 0x53, 0x98, 0x1e, 0x75, 0x88,
+	0x08, 0x19, 0xa5, 0xb0, 0x32, 0x2a, 0x60, 0x22, 0xf6, 0x5e, 0x3a, 0xc5, 0xe9, 0x51, 0x5d, 0x44,
+	0xc8, 0x28, 0x85, 0x7d, 0x30, 0x0f, 0xb6, 0xaa, 0x95, 0xba, 0xb0, 0x90, 0x5e, 0x37, 0x53, 0xbf,
+	0xce, 0x69, 0xfd, 0x52, 0xce, 0x0e, 0xd8, 0x4a, 0xdd, 0x78, 0xc1, 0xad, 0xc9, 0x82, 0x67, 0xdb,
+	0x69, 0xe9, 0x76, 0xd2, 0x1d, 0xb0, 0x0f, 0x8d, 0xa2, 0xb9, 0x84, 0x05, 0x03, 0x7f, 0x3c, 0x15,
+	0xf9, 0xed, 0x43, 0x88, 0x6b, 0xa0, 0xb4, 0x0d, 0xe5, 0xbd, 0xc8, 0x0b, 0xc5, 0x25, 0x28, 0x3f,
+	0x5a, 0x60, 0xab, 0x34, 0x64, 0x07, 0xec, 0x80, 0x8b, 0xd8, 0xeb, 0x69, 0xca, 0xc6, 0x74, 0xca,
+	0x66, 0x17, 0x81, 0x7a, 0x86, 0x54, 0x14, 0x69, 0x41, 0x79, 0x20, 0xd9, 0xf5, 0x4f, 0xf7, 0x5f,
+	0x5e, 0x38, 0x96, 0xe7, 0x2a, 0x9c, 0x1c, 0xba, 0x54, 0x9e, 0x4b, 0x0d, 0xdd, 0x5d, 0xb0, 0x55,
+	0xef, 0x49, 0xe7, 0xa7, 0xf5, 0xc9, 0x6f, 0x94, 0x5a, 0x38, 0xb3, 0x3c, 0x9f, 0xa4, 0x68, 0x34,
+	0x2f, 0x28, 0x1a, 0xb1, 0xb9, 0xa2, 0xdb, 0x60, 0xa3, 0x98, 0x04, 0x1f, 0xac, 0x99, 0xaa, 0x35,
+	0xf0, 0x37, 0x64, 0x77, 0xbe, 0x94, 0xa0, 0xa6, 0x72, 0xca, 0x51, 0xf5, 0x78, 0x4c, 0x46, 0x50,
+	0x4d, 0x2f, 0x1f, 0xb9, 0x96, 0xc7, 0x9f, 0x73, 0x8c, 0x68, 0xe3, 0x7c, 0xa0, 0x9c, 0xe3, 0xcd,
+	0x95, 0x77, 0x5f, 0xbf, 0x7f, 0x28, 0xfc, 0x4b, 0x6a, 0x78, 0xf6, 0x46, 0xed, 0x96, 0x3a, 0x3b,
+	0x23, 0x80, 0xc9, 0xca, 0x90, 0xad, 0xbc, 0x64, 0x67, 0x2e, 0x18, 0xbd, 0x7a, 0x1e, 0x0c, 0x19,
+	0xd7, 0x90, 0x71, 0x85, 0x2c, 0x65, 0x18, 0x9f, 0xc6, 0x12, 0x47, 0x38, 0xd8, 0xea, 0xa1, 0x22,
+	0x1b, 0x53, 0x1f, 0x39, 0xf3, 0xfa, 0xd3, 0xfa, 0x2c, 0x08, 0x72, 0xad, 0x22, 0xd7, 0x02, 0xf9,
+	0xc7, 0x70, 0xa9, 0xf7, 0x90, 0xbc, 0xb5, 0xa0, 0x92, 0x7a, 0xd2, 0x49, 0x63, 0x6a, 0xa6, 0xcc,
+	0x49, 0xa3, 0x5b, 0xe7, 0xe2, 0x90, 0xf6, 0x0a, 0xd2, 0xfe, 0x4f, 0xd6, 0x32, 0xb4, 0xad, 0x57,
+	0xf2, 0x04, 0xbe, 0x6e, 0xa9, 0x43, 0x21, 0xa5, 0xea, 0x21, 0xdd, 0x98, 0x31, 0xd3, 0xb3, 0xa4,
+	0xa6, 0xef, 0xcc, 0x59, 0xa9, 0x6a, 0x19, 0x8e, 0x6c, 0xfc, 0xfb, 0x72, 0xe3, 0xc7, 0x00, 0x8a,
+	0x7c, 0x57, 0x52, 0xf9, 0x08, 0x00, 0x00,
 }